@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 代理链的实现方式
+const (
+	// relay 代理组，所有内核都支持
+	chainModeRelay = "relay"
+	// 复制节点并用 dialer-proxy 串联，需要 Meta 内核，原节点不受影响
+	chainModeDialer = "dialer"
+)
+
+// 只能通过 UDP 连接服务器的协议，无法经过前一跳的 TCP 连接转发
+var udpTransportProxyTypes = map[string]bool{
+	"hysteria":  true,
+	"hysteria2": true,
+	"tuic":      true,
+	"wireguard": true,
+}
+
+// 处理 chain 子命令
+func manageChains(args []string) {
+	if len(args) == 0 {
+		printChainUsage()
+		os.Exit(1)
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		os.Exit(1)
+	}
+	// 修改前的配置，用于显示差异
+	current, _ := readClashConfig()
+
+	positional, options, err := parseGroupArgs(args[1:])
+	if err != nil {
+		fmt.Printf(T("chain %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		printChains(config)
+		return
+	case "create":
+		if len(positional) < 3 {
+			printChainUsage()
+			os.Exit(1)
+		}
+		mode := chainModeRelay
+		for _, option := range options {
+			if option[0] != "mode" {
+				fmt.Printf(T("未知的参数: --%s\n"), option[0])
+				os.Exit(1)
+			}
+			mode = option[1]
+		}
+		err = createChain(config, positional[0], positional[1:], mode)
+	case "delete":
+		if len(positional) != 1 {
+			printChainUsage()
+			os.Exit(1)
+		}
+		err = deleteChain(config, positional[0])
+	default:
+		fmt.Printf(T("未知的 chain 子命令: %s\n"), args[0])
+		printChainUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf(T("chain %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+
+	// 命令行模式通常用于脚本，只打印修改内容，不要求确认
+	printConfigChanges(diffClashConfigs(current, config))
+	if err := saveClashConfig(config); err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+		os.Exit(1)
+	}
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+}
+
+// 显示 chain 子命令的用法
+func printChainUsage() {
+	fmt.Printf(T("用法: %s chain <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  list                                        列出代理链"))
+	fmt.Println(T("  create <名称> <第一跳> <第二跳>... [--mode relay|dialer]"))
+	fmt.Println(T("                                              创建代理链，流量依次经过各跳，最后一跳为出口"))
+	fmt.Println(T("  delete <名称>                               删除代理链"))
+	fmt.Println(T("\n--mode relay  (默认) 生成 relay 代理组，各跳可以是节点或代理组"))
+	fmt.Println(T("--mode dialer 复制第二跳之后的节点并通过 dialer-proxy 串联，需要 Meta 内核"))
+}
+
+// 检查各跳能否组成代理链
+func validateChainHops(config *ClashConfig, hops []string, mode string) error {
+	if len(hops) < 2 {
+		return errors.New(T("代理链至少需要两跳"))
+	}
+
+	for i, hop := range hops {
+		if builtinPolicies[hop] {
+			return fmt.Errorf(T("第 %d 跳不能是内置策略 %s"), i+1, hop)
+		}
+		if i > 0 && hops[i-1] == hop {
+			return fmt.Errorf(T("第 %d 跳与前一跳相同: %s"), i+1, hop)
+		}
+
+		proxy := config.FindProxy(hop)
+		if proxy == nil {
+			group := config.FindGroup(hop)
+			switch {
+			case group == nil:
+				return fmt.Errorf(T("第 %d 跳 %s 不是已有的节点或代理组"), i+1, hop)
+			case group.Type == "relay":
+				return fmt.Errorf(T("第 %d 跳 %s 本身是 relay 代理组，不能嵌套"), i+1, hop)
+			case mode == chainModeDialer && i > 0:
+				return fmt.Errorf(T("dialer 模式下只有第一跳可以是代理组，%s 需要是节点"), hop)
+			}
+			continue
+		}
+
+		// 之后的各跳需要经过前一跳建立连接
+		if i > 0 && udpTransportProxyTypes[proxy.Type] {
+			previous := config.FindProxy(hops[i-1])
+			if mode == chainModeRelay {
+				return fmt.Errorf(T("%s (%s) 基于 UDP 传输，只能作为第一跳"), hop, proxy.Type)
+			}
+			if previous == nil || previous.GetString("udp") != "true" {
+				return fmt.Errorf(T("%s (%s) 基于 UDP 传输，前一跳 %s 需要是开启了 udp 的节点"), hop, proxy.Type, hops[i-1])
+			}
+		}
+	}
+	return nil
+}
+
+// 创建代理链，并加入主代理组以便选择和测速
+func createChain(config *ClashConfig, name string, hops []string, mode string) error {
+	if name == "" || strings.Contains(name, ",") {
+		return fmt.Errorf(T("无效的代理链名称: %q"), name)
+	}
+	if policyNameExists(config, name) {
+		return errorOf(ErrNameConflict, T("名称 %s 已被节点、代理组或内置策略使用"), name)
+	}
+	if mode != chainModeRelay && mode != chainModeDialer {
+		return fmt.Errorf(T("不支持的模式: %s (可选 relay、dialer)"), mode)
+	}
+	if err := validateChainHops(config, hops, mode); err != nil {
+		return err
+	}
+
+	if mode == chainModeRelay {
+		if err := createProxyGroup(config, ProxyGroup{Name: name, Type: "relay"}, hops); err != nil {
+			return err
+		}
+	} else {
+		// 中间各跳复制为 "<名称>/<节点>"，出口节点的副本使用代理链名称
+		copyNames := make([]string, len(hops)-1)
+		used := map[string]bool{name: true}
+		for i, hop := range hops[1:] {
+			copyNames[i] = name
+			if i < len(hops)-2 {
+				copyNames[i] = name + "/" + hop
+				if used[copyNames[i]] || policyNameExists(config, copyNames[i]) {
+					return errorOf(ErrNameConflict, T("名称 %s 已被使用"), copyNames[i])
+				}
+				used[copyNames[i]] = true
+			}
+		}
+
+		// 全部副本创建成功后再加入配置，避免留下不完整的代理链
+		nodes := make([]Proxy, 0, len(copyNames))
+		previous := hops[0]
+		for i, hop := range hops[1:] {
+			node, err := cloneProxy(config.FindProxy(hop))
+			if err != nil {
+				return err
+			}
+			node.Name = copyNames[i]
+			node.Set("dialer-proxy", previous)
+			nodes = append(nodes, node)
+			previous = copyNames[i]
+		}
+		config.Proxies = append(config.Proxies, nodes...)
+	}
+
+	if group := primarySelectGroup(config, nil); group != nil {
+		group.Proxies = append(group.Proxies, name)
+		fmt.Printf(T("已将代理链 %s 加入代理组 %s\n"), name, group.Name)
+	}
+	return nil
+}
+
+// 深拷贝节点，协议选项中的嵌套字段不与原节点共享
+func cloneProxy(proxy *Proxy) (Proxy, error) {
+	var clone Proxy
+	content, err := yaml.Marshal(proxy)
+	if err != nil {
+		return clone, err
+	}
+	if err := yaml.Unmarshal(content, &clone); err != nil {
+		return clone, err
+	}
+	return clone, nil
+}
+
+// 代理链经过的各跳，不是代理链时返回 nil
+func chainHops(config *ClashConfig, name string) []string {
+	if group := config.FindGroup(name); group != nil {
+		if group.Type == "relay" {
+			return group.Proxies
+		}
+		return nil
+	}
+
+	// 沿 dialer-proxy 从出口向前查找，限制步数以防循环引用
+	var hops []string
+	current := name
+	for i := 0; i <= len(config.Proxies); i++ {
+		hops = append([]string{current}, hops...)
+		proxy := config.FindProxy(current)
+		if proxy == nil || !proxy.Has("dialer-proxy") {
+			break
+		}
+		current = proxy.GetString("dialer-proxy")
+	}
+	if len(hops) < 2 {
+		return nil
+	}
+	return hops
+}
+
+// 是否为代理链：relay 代理组或 dialer-proxy 链的出口节点
+func isProxyChain(config *ClashConfig, name string) bool {
+	if group := config.FindGroup(name); group != nil {
+		return group.Type == "relay"
+	}
+	proxy := config.FindProxy(name)
+	return proxy != nil && proxy.Has("dialer-proxy")
+}
+
+// 配置中的代理链名称，dialer-proxy 链只返回出口节点
+func chainNames(config *ClashConfig) []string {
+	// 被其它节点作为 dialer-proxy 的节点是链中的前一跳
+	dialers := make(map[string]bool)
+	for _, proxy := range config.Proxies {
+		if dialer := proxy.GetString("dialer-proxy"); dialer != "" {
+			dialers[dialer] = true
+		}
+	}
+
+	var names []string
+	for _, group := range config.ProxyGroups {
+		if group.Type == "relay" {
+			names = append(names, group.Name)
+		}
+	}
+	for _, proxy := range config.Proxies {
+		if proxy.Has("dialer-proxy") && !dialers[proxy.Name] {
+			names = append(names, proxy.Name)
+		}
+	}
+	return names
+}
+
+// 打印代理链
+func printChains(config *ClashConfig) {
+	names := chainNames(config)
+	if len(names) == 0 {
+		fmt.Println(T("配置文件中没有代理链"))
+		return
+	}
+
+	fmt.Printf(T("共有 %d 条代理链:\n\n"), len(names))
+	for i, name := range names {
+		mode := chainModeDialer
+		if config.FindGroup(name) != nil {
+			mode = chainModeRelay
+		}
+		fmt.Printf("%d. %s (%s)\n", i+1, name, mode)
+		fmt.Printf("   %s\n", strings.Join(chainHops(config, name), " -> "))
+	}
+}
+
+// 删除代理链，dialer 模式同时删除生成的中间副本
+func deleteChain(config *ClashConfig, name string) error {
+	if !isProxyChain(config, name) {
+		return fmt.Errorf(T("%s 不是代理链"), name)
+	}
+	for _, text := range config.Rules {
+		if target, err := ruleTarget(text); err == nil && target == name {
+			return errorOf(ErrReferencedByRules, T("代理链 %s 仍被规则 %s 使用"), name, text)
+		}
+	}
+
+	if config.FindGroup(name) != nil {
+		return deleteProxyGroup(config, name)
+	}
+
+	current := name
+	for i := 0; i <= len(config.Proxies); i++ {
+		proxy := config.FindProxy(current)
+		if proxy == nil || (current != name && !strings.HasPrefix(current, name+"/")) {
+			break
+		}
+		next := proxy.GetString("dialer-proxy")
+		config.RemoveProxy(current)
+		removeFromProxyGroups(config, current)
+		current = next
+	}
+	return nil
+}
+
+// 通过 Clash API 测试代理链整体的延迟，失败时返回 -1
+func testChainDelay(name string) int {
+	delay, err := defaultClashAPIClient().proxyDelay(name, defaultHealthCheckURL, 5*time.Second)
+	if err != nil {
+		return -1
+	}
+	return delay
+}
+
+// 交互式创建代理链
+func interactiveCreateChain(config *ClashConfig, reader *bufio.Reader) error {
+	fmt.Println()
+	printChains(config)
+	fmt.Println(T("\n可用的节点:"))
+	for i, proxy := range config.Proxies {
+		fmt.Printf("%d. %s (%s)\n", i+1, proxy.Name, proxy.Type)
+	}
+
+	name := promptLine(reader, T("\n代理链名称: "))
+	hops := promptList(reader, T("按转发顺序输入各跳的节点或代理组，用逗号分隔，最后一个为出口: "))
+	mode := chainModeRelay
+	if strings.ToLower(promptLine(reader, T("使用 dialer-proxy 串联 (需要 Meta 内核)? [y/N]: "))) == "y" {
+		mode = chainModeDialer
+	}
+	return createChain(config, name, hops, mode)
+}
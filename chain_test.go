@@ -0,0 +1,174 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const chainTestConfig = `
+proxies:
+  - {name: a, type: ss, server: 1.1.1.1, port: 8388, cipher: aes-256-gcm, password: p, udp: true}
+  - {name: b, type: http, server: 1.1.1.2, port: 80}
+  - {name: c, type: http, server: 1.1.1.3, port: 80}
+  - {name: hy, type: hysteria2, server: 1.1.1.4, port: 443, password: p}
+  - {name: X/b, type: http, server: 1.1.1.5, port: 80}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [a, b, c]}
+  - {name: Fast, type: select, proxies: [a, b]}
+  - {name: Old, type: relay, proxies: [a, b]}
+`
+
+func TestCreateChain(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain string
+		hops  []string
+		mode  string
+		// 期望的错误，为空表示创建成功
+		wantErr error
+		// 创建成功后 chainHops 返回的各跳
+		wantHops []string
+		// dialer 模式新增的节点及其 dialer-proxy
+		wantDialers map[string]string
+	}{
+		{
+			name:     "relay 代理组可以包含代理组",
+			chain:    "R",
+			hops:     []string{"Fast", "b", "c"},
+			mode:     chainModeRelay,
+			wantHops: []string{"Fast", "b", "c"},
+		},
+		{
+			name:        "dialer 模式复制第二跳之后的节点",
+			chain:       "D",
+			hops:        []string{"Fast", "b", "c"},
+			mode:        chainModeDialer,
+			wantHops:    []string{"Fast", "D/b", "D"},
+			wantDialers: map[string]string{"D/b": "Fast", "D": "D/b"},
+		},
+		{
+			name:        "UDP 传输的节点可以跟在开启 udp 的节点之后",
+			chain:       "H",
+			hops:        []string{"a", "hy"},
+			mode:        chainModeDialer,
+			wantHops:    []string{"a", "H"},
+			wantDialers: map[string]string{"H": "a"},
+		},
+		{
+			name:    "relay 模式下 UDP 传输的节点只能作为第一跳",
+			chain:   "H",
+			hops:    []string{"a", "hy"},
+			mode:    chainModeRelay,
+			wantErr: errAny,
+		},
+		{
+			name:    "dialer 模式只有第一跳可以是代理组",
+			chain:   "D",
+			hops:    []string{"a", "Fast"},
+			mode:    chainModeDialer,
+			wantErr: errAny,
+		},
+		{
+			name:    "名称已被节点使用",
+			chain:   "a",
+			hops:    []string{"b", "c"},
+			mode:    chainModeRelay,
+			wantErr: ErrNameConflict,
+		},
+		{
+			name:    "副本名称与已有节点冲突时不做任何修改",
+			chain:   "X",
+			hops:    []string{"a", "b", "c"},
+			mode:    chainModeDialer,
+			wantErr: ErrNameConflict,
+		},
+		{
+			name:    "副本名称重复时不做任何修改",
+			chain:   "Y",
+			hops:    []string{"a", "b", "c", "b", "c"},
+			mode:    chainModeDialer,
+			wantErr: ErrNameConflict,
+		},
+		{
+			name:    "至少需要两跳",
+			chain:   "R",
+			hops:    []string{"a"},
+			mode:    chainModeRelay,
+			wantErr: errAny,
+		},
+		{
+			name:    "不能使用内置策略",
+			chain:   "R",
+			hops:    []string{"a", "DIRECT"},
+			mode:    chainModeRelay,
+			wantErr: errAny,
+		},
+		{
+			name:    "relay 代理组不能嵌套",
+			chain:   "R",
+			hops:    []string{"Old", "c"},
+			mode:    chainModeRelay,
+			wantErr: errAny,
+		},
+		{
+			name:    "不支持的模式",
+			chain:   "R",
+			hops:    []string{"a", "b"},
+			mode:    "tunnel",
+			wantErr: errAny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := mustParseConfig(t, chainTestConfig)
+			if !expectConfigOp(t, config, tt.wantErr, func() error {
+				return createChain(config, tt.chain, tt.hops, tt.mode)
+			}) {
+				return
+			}
+
+			if got := chainHops(config, tt.chain); !reflect.DeepEqual(got, tt.wantHops) {
+				t.Errorf("chainHops = %v，期望 %v", got, tt.wantHops)
+			}
+			for name, dialer := range tt.wantDialers {
+				proxy := config.FindProxy(name)
+				if proxy == nil {
+					t.Errorf("缺少节点 %s", name)
+					continue
+				}
+				if got := proxy.GetString("dialer-proxy"); got != dialer {
+					t.Errorf("%s 的 dialer-proxy = %q，期望 %q", name, got, dialer)
+				}
+			}
+			if !containsString(config.FindGroup("Proxy").Proxies, tt.chain) {
+				t.Errorf("代理链 %s 没有加入主代理组", tt.chain)
+			}
+			if err := validateConfig(config); err != nil {
+				t.Errorf("创建后的配置无效: %v", err)
+			}
+		})
+	}
+}
+
+func TestProxyGroupDialerReferences(t *testing.T) {
+	config := mustParseConfig(t, chainTestConfig)
+	if err := createChain(config, "D", []string{"Fast", "b", "c"}, chainModeDialer); err != nil {
+		t.Fatalf("createChain: %v", err)
+	}
+
+	// 作为 dialer-proxy 第一跳的代理组不能删除
+	expectConfigOp(t, config, ErrReferencedByChain, func() error {
+		return deleteProxyGroup(config, "Fast")
+	})
+
+	if err := renameProxyGroup(config, "Fast", "Quick"); err != nil {
+		t.Fatalf("renameProxyGroup: %v", err)
+	}
+	if got := config.FindProxy("D/b").GetString("dialer-proxy"); got != "Quick" {
+		t.Errorf("重命名后 dialer-proxy = %q，期望 Quick", got)
+	}
+	if want := []string{"Quick", "D/b", "D"}; !reflect.DeepEqual(chainHops(config, "D"), want) {
+		t.Errorf("chainHops = %v，期望 %v", chainHops(config, "D"), want)
+	}
+}
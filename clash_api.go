@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 未配置 external-controller 时 Clash 控制接口的默认地址
+const defaultClashController = "127.0.0.1:9090"
+
+// 控制接口请求的默认超时时间
+const clashAPITimeout = 5 * time.Second
+
+// 控制接口返回了非 2xx 状态码
+type ClashAPIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Clash 返回的 message 字段，没有时为响应内容
+	Message string
+}
+
+func (e *ClashAPIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf(T("Clash 控制接口 %s %s 返回状态码 %d"), e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf(T("Clash 控制接口 %s %s 返回状态码 %d: %s"), e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// 401/403 通常是 secret 不正确，可以用 errors.Is(err, ErrClashAPIUnauthorized) 判断
+func (e *ClashAPIError) Unwrap() error {
+	if e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden {
+		return ErrClashAPIUnauthorized
+	}
+	return nil
+}
+
+// 控制接口返回的代理或代理组，代理组才有 Now 和 All
+type clashAPIProxy struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Now     string   `json:"now"`
+	All     []string `json:"all"`
+	History []struct {
+		Delay int `json:"delay"`
+	} `json:"history"`
+}
+
+// Clash 控制接口 (external-controller) 的客户端
+type clashAPIClient struct {
+	baseURL string
+	secret  string
+	timeout time.Duration
+}
+
+// 根据配置中的 external-controller 和 secret 创建客户端，config 为 nil 时使用默认地址
+func newClashAPIClient(config *ClashConfig) *clashAPIClient {
+	client := &clashAPIClient{baseURL: clashControllerURL(config), timeout: clashAPITimeout}
+	if config != nil {
+		client.secret = config.Secret
+	}
+	return client
+}
+
+// 使用当前配置文件创建客户端，读取失败时使用默认地址
+func defaultClashAPIClient() *clashAPIClient {
+	config, err := readClashConfig()
+	if err != nil {
+		return newClashAPIClient(nil)
+	}
+	return newClashAPIClient(config)
+}
+
+// 返回使用指定超时时间的副本
+func (c *clashAPIClient) withTimeout(timeout time.Duration) *clashAPIClient {
+	copied := *c
+	copied.timeout = timeout
+	return &copied
+}
+
+// 根据 external-controller 得到本机访问控制接口的地址
+func clashControllerURL(config *ClashConfig) string {
+	address := defaultClashController
+	if config != nil && config.ExternalController != "" {
+		address = config.ExternalController
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "http://" + address
+	}
+	// 监听所有地址时通过本机回环地址访问
+	switch host {
+	case "", "0.0.0.0":
+		host = "127.0.0.1"
+	case "::":
+		host = "::1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// 发送请求，body 不为 nil 时编码为 JSON，out 不为 nil 时解码响应
+func (c *clashAPIClient) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.secret != "" {
+		req.Header.Set("Authorization", "Bearer "+c.secret)
+	}
+
+	client := &http.Client{Timeout: c.timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return withKind(ErrClashAPIUnreachable, fmt.Errorf(T("无法连接 Clash 控制接口: %w"), err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		apiErr := &ClashAPIError{Method: method, Path: path, StatusCode: resp.StatusCode}
+		var message struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &message) == nil && message.Message != "" {
+			apiErr.Message = message.Message
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf(T("解析 Clash 控制接口 %s 的响应失败: %w"), path, err)
+	}
+	return nil
+}
+
+// 请求控制接口的任意路径并丢弃响应，用于诊断
+func (c *clashAPIClient) check(path string) error {
+	return c.do("GET", path, nil, nil)
+}
+
+// 获取 Clash 的版本
+func (c *clashAPIClient) version() (string, error) {
+	var result struct {
+		Version string `json:"version"`
+	}
+	if err := c.do("GET", "/version", nil, &result); err != nil {
+		return "", err
+	}
+	return result.Version, nil
+}
+
+// 获取所有代理和代理组，键为名称
+func (c *clashAPIClient) proxies() (map[string]clashAPIProxy, error) {
+	var result struct {
+		Proxies map[string]clashAPIProxy `json:"proxies"`
+	}
+	if err := c.do("GET", "/proxies", nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Proxies == nil {
+		return nil, errors.New(T("无法获取代理信息"))
+	}
+	return result.Proxies, nil
+}
+
+// 获取单个代理或代理组
+func (c *clashAPIClient) proxy(name string) (*clashAPIProxy, error) {
+	var result clashAPIProxy
+	if err := c.do("GET", "/proxies/"+url.PathEscape(name), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// 切换 select 代理组选中的节点
+func (c *clashAPIClient) selectProxy(group, name string) error {
+	body := struct {
+		Name string `json:"name"`
+	}{name}
+	return c.do("PUT", "/proxies/"+url.PathEscape(group), body, nil)
+}
+
+// 通过 Clash 测试代理的延迟，超时或无法连接时返回 -1 而不是错误
+func (c *clashAPIClient) proxyDelay(name, testURL string, timeout time.Duration) (int, error) {
+	query := url.Values{}
+	query.Set("url", testURL)
+	query.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+
+	var result struct {
+		Delay int `json:"delay"`
+	}
+	// 给 Clash 留出返回测速结果的时间
+	client := c.withTimeout(timeout + c.timeout)
+	err := client.do("GET", "/proxies/"+url.PathEscape(name)+"/delay?"+query.Encode(), nil, &result)
+
+	// Clash 用 408 表示测速超时，503 表示节点无法连接
+	var apiErr *ClashAPIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusRequestTimeout || apiErr.StatusCode == http.StatusServiceUnavailable) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	if result.Delay <= 0 {
+		return -1, nil
+	}
+	return result.Delay, nil
+}
+
+// 让 Clash 重新拉取 proxy-provider
+func (c *clashAPIClient) refreshProxyProvider(name string) error {
+	return c.do("PUT", "/providers/proxies/"+url.PathEscape(name), nil, nil)
+}
+
+// 让 Clash 重新加载 rule-provider
+func (c *clashAPIClient) reloadRuleProvider(name string) error {
+	return c.do("PUT", "/providers/rules/"+url.PathEscape(name), nil, nil)
+}
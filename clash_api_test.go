@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 控制接口收到的请求
+type recordedAPIRequest struct {
+	method string
+	path   string
+	// 转义后的原始路径
+	escapedPath string
+	query       string
+	auth        string
+	body        map[string]interface{}
+}
+
+// 启动模拟的控制接口，返回指向它的客户端和收到的请求
+func newTestClashAPI(t *testing.T, secret string, handler func(w http.ResponseWriter, r *http.Request)) (*clashAPIClient, *[]recordedAPIRequest) {
+	t.Helper()
+	var requests []recordedAPIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := recordedAPIRequest{
+			method:      r.Method,
+			path:        r.URL.Path,
+			escapedPath: r.URL.EscapedPath(),
+			query:       r.URL.RawQuery,
+			auth:        r.Header.Get("Authorization"),
+		}
+		json.NewDecoder(r.Body).Decode(&req.body)
+		requests = append(requests, req)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	config := &ClashConfig{ExternalController: strings.TrimPrefix(server.URL, "http://"), Secret: secret}
+	return newClashAPIClient(config), &requests
+}
+
+func TestClashAPIClientRequests(t *testing.T) {
+	client, requests := newTestClashAPI(t, "s3cret", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/delay") {
+			w.Write([]byte(`{"delay": 123}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	group := "🚀 节点/选择 #1"
+	if err := client.selectProxy(group, "HK 01?"); err != nil {
+		t.Fatalf("selectProxy: %v", err)
+	}
+	delay, err := client.proxyDelay("a/b", "http://example.com/?x=1&y=2", time.Second)
+	if err != nil {
+		t.Fatalf("proxyDelay: %v", err)
+	}
+	if delay != 123 {
+		t.Errorf("延迟 = %d，期望 123", delay)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("收到 %d 个请求，期望 2 个", len(*requests))
+	}
+	selectReq, delayReq := (*requests)[0], (*requests)[1]
+
+	// 名称中的 / 和 # 经过转义，不会改变请求路径
+	if selectReq.method != "PUT" || selectReq.path != "/proxies/"+group {
+		t.Errorf("选择节点的请求 = %s %s", selectReq.method, selectReq.path)
+	}
+	if !strings.Contains(selectReq.escapedPath, "%2F") {
+		t.Errorf("代理组名称中的 / 没有转义: %s", selectReq.escapedPath)
+	}
+	if selectReq.body["name"] != "HK 01?" {
+		t.Errorf("请求内容 = %v", selectReq.body)
+	}
+	if delayReq.path != "/proxies/a/b/delay" || !strings.HasPrefix(delayReq.escapedPath, "/proxies/a%2Fb/") {
+		t.Errorf("测速请求的路径 = %s (%s)", delayReq.path, delayReq.escapedPath)
+	}
+	if !strings.Contains(delayReq.query, "url=http%3A%2F%2Fexample.com%2F%3Fx%3D1%26y%3D2") || !strings.Contains(delayReq.query, "timeout=1000") {
+		t.Errorf("测速请求的参数 = %s", delayReq.query)
+	}
+	for _, req := range *requests {
+		if req.auth != "Bearer s3cret" {
+			t.Errorf("%s %s 的 Authorization = %q", req.method, req.path, req.auth)
+		}
+	}
+}
+
+func TestClashAPIClientWithoutSecret(t *testing.T) {
+	client, requests := newTestClashAPI(t, "", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version": "v1.18.0"}`))
+	})
+
+	version, err := client.version()
+	if err != nil {
+		t.Fatalf("version: %v", err)
+	}
+	if version != "v1.18.0" {
+		t.Errorf("版本 = %s", version)
+	}
+	if auth := (*requests)[0].auth; auth != "" {
+		t.Errorf("没有 secret 时不应发送 Authorization，得到 %q", auth)
+	}
+}
+
+func TestClashAPIClientErrors(t *testing.T) {
+	t.Run("secret 不正确", func(t *testing.T) {
+		client, _ := newTestClashAPI(t, "wrong", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message": "Unauthorized"}`))
+		})
+		err := client.check("/version")
+		if !errors.Is(err, ErrClashAPIUnauthorized) {
+			t.Errorf("期望 ErrClashAPIUnauthorized，得到: %v", err)
+		}
+		var apiErr *ClashAPIError
+		if !errors.As(err, &apiErr) || apiErr.Message != "Unauthorized" {
+			t.Errorf("期望 ClashAPIError，得到: %v", err)
+		}
+	})
+
+	t.Run("测速超时返回 -1", func(t *testing.T) {
+		client, _ := newTestClashAPI(t, "", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusRequestTimeout)
+		})
+		delay, err := client.proxyDelay("a", "http://example.com", time.Second)
+		if err != nil || delay != -1 {
+			t.Errorf("得到 %d, %v，期望 -1, nil", delay, err)
+		}
+	})
+
+	t.Run("无法连接", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		client := newClashAPIClient(&ClashConfig{ExternalController: strings.TrimPrefix(server.URL, "http://")})
+		if _, err := client.version(); !errors.Is(err, ErrClashAPIUnreachable) {
+			t.Errorf("期望 ErrClashAPIUnreachable，得到: %v", err)
+		}
+	})
+}
+
+func TestClashControllerURL(t *testing.T) {
+	tests := []struct {
+		controller string
+		want       string
+	}{
+		{"", "http://127.0.0.1:9090"},
+		{"127.0.0.1:9097", "http://127.0.0.1:9097"},
+		{"0.0.0.0:9090", "http://127.0.0.1:9090"},
+		{":9090", "http://127.0.0.1:9090"},
+		{"[::]:9090", "http://[::1]:9090"},
+		{"192.168.1.2:9090", "http://192.168.1.2:9090"},
+	}
+
+	for _, tt := range tests {
+		if got := clashControllerURL(&ClashConfig{ExternalController: tt.controller}); got != tt.want {
+			t.Errorf("clashControllerURL(%q) = %s，期望 %s", tt.controller, got, tt.want)
+		}
+	}
+}
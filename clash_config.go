@@ -0,0 +1,337 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Clash 配置文件的类型化模型
+// 未在结构体中声明的字段保存在 Extra 中，保存时原样写回
+type ClashConfig struct {
+	Port               PortValue `yaml:"port,omitempty"`
+	SocksPort          PortValue `yaml:"socks-port,omitempty"`
+	MixedPort          PortValue `yaml:"mixed-port,omitempty"`
+	AllowLan           *bool     `yaml:"allow-lan,omitempty"`
+	BindAddress        string    `yaml:"bind-address,omitempty"`
+	Mode               string    `yaml:"mode,omitempty"`
+	LogLevel           string    `yaml:"log-level,omitempty"`
+	IPv6               *bool     `yaml:"ipv6,omitempty"`
+	ExternalController string    `yaml:"external-controller,omitempty"`
+	ExternalUI         string    `yaml:"external-ui,omitempty"`
+	Secret             string    `yaml:"secret,omitempty"`
+
+	DNS *DNSConfig `yaml:"dns,omitempty"`
+	Tun *TunConfig `yaml:"tun,omitempty"`
+
+	Proxies        []Proxy                  `yaml:"proxies,omitempty"`
+	ProxyGroups    []ProxyGroup             `yaml:"proxy-groups,omitempty"`
+	ProxyProviders map[string]ProxyProvider `yaml:"proxy-providers,omitempty"`
+	RuleProviders  map[string]RuleProvider  `yaml:"rule-providers,omitempty"`
+	Rules          []string                 `yaml:"rules,omitempty"`
+
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// DNS 配置
+type DNSConfig struct {
+	Enable            *bool                  `yaml:"enable,omitempty"`
+	Listen            string                 `yaml:"listen,omitempty"`
+	IPv6              *bool                  `yaml:"ipv6,omitempty"`
+	DefaultNameserver []string               `yaml:"default-nameserver,omitempty"`
+	EnhancedMode      string                 `yaml:"enhanced-mode,omitempty"`
+	FakeIPRange       string                 `yaml:"fake-ip-range,omitempty"`
+	UseHosts          *bool                  `yaml:"use-hosts,omitempty"`
+	Nameserver        []string               `yaml:"nameserver,omitempty"`
+	Fallback          []string               `yaml:"fallback,omitempty"`
+	FallbackFilter    map[string]interface{} `yaml:"fallback-filter,omitempty"`
+	Extra             map[string]interface{} `yaml:",inline"`
+}
+
+// TUN 模式配置
+type TunConfig struct {
+	Enable              *bool                  `yaml:"enable,omitempty"`
+	Stack               string                 `yaml:"stack,omitempty"`
+	AutoRoute           *bool                  `yaml:"auto-route,omitempty"`
+	AutoDetectInterface *bool                  `yaml:"auto-detect-interface,omitempty"`
+	DNSHijack           []string               `yaml:"dns-hijack,omitempty"`
+	Extra               map[string]interface{} `yaml:",inline"`
+}
+
+// 代理节点，协议相关的字段（uuid、password、cipher 等）保存在 Extra 中
+type Proxy struct {
+	Name   string                 `yaml:"name"`
+	Type   string                 `yaml:"type"`
+	Server string                 `yaml:"server,omitempty"`
+	Port   PortValue              `yaml:"port,omitempty"`
+	Extra  map[string]interface{} `yaml:",inline"`
+}
+
+// 代理组
+type ProxyGroup struct {
+	Name      string                 `yaml:"name"`
+	Type      string                 `yaml:"type"`
+	Proxies   []string               `yaml:"proxies,omitempty"`
+	Use       []string               `yaml:"use,omitempty"`
+	URL       string                 `yaml:"url,omitempty"`
+	Interval  int                    `yaml:"interval,omitempty"`
+	Tolerance int                    `yaml:"tolerance,omitempty"`
+	Lazy      *bool                  `yaml:"lazy,omitempty"`
+	Strategy  string                 `yaml:"strategy,omitempty"`
+	Selected  string                 `yaml:"selected,omitempty"`
+	Extra     map[string]interface{} `yaml:",inline"`
+}
+
+// proxy-provider 配置
+type ProxyProvider struct {
+	Type        string                 `yaml:"type"`
+	URL         string                 `yaml:"url,omitempty"`
+	Interval    int                    `yaml:"interval,omitempty"`
+	Path        string                 `yaml:"path,omitempty"`
+	HealthCheck *HealthCheck           `yaml:"health-check,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// rule-provider 配置，供 RULE-SET 规则引用
+type RuleProvider struct {
+	Type     string                 `yaml:"type"`
+	Behavior string                 `yaml:"behavior"`
+	URL      string                 `yaml:"url,omitempty"`
+	Path     string                 `yaml:"path,omitempty"`
+	Interval int                    `yaml:"interval,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+// proxy-provider 的健康检查配置
+type HealthCheck struct {
+	Enable   bool                   `yaml:"enable"`
+	URL      string                 `yaml:"url,omitempty"`
+	Interval int                    `yaml:"interval,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+// 端口号，兼容配置文件中的整数和字符串两种写法
+// 无法解析的值原样保存在 Raw 中，保存时写回，由 validateConfig 报告
+type PortValue struct {
+	Number int
+	Raw    string
+}
+
+// 解析整数或字符串形式的端口
+func (p *PortValue) UnmarshalYAML(node *yaml.Node) error {
+	*p = portValueOf(node.Value)
+	return nil
+}
+
+// 按配置文件的规则转换端口，无法解析的值保存在 Raw 中而不是丢弃
+func portValueOf(value string) PortValue {
+	if strings.TrimSpace(value) == "" {
+		return PortValue{}
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return PortValue{Raw: value}
+	}
+	return PortValue{Number: port}
+}
+
+// 无效的值原样写回，有效的端口统一写成整数
+func (p PortValue) MarshalYAML() (interface{}, error) {
+	if p.Raw != "" {
+		return p.Raw, nil
+	}
+	return p.Number, nil
+}
+
+// 供 omitempty 判断端口是否未设置
+func (p PortValue) IsZero() bool {
+	return p.Number == 0 && p.Raw == ""
+}
+
+// 端口是否为无法解析的值
+func (p PortValue) Invalid() bool {
+	return p.Raw != ""
+}
+
+// 端口的字符串形式，未设置时为空
+func (p PortValue) String() string {
+	if p.Raw != "" {
+		return p.Raw
+	}
+	if p.Number == 0 {
+		return ""
+	}
+	return strconv.Itoa(p.Number)
+}
+
+// 解析端口字符串
+func parsePortValue(s string) (PortValue, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || port <= 0 || port > 65535 {
+		return PortValue{}, fmt.Errorf(T("无效的端口: %s"), s)
+	}
+	return PortValue{Number: port}, nil
+}
+
+// 解析 Clash 配置内容
+func parseClashConfig(content []byte) (*ClashConfig, error) {
+	var config ClashConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// 获取代理的字符串字段，字段不存在时返回空字符串
+func (p *Proxy) GetString(key string) string {
+	switch key {
+	case "name":
+		return p.Name
+	case "type":
+		return p.Type
+	case "server":
+		return p.Server
+	case "port":
+		return p.Port.String()
+	}
+
+	value, ok := p.Extra[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// 设置代理字段
+func (p *Proxy) Set(key string, value interface{}) {
+	switch key {
+	case "name":
+		p.Name = fmt.Sprintf("%v", value)
+		return
+	case "type":
+		p.Type = fmt.Sprintf("%v", value)
+		return
+	case "server":
+		p.Server = fmt.Sprintf("%v", value)
+		return
+	case "port":
+		p.Port = portValueOf(fmt.Sprintf("%v", value))
+		return
+	}
+
+	if p.Extra == nil {
+		p.Extra = make(map[string]interface{})
+	}
+	p.Extra[key] = value
+}
+
+// 代理是否包含指定字段
+func (p *Proxy) Has(key string) bool {
+	switch key {
+	case "name", "type", "server", "port":
+		return p.GetString(key) != ""
+	}
+	_, ok := p.Extra[key]
+	return ok
+}
+
+// 将 URI 解析器等返回的 map 转换为类型化的代理
+func proxyFromMap(m map[string]interface{}) (Proxy, error) {
+	var proxy Proxy
+
+	content, err := yaml.Marshal(m)
+	if err != nil {
+		return proxy, err
+	}
+	if err := yaml.Unmarshal(content, &proxy); err != nil {
+		return proxy, err
+	}
+	if proxy.Name == "" {
+		return proxy, errors.New(T("代理缺少名称"))
+	}
+
+	return proxy, nil
+}
+
+// 将交互式收集的代理配置转换为类型化的代理
+func (pc ProxyConfig) toProxy() (Proxy, error) {
+	port, err := parsePortValue(pc.Port)
+	if err != nil {
+		return Proxy{}, err
+	}
+
+	proxy := Proxy{
+		Name:   pc.Name,
+		Type:   pc.Type,
+		Server: pc.Server,
+		Port:   port,
+	}
+
+	// 添加特定类型的配置
+	switch pc.Type {
+	case "ss", "shadowsocks":
+		proxy.Set("cipher", pc.Cipher)
+		proxy.Set("password", pc.Password)
+	case "vmess":
+		proxy.Set("uuid", pc.UUID)
+		if alterId, err := strconv.Atoi(pc.AlterId); err == nil {
+			proxy.Set("alterId", alterId)
+		} else {
+			proxy.Set("alterId", 0)
+		}
+		proxy.Set("cipher", pc.Cipher)
+	case "trojan":
+		proxy.Set("password", pc.Password)
+		if pc.SNI != "" {
+			proxy.Set("sni", pc.SNI)
+		}
+	}
+
+	// 添加共用选项
+	proxy.Set("udp", true)
+
+	return proxy, nil
+}
+
+// 按名称查找代理，未找到时返回 nil
+func (c *ClashConfig) FindProxy(name string) *Proxy {
+	for i := range c.Proxies {
+		if c.Proxies[i].Name == name {
+			return &c.Proxies[i]
+		}
+	}
+	return nil
+}
+
+// 按名称删除代理，返回是否找到
+func (c *ClashConfig) RemoveProxy(name string) bool {
+	for i := range c.Proxies {
+		if c.Proxies[i].Name == name {
+			c.Proxies = append(c.Proxies[:i], c.Proxies[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// 所有代理的名称
+func (c *ClashConfig) ProxyNames() []string {
+	names := make([]string, 0, len(c.Proxies))
+	for _, proxy := range c.Proxies {
+		names = append(names, proxy.Name)
+	}
+	return names
+}
+
+// 按名称查找代理组，未找到时返回 nil
+func (c *ClashConfig) FindGroup(name string) *ProxyGroup {
+	for i := range c.ProxyGroups {
+		if c.ProxyGroups[i].Name == name {
+			return &c.ProxyGroups[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 最多保留的快照数量，超出后删除最旧的
+	maxConfigBackups = 20
+	// 快照文件名中的时间格式
+	backupTimeFormat = "20060102-150405"
+	// 重启后等待 Clash 就绪的最长时间
+	clashHealthTimeout = 15 * time.Second
+	// 重启后服务短暂处于未运行状态，超过这段时间仍未运行才认为已退出
+	clashExitGracePeriod = 3 * time.Second
+)
+
+// 配置快照所在目录
+func clashBackupDir() string {
+	return clashWorkPath("backups")
+}
+
+// 一个配置快照
+type configBackup struct {
+	Path string
+	Time time.Time
+	Size int64
+}
+
+// 快照的时间戳，同时也是 rollback 可以使用的标识
+func (b configBackup) Timestamp() string {
+	return strings.TrimSuffix(strings.TrimPrefix(filepath.Base(b.Path), "config-"), ".yaml")
+}
+
+// 处理 config 子命令
+func manageConfig(args []string) {
+	if len(args) == 0 {
+		printConfigUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "history":
+		if err := showConfigHistory(); err != nil {
+			fmt.Printf(T("读取配置历史失败: %v\n"), err)
+			os.Exit(1)
+		}
+	case "rollback":
+		target := ""
+		if len(args) > 1 {
+			target = args[1]
+		}
+		if err := rollbackConfig(target); err != nil {
+			fmt.Printf(T("回滚配置失败: %v\n"), err)
+			os.Exit(1)
+		}
+	case "diff":
+		if err := showConfigDiff(args[1:]); err != nil {
+			fmt.Printf(T("比较配置失败: %v\n"), err)
+			os.Exit(1)
+		}
+	case "render":
+		if err := showRenderedConfig(); err != nil {
+			fmt.Printf(T("生成配置失败: %v\n"), err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf(T("未知的 config 子命令: %s\n"), args[0])
+		printConfigUsage()
+		os.Exit(1)
+	}
+}
+
+// 显示 config 子命令的用法
+func printConfigUsage() {
+	fmt.Printf(T("用法: %s config <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  history                  列出配置快照，最新的在最前"))
+	fmt.Println(T("  rollback [序号|时间戳]   恢复指定快照并重启 Clash，默认恢复最近一次"))
+	fmt.Println(T("  diff [-u] <a> [b]        比较两份配置，a/b 可以是文件路径、快照序号、时间戳或 live"))
+	fmt.Println(T("                           b 默认为当前配置，-u 输出统一格式的文本差异"))
+	fmt.Println(T("  render                   输出内置模板、conf.d/*.yaml 和当前节点合并后的配置"))
+}
+
+// 写入 Clash 配置文件，写入前先为旧配置保存快照
+func writeClashConfig(content []byte) error {
+	if _, err := backupClashConfig(); err != nil {
+		return fmt.Errorf(T("备份配置失败: %w"), err)
+	}
+	return writeFileAtomic(clashConfigPath(), content, 0644)
+}
+
+// 原子地写入文件：先写临时文件并同步到磁盘，再重命名覆盖目标文件
+// 目标为符号链接时写入其指向的文件，保留链接本身
+// 目标已存在时沿用它的权限，perm 只用于新建的文件
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	// 出错时清理临时文件，重命名成功后删除不会产生影响
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// 同步目录，确保重命名本身也已落盘
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// 为当前配置文件保存一份带时间戳的快照，配置文件不存在时不做任何事
+func backupClashConfig() (string, error) {
+	content, err := os.ReadFile(clashConfigPath())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// 与最近一次快照相同时不再重复保存
+	if backups, err := listConfigBackups(); err == nil && len(backups) > 0 {
+		if latest, err := os.ReadFile(backups[0].Path); err == nil && string(latest) == string(content) {
+			return backups[0].Path, nil
+		}
+	}
+
+	if err := os.MkdirAll(clashBackupDir(), 0755); err != nil {
+		return "", err
+	}
+
+	// 同一秒内多次保存时追加序号
+	stamp := time.Now().Format(backupTimeFormat)
+	path := filepath.Join(clashBackupDir(), "config-"+stamp+".yaml")
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(clashBackupDir(), fmt.Sprintf("config-%s.%d.yaml", stamp, i))
+	}
+
+	if err := writeFileAtomic(path, content, 0600); err != nil {
+		return "", err
+	}
+
+	pruneConfigBackups()
+	return path, nil
+}
+
+// 删除超出数量上限的旧快照
+func pruneConfigBackups() {
+	backups, err := listConfigBackups()
+	if err != nil {
+		return
+	}
+	for _, backup := range backups[min(len(backups), maxConfigBackups):] {
+		os.Remove(backup.Path)
+	}
+}
+
+// 列出所有配置快照，最新的在最前
+func listConfigBackups() ([]configBackup, error) {
+	entries, err := os.ReadDir(clashBackupDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []configBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "config-") || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		// 时间以文件名为准，文件被复制过时修改时间可能不可靠
+		backup := configBackup{Path: filepath.Join(clashBackupDir(), name), Time: info.ModTime(), Size: info.Size()}
+		stamp := strings.SplitN(backup.Timestamp(), ".", 2)[0]
+		if t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local); err == nil {
+			backup.Time = t
+		}
+		backups = append(backups, backup)
+	}
+
+	// 文件名中的时间戳和序号保证了按名称排序即为时间顺序
+	sort.Slice(backups, func(i, j int) bool {
+		return backupSortKey(backups[i]) > backupSortKey(backups[j])
+	})
+	return backups, nil
+}
+
+// 快照排序用的键，保证 config-T.10 排在 config-T.9 之后
+func backupSortKey(b configBackup) string {
+	parts := strings.SplitN(b.Timestamp(), ".", 2)
+	seq := 0
+	if len(parts) == 2 {
+		seq, _ = strconv.Atoi(parts[1])
+	}
+	return fmt.Sprintf("%s.%06d", parts[0], seq)
+}
+
+// 显示配置快照列表
+func showConfigHistory() error {
+	backups, err := listConfigBackups()
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		fmt.Println(T("还没有配置快照，每次保存配置时会自动创建"))
+		return nil
+	}
+
+	fmt.Printf(T("共有 %d 个配置快照 (保存在 %s):\n\n"), len(backups), clashBackupDir())
+	for i, backup := range backups {
+		fmt.Printf(T("%2d. %s  %s  %d 字节\n"), i+1, backup.Timestamp(),
+			backup.Time.Format("2006-01-02 15:04:05"), backup.Size)
+	}
+	fmt.Printf(T("\n使用 %s config rollback <序号|时间戳> 恢复快照\n"), os.Args[0])
+	return nil
+}
+
+// 按序号或时间戳查找快照，序号从 1 开始，1 为最近一次
+func findConfigBackup(backups []configBackup, target string) (*configBackup, error) {
+	if len(backups) == 0 {
+		return nil, errors.New(T("没有可用的配置快照"))
+	}
+
+	if target == "" {
+		return &backups[0], nil
+	}
+
+	if n, err := strconv.Atoi(target); err == nil && len(target) < len(backupTimeFormat) {
+		if n < 1 || n > len(backups) {
+			return nil, fmt.Errorf(T("快照序号超出范围: %d (共 %d 个)"), n, len(backups))
+		}
+		return &backups[n-1], nil
+	}
+
+	// 时间戳允许只写前缀，例如 20240101-12
+	var matched []configBackup
+	for _, backup := range backups {
+		if strings.HasPrefix(backup.Timestamp(), target) {
+			matched = append(matched, backup)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return nil, fmt.Errorf(T("未找到时间戳为 %s 的快照"), target)
+	case 1:
+		return &matched[0], nil
+	default:
+		return nil, fmt.Errorf(T("时间戳 %s 匹配到 %d 个快照，请提供更完整的时间戳"), target, len(matched))
+	}
+}
+
+// 恢复指定的配置快照并重启 Clash
+func rollbackConfig(target string) error {
+	backups, err := listConfigBackups()
+	if err != nil {
+		return err
+	}
+
+	backup, err := findConfigBackup(backups, target)
+	if err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf(T("读取快照失败: %w"), err)
+	}
+
+	// 恢复前同样为当前配置保存快照，回滚本身也可以撤销
+	if err := writeClashConfig(content); err != nil {
+		return fmt.Errorf(T("写入配置失败: %w"), err)
+	}
+	fmt.Printf(T("已恢复配置快照 %s\n"), backup.Timestamp())
+
+	return restartClashWithHealthCheck()
+}
+
+// 重启 Clash 并确认其正常运行，失败时自动恢复上一份配置并再次重启
+func restartClashWithHealthCheck() error {
+	current, _ := os.ReadFile(clashConfigPath())
+	previous := previousConfigBackup(current)
+
+	fmt.Println(T("正在重启 Clash 服务..."))
+	err := restartClashAndWait()
+	if err == nil {
+		fmt.Println(T("Clash 服务已重启并正常运行"))
+		return nil
+	}
+	fmt.Printf(T("Clash 未能正常启动: %v\n"), err)
+
+	if previous == nil {
+		return errors.New(T("Clash 未能正常启动，且没有可恢复的配置快照"))
+	}
+
+	fmt.Printf(T("正在恢复上一份配置 (快照 %s)...\n"), previous.Timestamp())
+	content, readErr := os.ReadFile(previous.Path)
+	if readErr != nil {
+		return fmt.Errorf(T("读取快照失败: %w"), readErr)
+	}
+	if writeErr := writeClashConfig(content); writeErr != nil {
+		return fmt.Errorf(T("恢复配置失败: %w"), writeErr)
+	}
+
+	if retryErr := restartClashAndWait(); retryErr != nil {
+		return fmt.Errorf(T("已恢复上一份配置，但 Clash 仍未能启动: %w"), retryErr)
+	}
+	return errors.New(T("新配置导致 Clash 无法启动，已自动恢复上一份配置 (出错的配置已保存为快照，可用 config history 查看)"))
+}
+
+// 找到内容与当前配置不同的最近一份快照
+func previousConfigBackup(current []byte) *configBackup {
+	backups, err := listConfigBackups()
+	if err != nil {
+		return nil
+	}
+	for i := range backups {
+		content, err := os.ReadFile(backups[i].Path)
+		if err == nil && string(content) != string(current) {
+			return &backups[i]
+		}
+	}
+	return nil
+}
+
+// 重启 Clash 服务并等待控制接口可用
+func restartClashAndWait() error {
+	cmd := exec.Command("systemctl", "restart", "clash")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf(T("systemctl restart 失败: %w %s"), err, strings.TrimSpace(string(output)))
+	}
+	return waitForClashReady(clashHealthTimeout)
+}
+
+// 轮询控制接口的 /version，直到成功或超时
+func waitForClashReady(timeout time.Duration) error {
+	client := defaultClashAPIClient().withTimeout(2 * time.Second)
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	var lastErr error
+	for time.Now().Before(deadline) {
+		time.Sleep(500 * time.Millisecond)
+
+		// systemd 报告服务已退出时不必继续等待，给重启留出一点时间
+		if !isClashRunning() {
+			lastErr = errors.New(T("Clash 服务已退出"))
+			if time.Since(start) >= clashExitGracePeriod {
+				return lastErr
+			}
+			continue
+		}
+
+		_, err := client.version()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New(T("等待超时"))
+	}
+	return fmt.Errorf(T("%v 内未就绪: %w"), timeout, lastErr)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomicKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(existing, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// 绕过 umask，确保目标文件的权限就是 0600
+	if err := os.Chmod(existing, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(existing, []byte("new\n"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic 出错: %v", err)
+	}
+	checkFile(t, existing, "new\n", 0600)
+
+	created := filepath.Join(dir, "sub", "new.yaml")
+	if err := writeFileAtomic(created, []byte("new\n"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic 出错: %v", err)
+	}
+	checkFile(t, created, "new\n", 0644)
+}
+
+func checkFile(t *testing.T, path, wantContent string, wantMode os.FileMode) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != wantContent {
+		t.Errorf("%s 的内容为 %q，期望 %q", path, data, wantContent)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != wantMode {
+		t.Errorf("%s 的权限为 %v，期望 %v", path, info.Mode().Perm(), wantMode)
+	}
+}
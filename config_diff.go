@@ -0,0 +1,605 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 差异中不直接显示取值的敏感字段
+var sensitiveProxyFields = map[string]bool{
+	"password":    true,
+	"uuid":        true,
+	"psk":         true,
+	"private-key": true,
+	"auth-str":    true,
+}
+
+// 统一格式差异中每个修改块前后保留的上下文行数
+const diffContextLines = 3
+
+// 比较两份配置，返回按节点、代理组、规则等分类的语义差异
+func diffClashConfigs(old, updated *ClashConfig) []string {
+	var changes []string
+
+	changes = append(changes, diffGeneralSettings(old, updated)...)
+	changes = append(changes, diffProxies(old.Proxies, updated.Proxies)...)
+	changes = append(changes, diffProxyGroups(old.ProxyGroups, updated.ProxyGroups)...)
+
+	// proxy-providers
+	for _, name := range sortedKeys(old.ProxyProviders) {
+		if _, ok := updated.ProxyProviders[name]; !ok {
+			changes = append(changes, fmt.Sprintf("- proxy-provider %s", name))
+		}
+	}
+	for _, name := range sortedKeys(updated.ProxyProviders) {
+		before, ok := old.ProxyProviders[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("+ proxy-provider %s (%s)", name, updated.ProxyProviders[name].URL))
+		} else if !yamlValuesEqual(before, updated.ProxyProviders[name]) {
+			changes = append(changes, fmt.Sprintf(T("~ proxy-provider %s 已修改"), name))
+		}
+	}
+
+	// rule-providers
+	for _, name := range sortedKeys(old.RuleProviders) {
+		if _, ok := updated.RuleProviders[name]; !ok {
+			changes = append(changes, fmt.Sprintf("- rule-provider %s", name))
+		}
+	}
+	for _, name := range sortedKeys(updated.RuleProviders) {
+		before, ok := old.RuleProviders[name]
+		if !ok {
+			provider := updated.RuleProviders[name]
+			changes = append(changes, fmt.Sprintf("+ rule-provider %s (%s, %s)", name, provider.Type, provider.Behavior))
+		} else if !yamlValuesEqual(before, updated.RuleProviders[name]) {
+			changes = append(changes, fmt.Sprintf(T("~ rule-provider %s 已修改"), name))
+		}
+	}
+
+	// 规则按行比较，保留顺序信息
+	for _, line := range diffLines(old.Rules, updated.Rules) {
+		if line.op != ' ' {
+			changes = append(changes, fmt.Sprintf(T("%c 规则 %s"), line.op, line.text))
+		}
+	}
+
+	return changes
+}
+
+// 比较节点、代理组、规则之外的顶层设置
+func diffGeneralSettings(old, updated *ClashConfig) []string {
+	skip := map[string]bool{"proxies": true, "proxy-groups": true, "proxy-providers": true, "rule-providers": true, "rules": true}
+
+	oldRoot, err1 := encodeYAMLNode(old)
+	newRoot, err2 := encodeYAMLNode(updated)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	var changes []string
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(newRoot.Content); i += 2 {
+		key := newRoot.Content[i].Value
+		seen[key] = true
+		if skip[key] {
+			continue
+		}
+		before := mappingValue(oldRoot, key)
+		after := newRoot.Content[i+1]
+		switch {
+		case before == nil:
+			changes = append(changes, fmt.Sprintf("+ %s: %s", key, inlineYAML(after)))
+		case !yamlNodesEqual(before, after):
+			changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", key, inlineYAML(before), inlineYAML(after)))
+		}
+	}
+	for i := 0; i+1 < len(oldRoot.Content); i += 2 {
+		key := oldRoot.Content[i].Value
+		if !seen[key] && !skip[key] {
+			changes = append(changes, fmt.Sprintf("- %s", key))
+		}
+	}
+	return changes
+}
+
+// 比较节点列表
+func diffProxies(old, updated []Proxy) []string {
+	var changes []string
+
+	before := make(map[string]*Proxy)
+	for i := range old {
+		before[old[i].Name] = &old[i]
+	}
+	after := make(map[string]bool)
+	for _, proxy := range updated {
+		after[proxy.Name] = true
+	}
+
+	for _, proxy := range old {
+		if !after[proxy.Name] {
+			changes = append(changes, fmt.Sprintf(T("- 节点 %s"), proxy.Name))
+		}
+	}
+
+	for i := range updated {
+		proxy := &updated[i]
+		prev, ok := before[proxy.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf(T("+ 节点 %s (%s %s)"), proxy.Name, proxy.Type,
+				joinHostPortString(proxy.Server, proxy.Port.String())))
+			continue
+		}
+
+		fields := diffProxyFields(prev, proxy)
+		if len(fields) > 0 {
+			changes = append(changes, fmt.Sprintf(T("~ 节点 %s: %s"), proxy.Name, strings.Join(fields, ", ")))
+		}
+	}
+
+	return changes
+}
+
+// 比较单个节点的字段
+func diffProxyFields(old, updated *Proxy) []string {
+	oldFields := proxyFieldValues(old)
+	newFields := proxyFieldValues(updated)
+
+	keys := make(map[string]bool)
+	for key := range oldFields {
+		keys[key] = true
+	}
+	for key := range newFields {
+		keys[key] = true
+	}
+
+	var changes []string
+	for _, key := range sortedKeys(keys) {
+		before, hadBefore := oldFields[key]
+		after, hasAfter := newFields[key]
+		if hadBefore && hasAfter && before == after {
+			continue
+		}
+		if sensitiveProxyFields[key] {
+			changes = append(changes, fmt.Sprintf(T("%s 已修改"), key))
+			continue
+		}
+		switch {
+		case !hadBefore:
+			changes = append(changes, fmt.Sprintf("%s: %s", key, after))
+		case !hasAfter:
+			changes = append(changes, fmt.Sprintf(T("%s 已删除"), key))
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", key, before, after))
+		}
+	}
+	return changes
+}
+
+// 节点所有字段的文本形式
+func proxyFieldValues(proxy *Proxy) map[string]string {
+	values := map[string]string{
+		"type":   proxy.Type,
+		"server": proxy.Server,
+		"port":   proxy.Port.String(),
+	}
+	for key, value := range proxy.Extra {
+		content, err := yaml.Marshal(value)
+		if err != nil {
+			continue
+		}
+		values[key] = strings.TrimSpace(string(content))
+	}
+	return values
+}
+
+// 比较代理组，包括成员变化和选中节点变化
+func diffProxyGroups(old, updated []ProxyGroup) []string {
+	var changes []string
+
+	before := make(map[string]*ProxyGroup)
+	for i := range old {
+		before[old[i].Name] = &old[i]
+	}
+	after := make(map[string]bool)
+	for _, group := range updated {
+		after[group.Name] = true
+	}
+
+	for _, group := range old {
+		if !after[group.Name] {
+			changes = append(changes, fmt.Sprintf(T("- 代理组 %s"), group.Name))
+		}
+	}
+
+	for i := range updated {
+		group := &updated[i]
+		prev, ok := before[group.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf(T("+ 代理组 %s (%s, %d 个成员)"), group.Name, group.Type, len(group.Proxies)))
+			continue
+		}
+
+		var details []string
+		if prev.Type != group.Type {
+			details = append(details, fmt.Sprintf(T("类型 %s -> %s"), prev.Type, group.Type))
+		}
+		added, removed := diffStringSets(prev.Proxies, group.Proxies)
+		for _, name := range added {
+			details = append(details, "+"+name)
+		}
+		for _, name := range removed {
+			details = append(details, "-"+name)
+		}
+		if len(added) == 0 && len(removed) == 0 && strings.Join(prev.Proxies, "\n") != strings.Join(group.Proxies, "\n") {
+			details = append(details, T("成员顺序已调整"))
+		}
+		added, removed = diffStringSets(prev.Use, group.Use)
+		for _, name := range added {
+			details = append(details, "+use:"+name)
+		}
+		for _, name := range removed {
+			details = append(details, "-use:"+name)
+		}
+		if prev.Selected != group.Selected {
+			details = append(details, fmt.Sprintf(T("选中 %s -> %s"), displayOrNone(prev.Selected), displayOrNone(group.Selected)))
+		}
+
+		// 其余字段（url、interval 等）只提示已修改
+		prevCopy, groupCopy := *prev, *group
+		prevCopy.Proxies, prevCopy.Use, prevCopy.Selected, prevCopy.Type = nil, nil, "", ""
+		groupCopy.Proxies, groupCopy.Use, groupCopy.Selected, groupCopy.Type = nil, nil, "", ""
+		if !yamlValuesEqual(prevCopy, groupCopy) {
+			details = append(details, T("其他参数已修改"))
+		}
+
+		if len(details) > 0 {
+			changes = append(changes, fmt.Sprintf(T("~ 代理组 %s: %s"), group.Name, strings.Join(details, ", ")))
+		}
+	}
+
+	return changes
+}
+
+// 两组名称的差集，保持原有顺序
+func diffStringSets(old, updated []string) (added, removed []string) {
+	for _, name := range updated {
+		if !containsString(old, name) {
+			added = append(added, name)
+		}
+	}
+	for _, name := range old {
+		if !containsString(updated, name) {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+// 空值显示为“无”
+func displayOrNone(s string) string {
+	if s == "" {
+		return T("无")
+	}
+	return s
+}
+
+// 服务器和端口的显示形式
+func joinHostPortString(server, port string) string {
+	if port == "" {
+		return server
+	}
+	if strings.Contains(server, ":") {
+		return "[" + server + "]:" + port
+	}
+	return server + ":" + port
+}
+
+// 两个值编码后是否一致
+func yamlValuesEqual(a, b interface{}) bool {
+	aa, errA := yaml.Marshal(a)
+	bb, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && string(aa) == string(bb)
+}
+
+// 节点的单行 flow 格式
+func inlineYAML(node *yaml.Node) string {
+	flow := *node
+	flow.Style |= yaml.FlowStyle
+	content, err := yaml.Marshal(&flow)
+	if err != nil {
+		return node.Value
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// map 的键排序后返回
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 打印语义差异
+func printConfigChanges(changes []string) {
+	if len(changes) == 0 {
+		fmt.Println(T("配置没有变化"))
+		return
+	}
+	fmt.Printf(T("共 %d 处变化:\n"), len(changes))
+	for _, change := range changes {
+		fmt.Println("  " + change)
+	}
+}
+
+// 显示将要保存的修改并请求确认，确认后保存，返回是否已保存
+// 设置中关闭了确认时直接保存
+func saveClashConfigWithConfirm(config *ClashConfig) (bool, error) {
+	settings, err := loadManagerSettings()
+	if err == nil && !settings.confirmChanges() {
+		return true, saveClashConfig(config)
+	}
+
+	// 当前配置无法读取时没有可比较的内容，直接保存
+	current, err := readClashConfig()
+	if err != nil {
+		return true, saveClashConfig(config)
+	}
+
+	changes := diffClashConfigs(current, config)
+	if len(changes) == 0 {
+		fmt.Println(T("\n配置没有变化，无需保存"))
+		return false, nil
+	}
+
+	body := []string{fmt.Sprintf(T("即将对配置做如下修改，共 %d 处变化:"), len(changes)), ""}
+	for _, change := range changes {
+		body = append(body, "  "+change)
+	}
+	if !confirmDialog(T("确认修改"), T("确认保存以上修改？"), body, true) {
+		fmt.Println(T("已取消，配置未修改"))
+		return false, nil
+	}
+
+	return true, saveClashConfig(config)
+}
+
+// 比较两份配置文件并打印差异
+// 参数可以是文件路径、快照序号或时间戳，live 表示当前配置文件
+func showConfigDiff(args []string) error {
+	unified := false
+	var sources []string
+	for _, arg := range args {
+		switch arg {
+		case "-u", "--unified":
+			unified = true
+		default:
+			sources = append(sources, arg)
+		}
+	}
+
+	if len(sources) == 0 || len(sources) > 2 {
+		return errors.New(T("用法: config diff [-u] <a> [b]，b 默认为当前配置 (live)"))
+	}
+	if len(sources) == 1 {
+		sources = append(sources, "live")
+	}
+
+	nameA, contentA, err := loadConfigSource(sources[0])
+	if err != nil {
+		return err
+	}
+	nameB, contentB, err := loadConfigSource(sources[1])
+	if err != nil {
+		return err
+	}
+
+	if unified {
+		diff := unifiedDiff(nameA, nameB, splitLines(string(contentA)), splitLines(string(contentB)))
+		if diff == "" {
+			fmt.Println(T("两份配置内容相同"))
+			return nil
+		}
+		fmt.Print(diff)
+		return nil
+	}
+
+	configA, err := parseClashConfig(contentA)
+	if err != nil {
+		return fmt.Errorf(T("解析 %s 失败: %w"), nameA, err)
+	}
+	configB, err := parseClashConfig(contentB)
+	if err != nil {
+		return fmt.Errorf(T("解析 %s 失败: %w"), nameB, err)
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", nameA, nameB)
+	printConfigChanges(diffClashConfigs(configA, configB))
+	return nil
+}
+
+// 读取差异比较的一方：live、快照序号/时间戳或文件路径
+func loadConfigSource(source string) (string, []byte, error) {
+	if source == "live" || source == "current" {
+		content, err := os.ReadFile(clashConfigPath())
+		return clashConfigPath(), content, err
+	}
+
+	// 存在的文件优先按路径处理
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		content, err := os.ReadFile(source)
+		return source, content, err
+	}
+
+	backups, err := listConfigBackups()
+	if err != nil {
+		return "", nil, err
+	}
+	backup, err := findConfigBackup(backups, source)
+	if err != nil {
+		return "", nil, fmt.Errorf(T("%s 既不是文件也不是有效的快照: %w"), source, err)
+	}
+	content, err := os.ReadFile(backup.Path)
+	return backup.Path, content, err
+}
+
+// 逐行差异中的一行，op 为 ' '、'+' 或 '-'
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// 基于最长公共子序列的逐行差异
+func diffLines(a, b []string) []diffLine {
+	// 去掉相同的首尾部分，减少需要比较的行数
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var result []diffLine
+	for _, line := range a[:prefix] {
+		result = append(result, diffLine{' ', line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	n, m := len(midA), len(midB)
+
+	if n*m > 4000000 {
+		// 差异过大时不再逐行对齐，整体视为替换
+		for _, line := range midA {
+			result = append(result, diffLine{'-', line})
+		}
+		for _, line := range midB {
+			result = append(result, diffLine{'+', line})
+		}
+	} else {
+		lcs := make([][]int32, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int32, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && midA[i] == midB[j]:
+				result = append(result, diffLine{' ', midA[i]})
+				i++
+				j++
+			case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+				result = append(result, diffLine{'+', midB[j]})
+				j++
+			default:
+				result = append(result, diffLine{'-', midA[i]})
+				i++
+			}
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, diffLine{' ', line})
+	}
+	return result
+}
+
+// 生成统一格式 (unified) 的文本差异，内容相同时返回空字符串
+func unifiedDiff(nameA, nameB string, a, b []string) string {
+	lines := diffLines(a, b)
+
+	var sb strings.Builder
+	// 每行在两份文件中的行号
+	posA, posB := make([]int, len(lines)), make([]int, len(lines))
+	lineA, lineB := 1, 1
+	for k, line := range lines {
+		posA[k], posB[k] = lineA, lineB
+		if line.op != '+' {
+			lineA++
+		}
+		if line.op != '-' {
+			lineB++
+		}
+	}
+
+	for k := 0; k < len(lines); {
+		if lines[k].op == ' ' {
+			k++
+			continue
+		}
+
+		// 找到修改块的范围，相距较近的修改合并为一块
+		start := k - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*diffContextLines {
+				end = next
+				continue
+			}
+			end += diffContextLines
+			if end > len(lines) {
+				end = len(lines)
+			}
+			break
+		}
+
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+
+		countA, countB := 0, 0
+		for _, line := range lines[start:end] {
+			if line.op != '+' {
+				countA++
+			}
+			if line.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", posA[start], countA, posB[start], countB)
+		for _, line := range lines[start:end] {
+			text := line.text
+			if !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+			sb.WriteByte(line.op)
+			sb.WriteString(text)
+		}
+
+		k = end
+	}
+
+	return sb.String()
+}
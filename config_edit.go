@@ -0,0 +1,495 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 新增顶层字段和无法检测缩进时使用的缩进宽度
+const defaultConfigIndent = 2
+
+// 在原配置文件内容的基础上写入修改后的配置
+// 只重写发生变化的顶层字段，其余部分（注释、字段顺序、flow 格式）逐字节保留
+func renderClashConfig(original []byte, config *ClashConfig) ([]byte, error) {
+	var origDoc yaml.Node
+	if err := yaml.Unmarshal(original, &origDoc); err != nil || len(origDoc.Content) == 0 ||
+		origDoc.Content[0].Kind != yaml.MappingNode {
+		// 原文件无法解析时直接整体输出
+		return yaml.Marshal(config)
+	}
+	origRoot := origDoc.Content[0]
+
+	// 原文件按类型化模型规范化后的结果，用于判断字段是否真的发生了变化
+	oldConfig, err := parseClashConfig(original)
+	if err != nil {
+		return yaml.Marshal(config)
+	}
+	oldRoot, err := encodeYAMLNode(oldConfig)
+	if err != nil {
+		return nil, err
+	}
+	newRoot, err := encodeYAMLNode(config)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := splitLines(string(original))
+	spans := topLevelSpans(origRoot, lines)
+
+	// 每个原有顶层字段对应的新文本，nil 表示保持不变
+	replacements := make(map[string][]string)
+	// 插入在某个原有字段之后的新字段，空字符串表示插入到文件开头
+	insertions := make(map[string][]string)
+	seen := make(map[string]bool)
+
+	prevKey := ""
+	for i := 0; i+1 < len(newRoot.Content); i += 2 {
+		key := newRoot.Content[i].Value
+		value := newRoot.Content[i+1]
+		seen[key] = true
+
+		span, exists := spans[key]
+		if !exists {
+			text, err := encodeTopLevelField(&yaml.Node{Kind: yaml.ScalarNode, Value: key}, value, defaultConfigIndent)
+			if err != nil {
+				return nil, err
+			}
+			insertions[prevKey] = append(insertions[prevKey], text...)
+			continue
+		}
+		prevKey = key
+
+		oldValue := mappingValue(oldRoot, key)
+		if oldValue != nil && yamlNodesEqual(oldValue, value) {
+			continue
+		}
+
+		// proxies、rules 等块序列逐项替换，未变化的元素保持原样
+		if isBlockSequence(span.value) && oldValue != nil && value.Kind == yaml.SequenceNode {
+			text, err := spliceSequence(span, oldValue, value, lines)
+			if err != nil {
+				return nil, err
+			}
+			replacements[key] = text
+			continue
+		}
+
+		merged := mergeYAMLNode(span.value, oldValue, value)
+
+		// 单行标量只替换值本身，保持行尾注释的对齐
+		if text, ok := replaceInlineScalar(span, merged, lines); ok {
+			replacements[key] = text
+			continue
+		}
+
+		keyNode := *span.key
+		keyNode.HeadComment = ""
+		keyNode.FootComment = ""
+		text, err := encodeTopLevelField(&keyNode, merged, span.indent)
+		if err != nil {
+			return nil, err
+		}
+		replacements[key] = text
+	}
+
+	// 按原文件顺序拼接输出
+	var out []string
+	out = appendLines(out, insertions[""])
+	line := 0
+	for i := 0; i+1 < len(origRoot.Content); i += 2 {
+		key := origRoot.Content[i].Value
+		span := spans[key]
+		if span == nil || span.start < line {
+			continue
+		}
+
+		out = appendLines(out, lines[line:span.start])
+		switch {
+		case !seen[key]:
+			// 字段已被删除
+		case replacements[key] != nil:
+			out = appendLines(out, replacements[key])
+		default:
+			out = appendLines(out, lines[span.start:span.end])
+		}
+		line = span.end
+
+		out = appendLines(out, insertions[key])
+	}
+	out = appendLines(out, lines[line:])
+
+	return []byte(strings.Join(out, "")), nil
+}
+
+// 追加文本行，保证前一行以换行符结尾（原文件末行可能没有换行符）
+func appendLines(out, lines []string) []string {
+	if len(lines) == 0 {
+		return out
+	}
+	if n := len(out); n > 0 && !strings.HasSuffix(out[n-1], "\n") {
+		out[n-1] += "\n"
+	}
+	return append(out, lines...)
+}
+
+// 在原行中原地替换单行标量的值
+func replaceInlineScalar(span *configSpan, merged *yaml.Node, lines []string) ([]string, bool) {
+	orig := span.value
+	if span.end != span.start+1 || orig.Kind != yaml.ScalarNode || merged.Kind != yaml.ScalarNode ||
+		orig.Line != span.key.Line {
+		return nil, false
+	}
+
+	line := lines[span.start]
+	from := orig.Column - 1
+	oldText := orig.Value
+	if orig.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		// 带引号的值需要原文中的长度，这里只处理不含引号的简单情况
+		if strings.ContainsAny(oldText, `'"\`) {
+			return nil, false
+		}
+		oldText = line[from:from+1] + oldText + line[from:from+1]
+	}
+	if from+len(oldText) > len(line) || line[from:from+len(oldText)] != oldText {
+		return nil, false
+	}
+
+	newValue := *merged
+	newValue.HeadComment, newValue.LineComment, newValue.FootComment = "", "", ""
+	encoded, err := yaml.Marshal(&newValue)
+	if err != nil {
+		return nil, false
+	}
+	newText := strings.TrimSuffix(string(encoded), "\n")
+	if strings.Contains(newText, "\n") {
+		return nil, false
+	}
+
+	rest := line[from+len(oldText):]
+	// 新值较短时补空格，较长时吃掉多余的空格，使行尾注释保持原来的列
+	if diff := len(newText) - len(oldText); diff < 0 && strings.HasPrefix(strings.TrimLeft(rest, " "), "#") {
+		rest = strings.Repeat(" ", -diff) + rest
+	} else if diff > 0 {
+		spaces := len(rest) - len(strings.TrimLeft(rest, " "))
+		if trim := spaces - 1; trim > 0 && strings.HasPrefix(strings.TrimLeft(rest, " "), "#") {
+			if trim > diff {
+				trim = diff
+			}
+			rest = rest[trim:]
+		}
+	}
+
+	return []string{line[:from] + newText + rest}, true
+}
+
+// 是否为至少包含一个元素的块序列
+func isBlockSequence(node *yaml.Node) bool {
+	return node.Kind == yaml.SequenceNode && node.Style&yaml.FlowStyle == 0 && len(node.Content) > 0
+}
+
+// 逐项重写块序列字段，未变化的元素及其前面的注释保持原样
+func spliceSequence(span *configSpan, old, updated *yaml.Node, lines []string) ([]string, error) {
+	orig := span.value
+
+	// 每个原有元素占据的行范围，元素末尾的空行和注释归入下一个元素
+	starts := make([]int, len(orig.Content))
+	ends := make([]int, len(orig.Content))
+	for j, item := range orig.Content {
+		starts[j] = item.Line - 1
+	}
+	for j := range orig.Content {
+		end := span.end
+		if j+1 < len(orig.Content) {
+			end = starts[j+1]
+		}
+		for end > starts[j]+1 && isCommentOrBlankLine(lines[end-1]) {
+			end--
+		}
+		ends[j] = end
+	}
+
+	out := append([]string{}, lines[span.start:starts[0]]...)
+	for _, item := range updated.Content {
+		j := findSequenceItem(old, item)
+		if j < 0 || j >= len(orig.Content) {
+			// 新增的元素沿用已有元素的格式
+			styleLike(item, orig.Content[0])
+			text, err := encodeSequenceItem(item, span.indent)
+			if err != nil {
+				return nil, err
+			}
+			out = appendLines(out, text)
+			continue
+		}
+
+		// 元素前的注释
+		if j > 0 {
+			out = appendLines(out, lines[ends[j-1]:starts[j]])
+		}
+
+		if yamlNodesEqual(old.Content[j], item) {
+			out = appendLines(out, lines[starts[j]:ends[j]])
+			continue
+		}
+
+		merged := mergeYAMLNode(orig.Content[j], old.Content[j], item)
+		merged.HeadComment = ""
+		text, err := encodeSequenceItem(merged, span.indent)
+		if err != nil {
+			return nil, err
+		}
+		out = appendLines(out, text)
+	}
+	out = appendLines(out, lines[ends[len(ends)-1]:span.end])
+
+	return out, nil
+}
+
+// 注释行或空行
+func isCommentOrBlankLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// 将单个序列元素编码为文本行，缩进与顶层字段下的序列一致
+func encodeSequenceItem(item *yaml.Node, indent int) ([]string, error) {
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{item}}
+	text, err := encodeTopLevelField(&yaml.Node{Kind: yaml.ScalarNode, Value: "item"}, seq, indent)
+	if err != nil {
+		return nil, err
+	}
+	// 去掉占位的字段名
+	return text[1:], nil
+}
+
+// 顶层字段在原文件中占据的行范围 [start, end)
+type configSpan struct {
+	key    *yaml.Node
+	value  *yaml.Node
+	start  int
+	end    int
+	indent int
+}
+
+// 计算每个顶层字段占据的行范围
+// 字段之间的空行和顶格注释不属于任何字段，重写字段时原样保留
+func topLevelSpans(root *yaml.Node, lines []string) map[string]*configSpan {
+	spans := make(map[string]*configSpan)
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		start := key.Line - 1
+
+		end := len(lines)
+		if i+2 < len(root.Content) {
+			end = root.Content[i+2].Line - 1
+		}
+		for end > start+1 && isDetachedLine(lines[end-1]) {
+			end--
+		}
+
+		indent := defaultConfigIndent
+		for _, l := range lines[start+1 : end] {
+			trimmed := strings.TrimLeft(l, " ")
+			if strings.TrimSpace(l) == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if n := len(l) - len(trimmed) - key.Column + 1; n >= 2 {
+				indent = n
+			}
+			break
+		}
+
+		spans[key.Value] = &configSpan{
+			key:    key,
+			value:  root.Content[i+1],
+			start:  start,
+			end:    end,
+			indent: indent,
+		}
+	}
+
+	return spans
+}
+
+// 空行或顶格注释
+func isDetachedLine(line string) bool {
+	return strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#")
+}
+
+// 将单个顶层字段编码为文本行
+func encodeTopLevelField(key, value *yaml.Node, indent int) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(indent)
+
+	doc := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{key, value}}
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return splitLines(buf.String()), nil
+}
+
+// 按行切分文本，每行保留换行符
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// 将值编码为节点树，返回其中的映射节点
+func encodeYAMLNode(v interface{}) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := doc.Encode(v); err != nil {
+		return nil, err
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0], nil
+	}
+	return &doc, nil
+}
+
+// 两个节点编码后的内容是否一致
+func yamlNodesEqual(a, b *yaml.Node) bool {
+	aa, errA := yaml.Marshal(a)
+	bb, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aa, bb)
+}
+
+// 在映射节点中查找字段的值
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// 将修改后的节点合并到原节点上
+// orig 为原文件中的节点，old 为其规范化后的结果，updated 为修改后的结果
+// 未变化的子树直接沿用原节点，以保留注释和格式
+func mergeYAMLNode(orig, old, updated *yaml.Node) *yaml.Node {
+	if orig == nil || old == nil {
+		return updated
+	}
+	if yamlNodesEqual(old, updated) {
+		return orig
+	}
+	if orig.Kind != updated.Kind || old.Kind != updated.Kind {
+		return updated
+	}
+
+	switch updated.Kind {
+	case yaml.MappingNode:
+		merged := *orig
+		merged.Content = nil
+
+		// 保持原有字段顺序，新增字段追加在末尾
+		done := make(map[string]bool)
+		for i := 0; i+1 < len(orig.Content); i += 2 {
+			key := orig.Content[i].Value
+			value := mappingValue(updated, key)
+			if value == nil {
+				continue
+			}
+			done[key] = true
+			merged.Content = append(merged.Content, orig.Content[i],
+				mergeYAMLNode(orig.Content[i+1], mappingValue(old, key), value))
+		}
+		for i := 0; i+1 < len(updated.Content); i += 2 {
+			if !done[updated.Content[i].Value] {
+				merged.Content = append(merged.Content, updated.Content[i], updated.Content[i+1])
+			}
+		}
+		return &merged
+
+	case yaml.SequenceNode:
+		merged := *orig
+		merged.Content = nil
+
+		for _, item := range updated.Content {
+			j := findSequenceItem(old, item)
+			if j >= 0 && j < len(orig.Content) {
+				merged.Content = append(merged.Content, mergeYAMLNode(orig.Content[j], old.Content[j], item))
+				continue
+			}
+
+			// 新增的元素沿用已有元素的格式
+			if len(orig.Content) > 0 {
+				styleLike(item, orig.Content[0])
+			}
+			merged.Content = append(merged.Content, item)
+		}
+		return &merged
+
+	case yaml.ScalarNode:
+		merged := *updated
+		merged.HeadComment = orig.HeadComment
+		merged.LineComment = orig.LineComment
+		merged.FootComment = orig.FootComment
+		if orig.Tag == updated.Tag {
+			merged.Style = orig.Style
+		}
+		return &merged
+	}
+
+	return updated
+}
+
+// 在序列中查找与 item 对应的元素，映射按 name 字段匹配，标量按值匹配
+func findSequenceItem(seq, item *yaml.Node) int {
+	for i, candidate := range seq.Content {
+		if candidate.Kind != item.Kind {
+			continue
+		}
+		switch item.Kind {
+		case yaml.MappingNode:
+			name := mappingValue(item, "name")
+			if name != nil && mappingValue(candidate, "name") != nil &&
+				mappingValue(candidate, "name").Value == name.Value {
+				return i
+			}
+		case yaml.ScalarNode:
+			if candidate.Value == item.Value {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// 让新节点沿用参考节点的格式（flow 风格、字符串引号）
+func styleLike(node, ref *yaml.Node) {
+	if node.Kind != ref.Kind {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		node.Style = ref.Style &^ (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			refValue := mappingValue(ref, node.Content[i].Value)
+			if refValue != nil && refValue.Kind == yaml.ScalarNode && node.Content[i+1].Kind == yaml.ScalarNode {
+				styleLike(node.Content[i+1], refValue)
+			}
+		}
+	case yaml.SequenceNode:
+		node.Style = ref.Style
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" && ref.ShortTag() == "!!str" {
+			node.Style = ref.Style & (yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const renderTestConfig = `# 顶部注释
+port: 7890 # HTTP 代理端口
+mode: rule
+
+proxies:
+  - { name: 'a', type: ss, server: 1.1.1.1, port: 8388, cipher: aes-256-gcm, password: p }
+  # b 是备用节点
+  - { name: 'b', type: ss, server: 2.2.2.2, port: 8388, cipher: aes-256-gcm, password: p }
+
+rules:
+  # 局域网直连
+  - IP-CIDR,192.168.0.0/16,DIRECT
+  - MATCH,DIRECT
+`
+
+func TestRenderClashConfig(t *testing.T) {
+	// 期望结果以原文件为基础，只替换发生变化的行
+	replace := func(old, new string) string {
+		return strings.Replace(renderTestConfig, old, new, 1)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(config *ClashConfig)
+		want   string
+	}{
+		{
+			name:   "没有修改时原样输出",
+			modify: func(config *ClashConfig) {},
+			want:   renderTestConfig,
+		},
+		{
+			name:   "修改标量时保留行尾注释",
+			modify: func(config *ClashConfig) { config.Port = PortValue{Number: 7891} },
+			want:   replace("port: 7890 #", "port: 7891 #"),
+		},
+		{
+			name:   "只重写修改过的节点",
+			modify: func(config *ClashConfig) { config.Proxies[1].Server = "3.3.3.3" },
+			want: replace(
+				"  - { name: 'b', type: ss, server: 2.2.2.2, port: 8388, cipher: aes-256-gcm, password: p }",
+				"  - {name: 'b', type: ss, server: 3.3.3.3, port: 8388, cipher: aes-256-gcm, password: p}",
+			),
+		},
+		{
+			name: "新节点沿用 flow 格式追加到末尾",
+			modify: func(config *ClashConfig) {
+				proxy := Proxy{Name: "c", Type: "ss", Server: "1.1.1.1", Port: PortValue{Number: 8388}}
+				proxy.Set("cipher", "aes-256-gcm")
+				proxy.Set("password", "q")
+				config.Proxies = append(config.Proxies, proxy)
+			},
+			want: replace(
+				"password: p }\n\nrules:",
+				"password: p }\n  - {name: 'c', type: ss, server: 1.1.1.1, port: 8388, cipher: aes-256-gcm, password: q}\n\nrules:",
+			),
+		},
+		{
+			name:   "删除规则时保留其他规则和注释",
+			modify: func(config *ClashConfig) { config.Rules = config.Rules[1:] },
+			want:   replace("  - IP-CIDR,192.168.0.0/16,DIRECT\n", ""),
+		},
+		{
+			name: "插入规则时保留原有规则",
+			modify: func(config *ClashConfig) {
+				config.Rules = append([]string{"DOMAIN,x.com,DIRECT"}, config.Rules...)
+			},
+			want: replace("  - IP-CIDR", "  - DOMAIN,x.com,DIRECT\n  - IP-CIDR"),
+		},
+		{
+			name:   "新增的顶层字段按字段顺序插入",
+			modify: func(config *ClashConfig) { config.Secret = "s" },
+			want:   replace("mode: rule\n", "mode: rule\nsecret: s\n"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseClashConfig([]byte(renderTestConfig))
+			if err != nil {
+				t.Fatalf("parseClashConfig: %v", err)
+			}
+			tt.modify(config)
+
+			got, err := renderClashConfig([]byte(renderTestConfig), config)
+			if err != nil {
+				t.Fatalf("renderClashConfig: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("输出不符合预期\n得到:\n%s\n期望:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderClashConfigUnparsableOriginal(t *testing.T) {
+	config := &ClashConfig{Mode: "rule"}
+	got, err := renderClashConfig([]byte("not: [valid"), config)
+	if err != nil {
+		t.Fatalf("renderClashConfig: %v", err)
+	}
+	parsed, err := parseClashConfig(got)
+	if err != nil {
+		t.Fatalf("输出无法解析: %v\n%s", err, got)
+	}
+	if parsed.Mode != "rule" {
+		t.Errorf("mode = %q, 期望 rule", parsed.Mode)
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 配置的一层，依次合并得到最终配置
+type configLayer struct {
+	Name string
+	Node *yaml.Node
+}
+
+// 覆盖文件所在目录，其中的 *.yaml 按文件名顺序合并
+func clashOverlayDir() string {
+	return clashWorkPath("conf.d")
+}
+
+// 合并各层配置生成最终配置内容
+// 合并顺序: 配置模板 -> conf.d/*.yaml -> 生成的节点
+// 合并规则:
+//   - 映射逐层递归合并，后面的层覆盖前面的同名字段，值为 null 时删除该字段
+//   - proxies 和 proxy-groups 按 name 合并，同名条目递归合并，新条目追加在末尾
+//   - rules 整体替换；prepend-rules 插入到规则开头，append-rules 插入到 MATCH 规则之前
+//   - 其他列表整体替换
+func renderLayeredConfig(nodes *ClashConfig) ([]byte, error) {
+	base, err := loadBaseLayer(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []configLayer{base}
+
+	overlays, err := loadOverlayLayers()
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, overlays...)
+
+	if nodes != nil {
+		layer, err := generatedNodesLayer(nodes)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	merged, err := mergeConfigLayers(layers)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(defaultConfigIndent)
+	if err := encoder.Encode(merged); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 渲染配置模板作为基础层
+func loadBaseLayer(nodes *ClashConfig) (configLayer, error) {
+	name, content, err := renderConfigTemplate(newConfigTemplateData(nodes))
+	if err != nil {
+		return configLayer{}, err
+	}
+	return parseConfigLayer(name, content)
+}
+
+// 读取 conf.d 目录中的覆盖文件，目录不存在时返回空
+func loadOverlayLayers() ([]configLayer, error) {
+	paths, err := filepath.Glob(filepath.Join(clashOverlayDir(), "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var layers []configLayer
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf(T("读取 %s 失败: %w"), path, err)
+		}
+		layer, err := parseConfigLayer(path, content)
+		if err != nil {
+			return nil, err
+		}
+		// 空文件不参与合并
+		if layer.Node != nil {
+			layers = append(layers, layer)
+		}
+	}
+	return layers, nil
+}
+
+// 将生成的节点、代理组和 proxy-providers 作为一层
+func generatedNodesLayer(nodes *ClashConfig) (configLayer, error) {
+	layerConfig := &ClashConfig{
+		Proxies:        nodes.Proxies,
+		ProxyGroups:    nodes.ProxyGroups,
+		ProxyProviders: nodes.ProxyProviders,
+	}
+	node, err := encodeYAMLNode(layerConfig)
+	if err != nil {
+		return configLayer{}, err
+	}
+	return configLayer{Name: T("生成的节点"), Node: node}, nil
+}
+
+// 解析一层配置，顶层必须是映射
+func parseConfigLayer(name string, content []byte) (configLayer, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return configLayer{}, fmt.Errorf(T("解析 %s 失败: %w"), name, err)
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return configLayer{Name: name}, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return configLayer{}, fmt.Errorf(T("%s 的顶层必须是映射"), name)
+	}
+	return configLayer{Name: name, Node: root}, nil
+}
+
+// 依次合并各层，返回合并后的顶层映射
+func mergeConfigLayers(layers []configLayer) (*yaml.Node, error) {
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, layer := range layers {
+		if layer.Node == nil {
+			continue
+		}
+		if err := mergeTopLevel(merged, layer.Node); err != nil {
+			return nil, fmt.Errorf(T("合并 %s 失败: %w"), layer.Name, err)
+		}
+	}
+	return merged, nil
+}
+
+// 合并顶层映射，处理 rules 的插入和按名称合并的列表
+func mergeTopLevel(dst, src *yaml.Node) error {
+	var prepend, appendRules *yaml.Node
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		switch key.Value {
+		case "prepend-rules":
+			prepend = value
+			continue
+		case "append-rules":
+			appendRules = value
+			continue
+		case "proxies", "proxy-groups":
+			existing := mappingValue(dst, key.Value)
+			if existing != nil && existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode {
+				if err := mergeNamedSequence(existing, value, key.Value); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		mergeMappingEntry(dst, key, value, true)
+	}
+
+	if prepend != nil || appendRules != nil {
+		if err := insertRules(dst, prepend, appendRules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 合并映射中的一个字段，topLevel 时新字段放在 rules 之前，保持规则位于文件末尾
+func mergeMappingEntry(dst, key, value *yaml.Node, topLevel bool) {
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		if dst.Content[i].Value != key.Value {
+			continue
+		}
+		switch {
+		case isNullNode(value):
+			dst.Content = append(dst.Content[:i], dst.Content[i+2:]...)
+		case dst.Content[i+1].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			mergeMapping(dst.Content[i+1], value)
+		default:
+			dst.Content[i+1] = value
+		}
+		return
+	}
+
+	if isNullNode(value) {
+		return
+	}
+
+	if topLevel {
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if dst.Content[i].Value == "rules" {
+				entry := []*yaml.Node{key, value}
+				dst.Content = append(dst.Content[:i], append(entry, dst.Content[i:]...)...)
+				return
+			}
+		}
+	}
+	dst.Content = append(dst.Content, key, value)
+}
+
+// 递归合并两个映射
+func mergeMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		mergeMappingEntry(dst, src.Content[i], src.Content[i+1], false)
+	}
+}
+
+// 按 name 合并列表，同名条目递归合并，新条目追加到末尾
+func mergeNamedSequence(dst, src *yaml.Node, field string) error {
+	// 模板中的空列表写作 []，合并后使用新内容的格式
+	if len(dst.Content) == 0 {
+		dst.Style = src.Style
+	}
+
+	for _, item := range src.Content {
+		name := mappingValue(item, "name")
+		if name == nil || name.Value == "" {
+			return fmt.Errorf(T("%s 中的条目缺少 name"), field)
+		}
+
+		if existing := findNamedItem(dst, name.Value); existing != nil {
+			mergeMapping(existing, item)
+		} else {
+			dst.Content = append(dst.Content, item)
+		}
+	}
+	return nil
+}
+
+// 在列表中按 name 查找映射条目
+func findNamedItem(seq *yaml.Node, name string) *yaml.Node {
+	for _, item := range seq.Content {
+		if value := mappingValue(item, "name"); value != nil && value.Value == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// 在规则开头插入 prepend，在末尾的 MATCH 规则之前插入 appendRules
+func insertRules(dst, prepend, appendRules *yaml.Node) error {
+	for _, node := range []*yaml.Node{prepend, appendRules} {
+		if node != nil && node.Kind != yaml.SequenceNode {
+			return errors.New(T("prepend-rules 和 append-rules 必须是列表"))
+		}
+	}
+
+	rules := mappingValue(dst, "rules")
+	if rules == nil {
+		rules = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		mergeMappingEntry(dst, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "rules"}, rules, true)
+	}
+
+	if prepend != nil {
+		rules.Content = append(append([]*yaml.Node{}, prepend.Content...), rules.Content...)
+	}
+	if appendRules != nil {
+		// MATCH 之后的规则不会生效，追加的规则放在它前面
+		at := len(rules.Content)
+		for at > 0 && isMatchRule(rules.Content[at-1].Value) {
+			at--
+		}
+		tail := append([]*yaml.Node{}, rules.Content[at:]...)
+		rules.Content = append(append(rules.Content[:at], appendRules.Content...), tail...)
+	}
+	return nil
+}
+
+// 是否为兜底的 MATCH 规则
+func isMatchRule(rule string) bool {
+	ruleType := strings.ToUpper(strings.TrimSpace(strings.SplitN(rule, ",", 2)[0]))
+	return ruleType == "MATCH" || ruleType == "FINAL"
+}
+
+// 是否为 null 值
+func isNullNode(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null"
+}
+
+// 打印由各层合并得到的配置，节点部分使用当前配置中的节点
+func showRenderedConfig() error {
+	var nodes *ClashConfig
+	if config, err := readClashConfig(); err == nil {
+		nodes = config
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf(T("读取当前配置失败: %w"), err)
+	}
+
+	content, err := renderLayeredConfig(nodes)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(content))
+
+	// 校验问题输出到标准错误，不影响重定向得到的配置内容
+	config, err := parseClashConfig(content)
+	if err == nil {
+		err = validateConfig(config)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, T("\n注意: 合并结果未通过校验: %v\n"), err)
+	}
+	return nil
+}
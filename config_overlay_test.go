@@ -0,0 +1,189 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// 按顺序合并多层 YAML 内容，返回解析后的配置
+func mergeTestLayers(t *testing.T, contents ...string) (*ClashConfig, error) {
+	t.Helper()
+	var layers []configLayer
+	for i, content := range contents {
+		layer, err := parseConfigLayer(string(rune('A'+i)), []byte(content))
+		if err != nil {
+			t.Fatalf("parseConfigLayer: %v", err)
+		}
+		layers = append(layers, layer)
+	}
+
+	merged, err := mergeConfigLayers(layers)
+	if err != nil {
+		return nil, err
+	}
+	node, err := encodeYAMLNode(merged)
+	if err != nil {
+		t.Fatalf("encodeYAMLNode: %v", err)
+	}
+	var config ClashConfig
+	if err := node.Decode(&config); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return &config, nil
+}
+
+func TestMergeConfigLayers(t *testing.T) {
+	base := `
+mode: rule
+dns:
+  enable: true
+  ipv6: false
+proxies:
+  - {name: a, type: ss, server: 1.1.1.1, port: 8388, cipher: aes-256-gcm, password: p}
+  - {name: b, type: ss, server: 2.2.2.2, port: 8388, cipher: aes-256-gcm, password: p}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [a, b]}
+rules:
+  - DOMAIN,base.com,DIRECT
+  - MATCH,Proxy
+`
+
+	t.Run("prepend-rules 在开头，append-rules 在 MATCH 之前", func(t *testing.T) {
+		config, err := mergeTestLayers(t, base, `
+prepend-rules: ["DOMAIN,first.com,DIRECT"]
+append-rules: ["DOMAIN,last.com,DIRECT"]
+`, `
+prepend-rules: ["DOMAIN,second.com,DIRECT"]
+append-rules: ["DOMAIN,later.com,DIRECT"]
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"DOMAIN,second.com,DIRECT",
+			"DOMAIN,first.com,DIRECT",
+			"DOMAIN,base.com,DIRECT",
+			"DOMAIN,last.com,DIRECT",
+			"DOMAIN,later.com,DIRECT",
+			"MATCH,Proxy",
+		}
+		if !reflect.DeepEqual(config.Rules, want) {
+			t.Errorf("rules = %q\n期望 %q", config.Rules, want)
+		}
+	})
+
+	t.Run("rules 整体替换", func(t *testing.T) {
+		config, err := mergeTestLayers(t, base, `rules: ["MATCH,DIRECT"]`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"MATCH,DIRECT"}; !reflect.DeepEqual(config.Rules, want) {
+			t.Errorf("rules = %q，期望 %q", config.Rules, want)
+		}
+	})
+
+	t.Run("节点和代理组按名称合并", func(t *testing.T) {
+		config, err := mergeTestLayers(t, base, `
+proxies:
+  - {name: c, type: http, server: 3.3.3.3, port: 80}
+  - {name: a, server: 9.9.9.9}
+proxy-groups:
+  - {name: Proxy, proxies: [c, a, b]}
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, proxy := range config.Proxies {
+			names = append(names, proxy.Name)
+		}
+		if want := []string{"a", "b", "c"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("节点顺序 = %q，期望 %q", names, want)
+		}
+		if a := config.Proxies[0]; a.Server != "9.9.9.9" || a.GetString("password") != "p" {
+			t.Errorf("a 合并后 server = %s, password = %s", a.Server, a.GetString("password"))
+		}
+		if len(config.ProxyGroups) != 1 {
+			t.Fatalf("代理组数量 = %d，期望 1", len(config.ProxyGroups))
+		}
+		group := config.ProxyGroups[0]
+		if group.Type != "select" || !reflect.DeepEqual(group.Proxies, []string{"c", "a", "b"}) {
+			t.Errorf("代理组合并后 type = %s, proxies = %q", group.Type, group.Proxies)
+		}
+	})
+
+	t.Run("映射递归合并，null 删除字段", func(t *testing.T) {
+		config, err := mergeTestLayers(t, base, `
+mode: global
+dns:
+  ipv6: null
+  listen: 0.0.0.0:53
+`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Mode != "global" {
+			t.Errorf("mode = %s，期望 global", config.Mode)
+		}
+		if config.DNS == nil || config.DNS.Enable == nil || !*config.DNS.Enable {
+			t.Errorf("dns.enable 应保留")
+		}
+		if config.DNS != nil && (config.DNS.IPv6 != nil || config.DNS.Listen != "0.0.0.0:53") {
+			t.Errorf("dns 合并结果 ipv6 = %v, listen = %s", config.DNS.IPv6, config.DNS.Listen)
+		}
+	})
+
+	t.Run("条目缺少 name 时报错", func(t *testing.T) {
+		if _, err := mergeTestLayers(t, base, "proxies: [{type: http}]"); err == nil {
+			t.Error("期望返回错误")
+		}
+	})
+}
+
+func TestRenderLayeredConfigOverlayOrder(t *testing.T) {
+	dir := useTempWorkDir(t)
+	overlayDir := filepath.Join(dir, "conf.d")
+	if err := os.MkdirAll(overlayDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 文件按名称顺序合并，与创建顺序无关
+	files := []struct{ name, content string }{
+		{"20-b.yaml", "mode: direct\nappend-rules: ['DOMAIN,b.com,DIRECT']\n"},
+		{"10-a.yaml", "mode: global\nappend-rules: ['DOMAIN,a.com,DIRECT']\n"},
+		{"empty.yaml", ""},
+		{"ignored.yml", "mode: rule\n"},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(overlayDir, f.name), []byte(f.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := renderLayeredConfig(nil)
+	if err != nil {
+		t.Fatalf("renderLayeredConfig: %v", err)
+	}
+	config, err := parseClashConfig(content)
+	if err != nil {
+		t.Fatalf("parseClashConfig: %v", err)
+	}
+
+	if config.Mode != "direct" {
+		t.Errorf("mode = %s，期望 direct", config.Mode)
+	}
+	n := len(config.Rules)
+	if n < 3 {
+		t.Fatalf("规则数量 = %d", n)
+	}
+	want := []string{"DOMAIN,a.com,DIRECT", "DOMAIN,b.com,DIRECT"}
+	if got := config.Rules[n-3 : n-1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("MATCH 前的规则 = %q，期望 %q", got, want)
+	}
+	if !isMatchRule(config.Rules[n-1]) {
+		t.Errorf("最后一条规则 = %s，期望 MATCH", config.Rules[n-1])
+	}
+}
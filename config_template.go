@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 没有生成代理组时，模板中使用的主代理组名称
+const defaultProxyGroupName = "PROXY"
+
+// 渲染配置模板时可以使用的数据
+type configTemplateData struct {
+	Port               int
+	SocksPort          int
+	AllowLan           bool
+	Mode               string
+	LogLevel           string
+	ExternalController string
+	Secret             string
+	// 规则默认指向的主代理组
+	GroupName string
+	DNS       configTemplateDNS
+	// 生成的节点和代理组
+	Proxies     []Proxy
+	ProxyGroups []ProxyGroup
+}
+
+// 模板中的 DNS 设置
+type configTemplateDNS struct {
+	DefaultNameserver []string
+	Nameserver        []string
+	Fallback          []string
+}
+
+// 模板数据的默认值
+func defaultConfigTemplateData() configTemplateData {
+	return configTemplateData{
+		Port:               7890,
+		SocksPort:          7891,
+		AllowLan:           true,
+		Mode:               "rule",
+		LogLevel:           "debug",
+		ExternalController: "127.0.0.1:9090",
+		GroupName:          defaultProxyGroupName,
+		DNS: configTemplateDNS{
+			DefaultNameserver: []string{"223.5.5.5", "119.29.29.29", "10.233.73.60"},
+			Nameserver:        []string{"https://doh.pub/dns-query", "https://dns.alidns.com/dns-query"},
+			Fallback: []string{"https://doh.dns.sb/dns-query", "https://dns.cloudflare.com/dns-query",
+				"https://dns.twnic.tw/dns-query", "tls://8.8.4.4:853"},
+		},
+	}
+}
+
+// 用生成的节点填充模板数据，第一个代理组作为主代理组
+func newConfigTemplateData(nodes *ClashConfig) configTemplateData {
+	data := defaultConfigTemplateData()
+	if nodes == nil {
+		return data
+	}
+
+	data.Proxies = nodes.Proxies
+	data.ProxyGroups = nodes.ProxyGroups
+	if len(nodes.ProxyGroups) > 0 && nodes.ProxyGroups[0].Name != "" {
+		data.GroupName = nodes.ProxyGroups[0].Name
+	}
+	return data
+}
+
+// 模板中可用的函数，所有字符串都经过 YAML 编码，名称或密码中的特殊字符不会破坏配置
+var configTemplateFuncs = template.FuncMap{
+	// 单引号包裹的 YAML 字符串
+	"quote": yamlQuote,
+	// 由多个部分组成的规则，例如 {{ rule "MATCH" .GroupName }}
+	"rule": func(parts ...string) string {
+		return yamlQuote(strings.Join(parts, ","))
+	},
+	// 块格式的 YAML，通常与 indent 一起使用
+	"toYaml": func(v interface{}) (string, error) {
+		return encodeTemplateYAML(v, false)
+	},
+	// 流格式的 YAML，例如 [a, b]
+	"flow": func(v interface{}) (string, error) {
+		return encodeTemplateYAML(v, true)
+	},
+	// 为每一行添加缩进
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+}
+
+// 将字符串编码为单引号包裹的 YAML 字符串
+func yamlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// 用 YAML 编码器编码模板中的值，去掉末尾的换行
+func encodeTemplateYAML(v interface{}, flow bool) (string, error) {
+	node, err := encodeYAMLNode(v)
+	if err != nil {
+		return "", err
+	}
+	if flow {
+		node.Style = yaml.FlowStyle
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(defaultConfigIndent)
+	if err := encoder.Encode(node); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// 读取配置模板，指定了 --template 时使用该文件，否则使用内置模板
+func readConfigTemplate() (string, string, error) {
+	if path := configTemplatePath(); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", err
+		}
+		return path, string(content), nil
+	}
+
+	// 从嵌入资源中读取模板
+	templateContent, err := readEmbeddedFile("resources/config-template.yaml")
+	if err != nil {
+		// 如果嵌入资源读取失败，尝试从文件系统读取作为备选
+		templateContent, err = os.ReadFile("resources/config-template.yaml")
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return T("内置模板"), string(templateContent), nil
+}
+
+// 读取并渲染配置模板
+func renderConfigTemplate(data configTemplateData) (string, []byte, error) {
+	name, content, err := readConfigTemplate()
+	if err != nil {
+		return "", nil, fmt.Errorf(T("读取配置模板失败: %w"), err)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(configTemplateFuncs).Parse(content)
+	if err != nil {
+		return "", nil, fmt.Errorf(T("解析配置模板 %s 失败: %w"), name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", nil, fmt.Errorf(T("渲染配置模板 %s 失败: %w"), name, err)
+	}
+	return name, buf.Bytes(), nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderConfigTemplateEscaping(t *testing.T) {
+	// 名称和密码中包含 YAML 的特殊字符
+	groupName := `🚀 节点: 选择 #1 'a' "b"`
+	proxy := Proxy{Name: `- [HK], {01}: & *x`, Type: "ss", Server: "1.1.1.1", Port: PortValue{Number: 8388}}
+	proxy.Set("cipher", "aes-256-gcm")
+	proxy.Set("password", `p'a"ss: #word`)
+
+	data := defaultConfigTemplateData()
+	data.Secret = `s: '#`
+	data.GroupName = groupName
+	data.Proxies = []Proxy{proxy}
+	data.ProxyGroups = []ProxyGroup{{Name: groupName, Type: "select", Proxies: []string{proxy.Name, "DIRECT"}}}
+
+	_, content, err := renderConfigTemplate(data)
+	if err != nil {
+		t.Fatalf("renderConfigTemplate: %v", err)
+	}
+	config, err := parseClashConfig(content)
+	if err != nil {
+		t.Fatalf("渲染结果无法解析: %v\n%s", err, content)
+	}
+
+	if config.Secret != data.Secret {
+		t.Errorf("secret = %q，期望 %q", config.Secret, data.Secret)
+	}
+	if len(config.Proxies) != 1 || config.Proxies[0].Name != proxy.Name {
+		t.Fatalf("proxies = %+v", config.Proxies)
+	}
+	if got := config.Proxies[0].GetString("password"); got != proxy.GetString("password") {
+		t.Errorf("password = %q，期望 %q", got, proxy.GetString("password"))
+	}
+	if len(config.ProxyGroups) != 1 || config.ProxyGroups[0].Name != groupName {
+		t.Fatalf("proxy-groups = %+v", config.ProxyGroups)
+	}
+	if want := []string{proxy.Name, "DIRECT"}; !reflect.DeepEqual(config.ProxyGroups[0].Proxies, want) {
+		t.Errorf("代理组成员 = %q，期望 %q", config.ProxyGroups[0].Proxies, want)
+	}
+	if last := config.Rules[len(config.Rules)-1]; last != "MATCH,"+groupName {
+		t.Errorf("最后一条规则 = %q，期望指向 %q", last, groupName)
+	}
+	if err := validateConfig(config); err != nil {
+		t.Errorf("渲染结果未通过校验: %v", err)
+	}
+}
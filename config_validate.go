@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Clash 内置的策略，可以直接出现在代理组和规则中
+var builtinPolicies = map[string]bool{
+	"DIRECT":      true,
+	"REJECT":      true,
+	"REJECT-DROP": true,
+	"PASS":        true,
+	"COMPATIBLE":  true,
+	"GLOBAL":      true,
+}
+
+// 各类型代理必须包含的字段，未列出的类型只检查名称
+var requiredProxyFields = map[string][]string{
+	"ss":          {"server", "port", "cipher", "password"},
+	"shadowsocks": {"server", "port", "cipher", "password"},
+	"ssr":         {"server", "port", "cipher", "password", "obfs", "protocol"},
+	"vmess":       {"server", "port", "uuid", "cipher"},
+	"vless":       {"server", "port", "uuid"},
+	"trojan":      {"server", "port", "password"},
+	"snell":       {"server", "port", "psk"},
+	"socks5":      {"server", "port"},
+	"http":        {"server", "port"},
+	"hysteria":    {"server", "port"},
+	"hysteria2":   {"server", "port", "password"},
+	"tuic":        {"server", "port"},
+	"wireguard":   {"server", "port", "private-key"},
+}
+
+// 支持的代理组类型
+var proxyGroupTypes = map[string]bool{
+	"select":       true,
+	"url-test":     true,
+	"fallback":     true,
+	"load-balance": true,
+	"relay":        true,
+}
+
+// 配置校验发现的问题
+type ConfigValidationError struct {
+	Problems []string
+}
+
+func (e *ConfigValidationError) Error() string {
+	return T("配置校验失败:\n  - ") + strings.Join(e.Problems, "\n  - ")
+}
+
+// 检查单个代理的类型、必要字段和端口
+func validateProxy(proxy *Proxy) []string {
+	if proxy.Type == "" {
+		return []string{fmt.Sprintf(T("代理 %s 缺少类型"), proxy.Name)}
+	}
+
+	var problems []string
+	for _, field := range requiredProxyFields[proxy.Type] {
+		if !proxy.Has(field) || proxy.GetString(field) == "" {
+			problems = append(problems, fmt.Sprintf(T("代理 %s (%s) 缺少必要字段 %s"), proxy.Name, proxy.Type, field))
+		}
+	}
+	if proxy.Port.Invalid() {
+		problems = append(problems, fmt.Sprintf(T("代理 %s 的端口无效: %q"), proxy.Name, proxy.Port.Raw))
+	} else if proxy.Has("port") && (proxy.Port.Number < 1 || proxy.Port.Number > 65535) {
+		problems = append(problems, fmt.Sprintf(T("代理 %s 的端口超出范围: %d"), proxy.Name, proxy.Port.Number))
+	}
+	return problems
+}
+
+// 校验配置，返回发现的所有问题，配置有效时返回 nil
+func validateConfig(config *ClashConfig) error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// 检查监听端口
+	for _, p := range []struct {
+		name string
+		port PortValue
+	}{
+		{"port", config.Port},
+		{"socks-port", config.SocksPort},
+		{"mixed-port", config.MixedPort},
+	} {
+		if p.port.Invalid() {
+			addProblem(T("%s 的端口无效: %q"), p.name, p.port.Raw)
+		} else if p.port.Number < 0 || p.port.Number > 65535 {
+			addProblem(T("%s 超出端口范围: %d"), p.name, p.port.Number)
+		}
+	}
+
+	// 检查代理节点
+	names := make(map[string]string)
+	for i, proxy := range config.Proxies {
+		if proxy.Name == "" {
+			addProblem(T("第 %d 个代理缺少名称"), i+1)
+			continue
+		}
+		if _, exists := names[proxy.Name]; exists {
+			addProblem(T("代理名称重复: %s"), proxy.Name)
+		}
+		names[proxy.Name] = "proxy"
+		problems = append(problems, validateProxy(&proxy)...)
+	}
+
+	// 检查代理组名称，组之间可以相互引用，需要先收集全部名称
+	for _, group := range config.ProxyGroups {
+		if group.Name == "" {
+			addProblem(T("存在缺少名称的代理组"))
+			continue
+		}
+		if kind, exists := names[group.Name]; exists {
+			if kind == "proxy" {
+				addProblem(T("代理组 %s 与代理节点重名"), group.Name)
+			} else {
+				addProblem(T("代理组名称重复: %s"), group.Name)
+			}
+		}
+		names[group.Name] = "group"
+	}
+
+	// 检查代理组成员
+	for _, group := range config.ProxyGroups {
+		if group.Name == "" {
+			continue
+		}
+		if !proxyGroupTypes[group.Type] {
+			addProblem(T("代理组 %s 的类型无效: %q"), group.Name, group.Type)
+		}
+		if len(group.Proxies) == 0 && len(group.Use) == 0 {
+			addProblem(T("代理组 %s 没有任何成员"), group.Name)
+		}
+		for _, member := range group.Proxies {
+			if member == group.Name {
+				addProblem(T("代理组 %s 引用了自身"), group.Name)
+			} else if _, exists := names[member]; !exists && !builtinPolicies[member] {
+				addProblem(T("代理组 %s 引用了不存在的节点或代理组: %s"), group.Name, member)
+			}
+		}
+		for _, provider := range group.Use {
+			if _, exists := config.ProxyProviders[provider]; !exists {
+				addProblem(T("代理组 %s 引用了不存在的 proxy-provider: %s"), group.Name, provider)
+			}
+		}
+		if group.Selected != "" && !containsString(group.Proxies, group.Selected) && len(group.Use) == 0 {
+			addProblem(T("代理组 %s 选中的节点 %s 不在组内"), group.Name, group.Selected)
+		}
+		// 直接列出成员的 url-test 等代理组必须设置健康检查参数，否则 Clash 拒绝加载
+		if isHealthCheckGroup(group.Type) && len(group.Proxies) > 0 && (group.URL == "" || group.Interval <= 0) {
+			addProblem(T("代理组 %s (%s) 缺少健康检查的 url 或 interval"), group.Name, group.Type)
+		}
+		if group.Type == "load-balance" && group.Strategy != "" && !loadBalanceStrategies[group.Strategy] {
+			addProblem(T("代理组 %s 的负载均衡策略无效: %q"), group.Name, group.Strategy)
+		}
+		if group.Type == "relay" {
+			for _, member := range group.Proxies {
+				if builtinPolicies[member] {
+					addProblem(T("relay 代理组 %s 不能包含内置策略 %s"), group.Name, member)
+				}
+			}
+		}
+	}
+	if cycle := findGroupCycle(config); len(cycle) > 0 {
+		addProblem(T("代理组之间存在循环引用: %s"), strings.Join(cycle, " -> "))
+	}
+
+	for _, name := range sortedKeys(config.RuleProviders) {
+		provider := config.RuleProviders[name]
+		switch provider.Type {
+		case "http":
+			if provider.URL == "" {
+				addProblem(T("rule-provider %s 缺少 url"), name)
+			}
+		case "file":
+			if provider.Path == "" {
+				addProblem(T("rule-provider %s 缺少 path"), name)
+			}
+		case "inline":
+		default:
+			addProblem(T("rule-provider %s 的类型无效: %q"), name, provider.Type)
+		}
+		if !ruleSetBehaviors[provider.Behavior] {
+			addProblem(T("rule-provider %s 的 behavior 无效: %q"), name, provider.Behavior)
+		}
+	}
+
+	// 检查规则的类型、内容和目标策略
+	for i, text := range config.Rules {
+		rule, err := parseRule(text)
+		if err != nil {
+			addProblem(T("第 %d 条规则 %q: %v"), i+1, text, err)
+			continue
+		}
+		if err := checkRuleSyntax(rule, config); err != nil {
+			addProblem(T("第 %d 条规则 %q: %v"), i+1, text, err)
+		}
+		if _, exists := names[rule.Target]; !exists && !builtinPolicies[rule.Target] {
+			addProblem(T("第 %d 条规则 %q 的目标 %s 不存在"), i+1, text, rule.Target)
+		}
+		if isMatchType(rule.Type) && i != len(config.Rules)-1 {
+			addProblem(T("第 %d 条规则 %q 之后的规则不会生效，MATCH 必须是最后一条规则"), i+1, text)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ConfigValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// 查找代理组之间的循环引用，返回组成环的代理组名称，首尾相同
+func findGroupCycle(config *ClashConfig) []string {
+	groups := make(map[string]*ProxyGroup)
+	for i := range config.ProxyGroups {
+		groups[config.ProxyGroups[i].Name] = &config.ProxyGroups[i]
+	}
+
+	// 0: 未访问，1: 正在访问，2: 已完成
+	state := make(map[string]int)
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = 1
+		path = append(path, name)
+		for _, member := range groups[name].Proxies {
+			if _, isGroup := groups[member]; !isGroup || member == name {
+				continue
+			}
+			switch state[member] {
+			case 1:
+				for i, n := range path {
+					if n == member {
+						return append(append([]string{}, path[i:]...), member)
+					}
+				}
+			case 0:
+				if cycle := visit(member); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = 2
+		return nil
+	}
+
+	for _, group := range config.ProxyGroups {
+		if state[group.Name] == 0 {
+			if cycle := visit(group.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// 解析规则的目标策略，例如 DOMAIN-SUFFIX,google.com,Proxy 返回 Proxy
+func ruleTarget(rule string) (string, error) {
+	parsed, err := parseRule(rule)
+	return parsed.Target, err
+}
+
+// 字符串切片中是否包含指定值
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		// 每个问题应包含的关键内容，按问题的顺序排列，为空表示配置有效
+		want []string
+	}{
+		{
+			name: "有效配置",
+			config: `
+port: 7890
+proxies:
+  - {name: a, type: ss, server: 1.1.1.1, port: 8388, cipher: aes-256-gcm, password: p}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [a, DIRECT]}
+rules:
+  - DOMAIN-SUFFIX,example.com,Proxy
+  - DOMAIN-REGEX,^ads\.,REJECT
+  - AND,((DOMAIN,a.com),(NETWORK,UDP)),REJECT
+  - MATCH,Proxy
+`,
+		},
+		{
+			name: "无法解析的端口",
+			config: `
+port: abc
+proxies:
+  - {name: a, type: ss, server: 1.1.1.1, port: x, cipher: aes-256-gcm, password: p}
+`,
+			want: []string{"abc", "x"},
+		},
+		{
+			name: "端口超出范围和缺少字段",
+			config: `
+mixed-port: 70000
+proxies:
+  - {name: a, type: ss, server: 1.1.1.1, port: 65536, cipher: aes-256-gcm}
+`,
+			want: []string{"mixed-port", "password", "65536"},
+		},
+		{
+			name: "重名的节点和代理组",
+			config: `
+proxies:
+  - {name: a, type: http, server: 1.1.1.1, port: 80}
+  - {name: a, type: http, server: 1.1.1.2, port: 80}
+proxy-groups:
+  - {name: a, type: select, proxies: [DIRECT]}
+`,
+			want: []string{"a", "a"},
+		},
+		{
+			name: "代理组成员",
+			config: `
+proxy-groups:
+  - {name: A, type: select, proxies: [missing]}
+  - {name: C, type: unknown, proxies: [DIRECT], selected: REJECT}
+`,
+			want: []string{"missing", "unknown", "REJECT"},
+		},
+		{
+			name: "规则的目标、内容和 MATCH 位置",
+			config: `
+rules:
+  - MATCH,DIRECT
+  - DOMAIN,example.com,Missing
+  - IP-CIDR,not-a-cidr,DIRECT
+`,
+			want: []string{"MATCH,DIRECT", "Missing", "not-a-cidr"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := parseClashConfig([]byte(tt.config))
+			if err != nil {
+				t.Fatalf("parseClashConfig: %v", err)
+			}
+
+			err = validateConfig(config)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("期望配置有效，得到: %v", err)
+				}
+				return
+			}
+
+			var validationErr *ConfigValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("期望 ConfigValidationError，得到: %v", err)
+			}
+			if len(validationErr.Problems) != len(tt.want) {
+				t.Fatalf("问题数量 = %d，期望 %d: %q", len(validationErr.Problems), len(tt.want), validationErr.Problems)
+			}
+			for i, problem := range validationErr.Problems {
+				if !strings.Contains(problem, tt.want[i]) {
+					t.Errorf("第 %d 个问题 %q 不包含 %q", i+1, problem, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProxySetInvalidPort(t *testing.T) {
+	proxy := Proxy{Name: "a", Type: "ss", Server: "1.1.1.1", Port: PortValue{Number: 8388}}
+	proxy.Set("port", "80a")
+	if proxy.Port != (PortValue{Raw: "80a"}) {
+		t.Fatalf("Set(\"port\", \"80a\") 后端口为 %+v，期望保留原始值", proxy.Port)
+	}
+	problems := validateProxy(&proxy)
+	if len(problems) == 0 || !strings.Contains(strings.Join(problems, "\n"), "80a") {
+		t.Errorf("validateProxy 没有报告无效的端口: %v", problems)
+	}
+
+	proxy.Set("port", 443)
+	if proxy.Port != (PortValue{Number: 443}) {
+		t.Errorf("Set(\"port\", 443) 后端口为 %+v", proxy.Port)
+	}
+}
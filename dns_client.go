@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DNS 记录类型
+const (
+	dnsTypeA    = 1
+	dnsTypeAAAA = 28
+)
+
+// 单次 DNS 查询的超时时间
+const dnsQueryTimeout = 5 * time.Second
+
+// 按配置中 dns 部分的行为解析域名：hosts、nameserver、fallback 和 fallback-filter
+type clashDNSResolver struct {
+	config *ClashConfig
+	dns    *DNSConfig
+	mmdb   *mmdbReader
+}
+
+// 创建解析器，mmdb 用于 fallback-filter 的 GEOIP 判断，可以为 nil
+func newClashDNSResolver(config *ClashConfig, mmdb *mmdbReader) *clashDNSResolver {
+	return &clashDNSResolver{config: config, dns: config.DNS, mmdb: mmdb}
+}
+
+// 解析域名，返回地址和结果来源的说明
+func (r *clashDNSResolver) resolve(host string) ([]netip.Addr, string, error) {
+	if addr, ok := r.lookupHosts(host); ok {
+		return []netip.Addr{addr}, "hosts", nil
+	}
+
+	// 未启用 DNS 时 Clash 使用系统解析
+	if r.dns == nil || r.dns.Enable == nil || !*r.dns.Enable || len(r.dns.Nameserver) == 0 {
+		addrs, err := r.systemResolve(host)
+		return addrs, T("系统 DNS"), err
+	}
+
+	// fallback-filter.domain 中的域名直接使用 fallback
+	if len(r.dns.Fallback) > 0 && r.fallbackDomain(host) {
+		addrs, server, err := r.queryServers(r.dns.Fallback, host)
+		return addrs, "fallback " + server, err
+	}
+
+	addrs, server, err := r.queryServers(r.dns.Nameserver, host)
+	if len(r.dns.Fallback) == 0 {
+		return addrs, "nameserver " + server, err
+	}
+
+	// nameserver 失败或结果被 fallback-filter 判定为污染时使用 fallback
+	if err == nil && len(addrs) > 0 && !r.shouldUseFallback(addrs[0]) {
+		return addrs, "nameserver " + server, nil
+	}
+	fallbackAddrs, fallbackServer, fallbackErr := r.queryServers(r.dns.Fallback, host)
+	if fallbackErr != nil {
+		if err == nil {
+			return addrs, "nameserver " + server, nil
+		}
+		return nil, "", fmt.Errorf(T("nameserver 和 fallback 均解析失败: %w; %w"), err, fallbackErr)
+	}
+	return fallbackAddrs, "fallback " + fallbackServer, nil
+}
+
+// 是否查询 IPv6 地址
+func (r *clashDNSResolver) ipv6() bool {
+	return r.dns != nil && r.dns.IPv6 != nil && *r.dns.IPv6
+}
+
+// 在配置的 hosts 中查找，支持 *.example.com 形式的通配
+func (r *clashDNSResolver) lookupHosts(host string) (netip.Addr, bool) {
+	if r.dns != nil && r.dns.UseHosts != nil && !*r.dns.UseHosts {
+		return netip.Addr{}, false
+	}
+	hosts, ok := r.config.Extra["hosts"].(map[string]interface{})
+	if !ok {
+		return netip.Addr{}, false
+	}
+
+	candidates := []string{host}
+	for rest := host; strings.Contains(rest, "."); {
+		rest = rest[strings.Index(rest, ".")+1:]
+		candidates = append(candidates, "*."+rest)
+	}
+	for _, name := range candidates {
+		if value, ok := hosts[name].(string); ok {
+			if addr, err := netip.ParseAddr(value); err == nil {
+				return addr, true
+			}
+		}
+	}
+	return netip.Addr{}, false
+}
+
+// 使用系统解析器
+func (r *clashDNSResolver) systemResolve(host string) ([]netip.Addr, error) {
+	network := "ip4"
+	if r.ipv6() {
+		network = "ip"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dnsQueryTimeout)
+	defer cancel()
+	return net.DefaultResolver.LookupNetIP(ctx, network, host)
+}
+
+// 域名是否在 fallback-filter.domain 中
+func (r *clashDNSResolver) fallbackDomain(host string) bool {
+	domains, _ := r.dns.FallbackFilter["domain"].([]interface{})
+	for _, d := range domains {
+		pattern, _ := d.(string)
+		if pattern != "" && matchDomainPattern(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// 根据 fallback-filter 判断 nameserver 返回的地址是否不可信
+func (r *clashDNSResolver) shouldUseFallback(addr netip.Addr) bool {
+	filter := r.dns.FallbackFilter
+
+	geoip := true
+	if v, ok := filter["geoip"].(bool); ok {
+		geoip = v
+	}
+	if geoip && r.mmdb != nil {
+		code := "CN"
+		if v, ok := filter["geoip-code"].(string); ok && v != "" {
+			code = v
+		}
+		if country, err := r.mmdb.country(addr); err == nil && !strings.EqualFold(country, code) {
+			return true
+		}
+	}
+
+	cidrs, _ := filter["ipcidr"].([]interface{})
+	for _, c := range cidrs {
+		value, _ := c.(string)
+		if prefix, err := netip.ParsePrefix(value); err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// 依次查询服务器，返回第一个成功的结果
+func (r *clashDNSResolver) queryServers(servers []string, host string) ([]netip.Addr, string, error) {
+	var lastErr error
+	for _, server := range servers {
+		addrs, err := r.query(server, host)
+		if err == nil {
+			return addrs, server, nil
+		}
+		lastErr = fmt.Errorf("%s: %w", server, err)
+	}
+	if lastErr == nil {
+		lastErr = errors.New(T("没有可用的 DNS 服务器"))
+	}
+	return nil, "", lastErr
+}
+
+// 向单个服务器查询 A 记录，启用 IPv6 时同时查询 AAAA 记录
+func (r *clashDNSResolver) query(server, host string) ([]netip.Addr, error) {
+	types := []uint16{dnsTypeA}
+	if r.ipv6() {
+		types = append(types, dnsTypeAAAA)
+	}
+
+	var addrs []netip.Addr
+	var lastErr error
+	for _, qtype := range types {
+		result, err := r.exchange(server, host, qtype)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		addrs = append(addrs, result...)
+	}
+	if len(addrs) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf(T("没有 %s 的解析记录"), host)
+		}
+		return nil, lastErr
+	}
+	return addrs, nil
+}
+
+// 按服务器地址的协议发送查询：udp (默认)、tcp、tls、https，dhcp/system 使用系统解析
+func (r *clashDNSResolver) exchange(server, host string, qtype uint16) ([]netip.Addr, error) {
+	// Clash Meta 允许在地址后用 # 指定出站代理，本地查询时忽略
+	server, _, _ = strings.Cut(server, "#")
+
+	scheme := "udp"
+	address := server
+	if i := strings.Index(server, "://"); i >= 0 {
+		scheme, address = server[:i], server[i+3:]
+	}
+
+	switch scheme {
+	case "dhcp", "system":
+		return r.systemResolve(host)
+	case "https":
+		response, err := r.exchangeHTTPS(server, host, qtype)
+		if err != nil {
+			return nil, err
+		}
+		return parseDNSResponse(response, 0)
+	}
+
+	defaultPort := "53"
+	if scheme == "tls" {
+		defaultPort = "853"
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(strings.Trim(address, "[]"), defaultPort)
+	}
+
+	query, id := buildDNSQuery(host, qtype)
+	var response []byte
+	var err error
+	switch scheme {
+	case "udp":
+		response, err = exchangeUDP(address, query)
+	case "tcp":
+		response, err = exchangeStream(r.dial, "tcp", address, query, nil)
+	case "tls":
+		serverName, _, _ := net.SplitHostPort(address)
+		response, err = exchangeStream(r.dial, "tcp", address, query, &tls.Config{ServerName: serverName})
+	default:
+		return nil, fmt.Errorf(T("不支持的 DNS 协议: %s"), scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseDNSResponse(response, id)
+}
+
+// 连接 DoT/DoH 服务器，服务器地址为域名时使用 default-nameserver 解析
+func (r *clashDNSResolver) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := netip.ParseAddr(host); err != nil && r.dns != nil && len(r.dns.DefaultNameserver) > 0 {
+		addrs, _, err := r.queryServers(r.dns.DefaultNameserver, host)
+		if err != nil {
+			return nil, fmt.Errorf(T("通过 default-nameserver 解析 %s 失败: %w"), host, err)
+		}
+		address = net.JoinHostPort(addrs[0].String(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: dnsQueryTimeout}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// 通过 UDP 发送查询
+func exchangeUDP(address string, query []byte) ([]byte, error) {
+	conn, err := net.DialTimeout("udp", address, dnsQueryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dnsQueryTimeout))
+
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// 通过 TCP 或 TLS 发送查询，消息前带两字节长度
+func exchangeStream(dial func(context.Context, string, string) (net.Conn, error), network, address string, query []byte, tlsConfig *tls.Config) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsQueryTimeout)
+	defer cancel()
+
+	conn, err := dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dnsQueryTimeout))
+
+	msg := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(msg, uint16(len(query)))
+	copy(msg[2:], query)
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	response := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// 通过 DNS over HTTPS (RFC 8484) 发送查询
+func (r *clashDNSResolver) exchangeHTTPS(server, host string, qtype uint16) ([]byte, error) {
+	if _, err := url.Parse(server); err != nil {
+		return nil, err
+	}
+
+	// DoH 查询的 ID 应为 0，便于缓存
+	query, _ := buildDNSQuery(host, qtype)
+	binary.BigEndian.PutUint16(query, 0)
+
+	client := &http.Client{
+		Timeout:   dnsQueryTimeout,
+		Transport: &http.Transport{DialContext: r.dial, ForceAttemptHTTP2: true},
+	}
+	req, err := http.NewRequest("POST", server, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(T("状态码: %d"), resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 65535))
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// 构造只包含一个问题的标准查询，返回消息和 ID
+func buildDNSQuery(host string, qtype uint16) ([]byte, uint16) {
+	id := uint16(rand.Intn(65536))
+
+	msg := make([]byte, 12, 512)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[2:], 0x0100) // 期望递归
+	binary.BigEndian.PutUint16(msg[4:], 1)      // 问题数
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = binary.BigEndian.AppendUint16(msg, qtype)
+	msg = binary.BigEndian.AppendUint16(msg, 1) // IN
+	return msg, id
+}
+
+// 解析响应中的 A 和 AAAA 记录，id 为 0 时不检查
+func parseDNSResponse(msg []byte, id uint16) ([]netip.Addr, error) {
+	if len(msg) < 12 {
+		return nil, errors.New(T("DNS 响应过短"))
+	}
+	if id != 0 && binary.BigEndian.Uint16(msg[0:]) != id {
+		return nil, errors.New(T("DNS 响应 ID 不匹配"))
+	}
+	if rcode := msg[3] & 0x0F; rcode != 0 {
+		return nil, fmt.Errorf(T("DNS 服务器返回错误码 %d"), rcode)
+	}
+
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	answers := int(binary.BigEndian.Uint16(msg[6:]))
+	offset := 12
+
+	for i := 0; i < questions; i++ {
+		next, err := skipDNSName(msg, offset)
+		if err != nil {
+			return nil, err
+		}
+		offset = next + 4
+	}
+
+	var addrs []netip.Addr
+	for i := 0; i < answers; i++ {
+		next, err := skipDNSName(msg, offset)
+		if err != nil {
+			return nil, err
+		}
+		if next+10 > len(msg) {
+			return nil, errors.New(T("DNS 响应被截断"))
+		}
+		rtype := binary.BigEndian.Uint16(msg[next:])
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		data := next + 10
+		if data+length > len(msg) {
+			return nil, errors.New(T("DNS 响应被截断"))
+		}
+
+		switch {
+		case rtype == dnsTypeA && length == 4:
+			addrs = append(addrs, netip.AddrFrom4([4]byte(msg[data:data+4])))
+		case rtype == dnsTypeAAAA && length == 16:
+			addrs = append(addrs, netip.AddrFrom16([16]byte(msg[data:data+16])))
+		}
+		offset = data + length
+	}
+
+	if len(addrs) == 0 {
+		return nil, errors.New(T("没有解析记录"))
+	}
+	return addrs, nil
+}
+
+// 跳过消息中的域名，支持压缩指针，返回之后的偏移量
+func skipDNSName(msg []byte, offset int) (int, error) {
+	for {
+		if offset >= len(msg) {
+			return 0, errors.New(T("DNS 响应被截断"))
+		}
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			return offset + 1, nil
+		case length&0xC0 == 0xC0:
+			return offset + 2, nil
+		default:
+			offset += 1 + length
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 界面语言
+const (
+	langZH = "zh-CN"
+	langEN = "en"
+)
+
+// 当前的界面语言，由 initLanguage 设置
+var activeLang = langZH
+
+// 各语言的消息目录，键为中文原文；中文是源语言，不需要目录
+var messageCatalogs = map[string]map[string]string{
+	langEN: messagesEN,
+}
+
+// 将 --lang 或 locale 环境变量的值转换为支持的语言，例如 en_US.UTF-8 -> en
+// C、POSIX 等没有指定语言的 locale 返回 false
+func normalizeLanguage(value string) (string, bool) {
+	value = strings.ToLower(value)
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	switch {
+	case value == "zh" || strings.HasPrefix(value, "zh_") || strings.HasPrefix(value, "zh-"):
+		return langZH, true
+	case value == "en" || strings.HasPrefix(value, "en_") || strings.HasPrefix(value, "en-"):
+		return langEN, true
+	}
+	return "", false
+}
+
+// 选择界面语言: --lang > LC_ALL > LC_MESSAGES > LANG，都未指定语言时使用中文
+func initLanguage(flagValue string) error {
+	if flagValue != "" {
+		lang, ok := normalizeLanguage(flagValue)
+		if !ok {
+			return fmt.Errorf(T("不支持的语言: %s，可选 zh-CN、en"), flagValue)
+		}
+		activeLang = lang
+		return nil
+	}
+
+	activeLang = langZH
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		// 按 POSIX 的优先级，第一个设置了的变量决定语言
+		if lang, ok := normalizeLanguage(value); ok {
+			activeLang = lang
+		}
+		break
+	}
+	return nil
+}
+
+// 翻译消息，当前语言的目录中没有时返回原文
+// 只用于输出给用户的文字，写入配置的节点名、代理组名等数据不经过翻译，避免随 LANG 变化
+func T(msg string) string {
+	if catalog := messageCatalogs[activeLang]; catalog != nil {
+		if translated, ok := catalog[msg]; ok {
+			return translated
+		}
+	}
+	return msg
+}
+
+// 翻译一组消息，用于在选择语言之前初始化的包级别菜单等
+func translateAll(msgs []string) []string {
+	translated := make([]string, len(msgs))
+	for i, msg := range msgs {
+		translated[i] = T(msg)
+	}
+	return translated
+}
+
+// 可以用 errors.Is 判断的错误，显示的文字随界面语言变化
+type localizedError struct {
+	msg string
+}
+
+func (e *localizedError) Error() string {
+	return T(e.msg)
+}
+
+// 常见的错误类别，通过 errorOf 或 withKind 附加到具体的错误上，调用方不依赖错误文字即可判断
+var (
+	ErrConfigRead           = &localizedError{"读取配置文件失败"}
+	ErrConfigSave           = &localizedError{"保存配置失败"}
+	ErrProxyNotFound        = &localizedError{"节点不存在"}
+	ErrGroupNotFound        = &localizedError{"代理组不存在"}
+	ErrProfileNotFound      = &localizedError{"profile 不存在"}
+	ErrRuleProviderNotFound = &localizedError{"rule-provider 不存在"}
+	ErrNameConflict         = &localizedError{"名称已被使用"}
+	ErrReferencedByRules    = &localizedError{"仍被规则引用"}
+	ErrReferencedByChain    = &localizedError{"仍被代理链引用"}
+	ErrProxyNotDisabled     = &localizedError{"节点未停用"}
+	ErrClashAPIUnreachable  = &localizedError{"无法连接 Clash 控制接口"}
+	ErrClashAPIUnauthorized = &localizedError{"Clash 控制接口拒绝访问，请检查 secret"}
+)
+
+// 带类别的错误，显示的文字不变，errors.Is 可以匹配类别和原始错误
+type kindError struct {
+	kind error
+	err  error
+	msg  string
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() []error {
+	if e.err == nil {
+		return []error{e.kind}
+	}
+	return []error{e.kind, e.err}
+}
+
+// 创建带类别的错误，format 应已经过 T 翻译
+func errorOf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
+
+// 给已有的错误附加类别，err 为 nil 时返回 nil
+func withKind(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err, msg: err.Error()}
+}
@@ -1,13 +1,11 @@
 package main
 
 import (
-	"bufio"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
@@ -16,7 +14,7 @@ import (
 	"strings"
 	// "time"
 	"gopkg.in/yaml.v3"
-	// "strconv"
+	"strconv"
 )
 
 type ProxyConfig struct {
@@ -32,10 +30,10 @@ type ProxyConfig struct {
 }
 
 type ProxyGroupConfig struct {
-	Name           string
-	Type           string
-	ProxyNames     []string
-	SelectedProxy  string
+	Name          string
+	Type          string
+	ProxyNames    []string
+	SelectedProxy string
 }
 
 const (
@@ -43,30 +41,56 @@ const (
 )
 
 func main() {
+	// 先按环境变量选择语言，--lang 在解析全局参数时覆盖
+	initLanguage("")
+
 	// 创建命令行子命令
 	flag.Usage = func() {
-		fmt.Printf("用法: %s <命令> [参数]\n\n", os.Args[0])
-		fmt.Println("可用命令:")
-		fmt.Println("  install    安装并配置 Clash Premium")
-		fmt.Println("  start      启动 Clash 服务")
-		fmt.Println("  stop       停止 Clash 服务")
-		fmt.Println("  restart    重启 Clash 服务")
-		fmt.Println("  status     检查 Clash 服务状态")
-		fmt.Println("  update     更新 Clash Premium")
-		fmt.Println("  reset-config     重置配置文件")
-		fmt.Println("  proxy      节点配置管理")
-		fmt.Println("  version    显示版本信息")
-		fmt.Println("  help       显示帮助信息")
+		fmt.Printf(T("用法: %s [--config 配置文件] [--workdir 工作目录] [--template 模板] [--lang 语言] <命令> [参数]\n\n"), os.Args[0])
+		fmt.Println(T("全局参数:"))
+		fmt.Println(T("  --config   Clash 配置文件路径，也可通过 CLASH_CONFIG_PATH 环境变量指定"))
+		fmt.Println(T("  --workdir  工作目录(快照、订阅缓存、providers 等)，也可通过 CLASH_WORKDIR 环境变量指定"))
+		fmt.Println(T("  --template reset-config 和 config render 使用的配置模板 (Go text/template 格式)"))
+		fmt.Println(T("  --lang     界面语言 zh-CN 或 en，默认根据 LC_ALL、LC_MESSAGES、LANG 环境变量选择"))
+		fmt.Println()
+		fmt.Println(T("可用命令:"))
+		fmt.Println(T("  install    安装并配置 Clash Premium"))
+		fmt.Println(T("  start      启动 Clash 服务"))
+		fmt.Println(T("  stop       停止 Clash 服务"))
+		fmt.Println(T("  restart    重启 Clash 服务"))
+		fmt.Println(T("  status     检查 Clash 服务状态"))
+		fmt.Println(T("  update     更新 Clash Premium"))
+		fmt.Println(T("  reset-config     重置配置文件"))
+		fmt.Println(T("  proxy      节点配置管理 (edit <节点> --set key=value 修改节点，disable/enable <节点>... 停用或启用节点，disabled 列出停用的节点，bulk 批量操作)"))
+		fmt.Println(T("  providers  proxy-providers 管理 (list/refresh)"))
+		fmt.Println(T("  subscription  订阅管理 (list/refresh)"))
+		fmt.Println(T("  config     配置快照管理 (history/rollback/diff/render)"))
+		fmt.Println(T("  profile    多套配置管理 (list/create/clone/switch/delete)"))
+		fmt.Println(T("  group      代理组管理 (list/create/edit/delete/add-member/remove-member)"))
+		fmt.Println(T("  chain      代理链管理 (list/create/delete)"))
+		fmt.Println(T("  rules      分流规则管理 (list/add/remove/move/test)"))
+		fmt.Println(T("  ruleset    规则集管理 (list/add/remove/refresh/timer)"))
+		fmt.Println(T("  version    显示版本信息"))
+		fmt.Println(T("  help       显示帮助信息"))
+	}
+
+	// 解析全局参数，确定配置文件和工作目录
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(1)
 	}
 
 	// 检查是否提供了子命令
-	if len(os.Args) < 2 {
+	if len(args) < 1 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	// 解析子命令
-	switch os.Args[1] {
+	switch args[0] {
 	case "install":
 		installClash()
 	case "start":
@@ -82,13 +106,38 @@ func main() {
 	case "reset-config":
 		generateConfigClash()
 	case "proxy":
-		manageProxyNodes()
+		switch {
+		case len(args) > 1 && (args[1] == "disable" || args[1] == "enable" || args[1] == "disabled"):
+			manageDisabledProxies(args[1:])
+		case len(args) > 1 && args[1] == "edit":
+			manageProxyEdit(args[2:])
+		case len(args) > 1 && args[1] == "bulk":
+			manageBulkProxies(args[2:])
+		default:
+			manageProxyNodes()
+		}
+	case "providers":
+		manageProxyProviders(args[1:])
+	case "subscription":
+		manageSubscriptions(args[1:])
+	case "config":
+		manageConfig(args[1:])
+	case "profile":
+		manageProfiles(args[1:])
+	case "group":
+		manageProxyGroups(args[1:])
+	case "chain":
+		manageChains(args[1:])
+	case "rules":
+		manageRules(args[1:])
+	case "ruleset":
+		manageRuleSets(args[1:])
 	case "version":
 		showVersion()
 	case "help":
 		flag.Usage()
 	default:
-		fmt.Printf("未知命令: %s\n", os.Args[1])
+		fmt.Printf(T("未知命令: %s\n"), args[0])
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -96,25 +145,25 @@ func main() {
 
 // 显示版本信息
 func showVersion() {
-	fmt.Printf("Clash 管理工具 v%s\n", VERSION)
+	fmt.Printf(T("Clash 管理工具 v%s\n"), VERSION)
 }
 
 // 安装并配置 Clash Premium
 func installClash() {
-	fmt.Println("开始自动配置 Clash Premium...")
+	fmt.Println(T("开始自动配置 Clash Premium..."))
 
 	// 创建临时工作目录
 	workDir, err := createWorkDir()
 	if err != nil {
-		fmt.Printf("创建工作目录失败: %v\n", err)
+		fmt.Printf(T("创建工作目录失败: %v\n"), err)
 		os.Exit(1)
 	}
 	defer os.RemoveAll(workDir)
 
 	// 从内置资源解压 clash-premium-installer
-	fmt.Println("提取内置 clash-premium-installer...")
+	fmt.Println(T("提取内置 clash-premium-installer..."))
 	if err := extractEmbeddedResources(workDir); err != nil {
-		fmt.Printf("提取 clash-premium-installer 失败: %v\n", err)
+		fmt.Printf(T("提取 clash-premium-installer 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
@@ -122,88 +171,88 @@ func installClash() {
 	installerDir := filepath.Join(workDir, "clash-premium-installer")
 
 	// 修改 installer.sh 脚本
-	fmt.Println("修改 installer.sh 脚本...")
+	fmt.Println(T("修改 installer.sh 脚本..."))
 	installerPath := filepath.Join(installerDir, "installer.sh")
 	if err := modifyInstallerScript(installerPath); err != nil {
-		fmt.Printf("修改 installer.sh 失败: %v\n", err)
+		fmt.Printf(T("修改 installer.sh 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// 修改 setup-tun.sh 脚本
-	fmt.Println("修改 setup-tun.sh 脚本...")
+	fmt.Println(T("修改 setup-tun.sh 脚本..."))
 	tunScriptPath := filepath.Join(installerDir, "scripts", "setup-tun.sh")
 	if err := modifyTunScript(tunScriptPath); err != nil {
-		fmt.Printf("修改 setup-tun.sh 失败: %v\n", err)
+		fmt.Printf(T("修改 setup-tun.sh 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// 运行安装脚本
-	fmt.Println("安装 Clash Premium...")
+	fmt.Println(T("安装 Clash Premium..."))
 	if err := runInstaller(installerDir); err != nil {
-		fmt.Printf("安装 Clash Premium 失败: %v\n", err)
+		fmt.Printf(T("安装 Clash Premium 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// 安装内置的 Country.mmdb 文件
-	fmt.Println("安装 Country.mmdb 文件...")
+	fmt.Println(T("安装 Country.mmdb 文件..."))
 	if err := installCountryMMDBFromEmbedded(); err != nil {
-		fmt.Printf("安装 Country.mmdb 失败: %v\n", err)
+		fmt.Printf(T("安装 Country.mmdb 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// 生成配置文件
-	fmt.Println("生成 Clash 配置文件...")
+	fmt.Println(T("生成 Clash 配置文件..."))
 	if err := generateClashConfig(); err != nil {
-		fmt.Printf("生成配置文件失败: %v\n", err)
+		fmt.Printf(T("生成配置文件失败: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// 配置 systemd resolved
-	fmt.Println("配置 systemd resolved...")
+	fmt.Println(T("配置 systemd resolved..."))
 	if err := configureResolved(); err != nil {
-		fmt.Printf("配置 systemd resolved 失败: %v\n", err)
+		fmt.Printf(T("配置 systemd resolved 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Clash Premium 配置完成！")
+	fmt.Println(T("Clash Premium 配置完成！"))
 }
 
 // 启动 Clash 服务
 func startClash() {
-	fmt.Println("正在启动 Clash 服务...")
+	fmt.Println(T("正在启动 Clash 服务..."))
 	cmd := exec.Command("systemctl", "start", "clash")
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("启动 Clash 服务失败: %v\n", err)
+		fmt.Printf(T("启动 Clash 服务失败: %v\n"), err)
 		os.Exit(1)
 	}
-	fmt.Println("Clash 服务已启动")
+	fmt.Println(T("Clash 服务已启动"))
 }
 
 // 停止 Clash 服务
 func stopClash() {
-	fmt.Println("正在停止 Clash 服务...")
+	fmt.Println(T("正在停止 Clash 服务..."))
 	cmd := exec.Command("systemctl", "stop", "clash")
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("停止 Clash 服务失败: %v\n", err)
+		fmt.Printf(T("停止 Clash 服务失败: %v\n"), err)
 		os.Exit(1)
 	}
-	fmt.Println("Clash 服务已停止")
+	fmt.Println(T("Clash 服务已停止"))
 }
 
 // 重启 Clash 服务
 func restartClash() {
-	fmt.Println("正在重启 Clash 服务...")
+	fmt.Println(T("正在重启 Clash 服务..."))
 	cmd := exec.Command("systemctl", "restart", "clash")
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("重启 Clash 服务失败: %v\n", err)
+		fmt.Printf(T("重启 Clash 服务失败: %v\n"), err)
 		os.Exit(1)
 	}
-	fmt.Println("Clash 服务已重启")
+	fmt.Println(T("Clash 服务已重启"))
 }
 
 // 检查 Clash 服务状态
 func statusClash() {
-	fmt.Println("检查 Clash 服务状态...")
+	fmt.Println(T("检查 Clash 服务状态..."))
 	cmd := exec.Command("systemctl", "status", "clash")
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -212,20 +261,20 @@ func statusClash() {
 
 // 更新 Clash Premium
 func updateClash() {
-	fmt.Println("开始更新 Clash Premium...")
+	fmt.Println(T("开始更新 Clash Premium..."))
 
 	// 创建临时工作目录
 	workDir, err := createWorkDir()
 	if err != nil {
-		fmt.Printf("创建工作目录失败: %v\n", err)
+		fmt.Printf(T("创建工作目录失败: %v\n"), err)
 		os.Exit(1)
 	}
 	defer os.RemoveAll(workDir)
 
 	// 从内置资源解压 clash-premium-installer
-	fmt.Println("提取内置 clash-premium-installer...")
+	fmt.Println(T("提取内置 clash-premium-installer..."))
 	if err := extractEmbeddedResources(workDir); err != nil {
-		fmt.Printf("提取 clash-premium-installer 失败: %v\n", err)
+		fmt.Printf(T("提取 clash-premium-installer 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
@@ -235,32 +284,32 @@ func updateClash() {
 	// 修改 installer.sh 脚本
 	installerPath := filepath.Join(installerDir, "installer.sh")
 	if err := modifyInstallerScript(installerPath); err != nil {
-		fmt.Printf("修改 installer.sh 失败: %v\n", err)
+		fmt.Printf(T("修改 installer.sh 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
 	// 运行更新脚本
-	fmt.Println("更新 Clash Premium...")
+	fmt.Println(T("更新 Clash Premium..."))
 	cmd := exec.Command("./installer.sh", "update")
 	cmd.Dir = installerDir
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	if err := cmd.Run(); err != nil {
-		fmt.Printf("更新 Clash Premium 失败: %v\n", err)
+		fmt.Printf(T("更新 Clash Premium 失败: %v\n"), err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Clash Premium 更新完成！")
+	fmt.Println(T("Clash Premium 更新完成！"))
 }
 
 // 仅生成或修改配置文件
 func generateConfigClash() {
-	fmt.Println("生成 Clash 配置文件...")
+	fmt.Println(T("生成 Clash 配置文件..."))
 	if err := generateClashConfig(); err != nil {
-		fmt.Printf("生成配置文件失败: %v\n", err)
+		fmt.Printf(T("生成配置文件失败: %v\n"), err)
 		os.Exit(1)
 	}
-	fmt.Println("配置文件已生成，您可能需要重启 Clash 服务以应用新配置。")
+	fmt.Println(T("配置文件已生成，您可能需要重启 Clash 服务以应用新配置。"))
 }
 
 func createWorkDir() (string, error) {
@@ -294,7 +343,7 @@ func modifyTunScript(filePath string) error {
 
 	// 找到需要添加规则的位置并添加规则
 	contentStr := string(content)
-	
+
 	// 修改 local-dns-redirect 链
 	localDnsChain := `    chain local-dns-redirect {
         type nat hook output priority 0; policy accept;
@@ -308,7 +357,7 @@ func modifyTunScript(filePath string) error {
         udp dport 53 dnat $FORWARD_DNS_REDIRECT
         tcp dport 53 dnat $FORWARD_DNS_REDIRECT
     }`
-	
+
 	// 添加 forward-dns-redirect 链
 	forwardDnsChain := `    chain forward-dns-redirect {
         type nat hook prerouting priority 0; policy accept;
@@ -358,7 +407,7 @@ DNSStubListener=no
 	if err != nil {
 		return err
 	}
-	
+
 	// 重启 systemd-resolved 服务
 	cmd := exec.Command("systemctl", "restart", "systemd-resolved")
 	return cmd.Run()
@@ -366,8 +415,7 @@ DNSStubListener=no
 
 func generateClashConfig() error {
 	// 创建配置目录
-	configDir := "/srv/clash"
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(clashConfigPath()), 0755); err != nil {
 		return err
 	}
 
@@ -383,138 +431,134 @@ func generateClashConfig() error {
 		return err
 	}
 
-	// 读取模板配置
-	templateContent, err := readConfigTemplate()
+	// 将输入的节点和代理组作为节点层，与模板和 conf.d 中的覆盖文件合并
+	nodes, err := generatedNodesConfig(proxies, proxyGroup)
+	if err != nil {
+		return err
+	}
+	configContent, err := renderLayeredConfig(nodes)
 	if err != nil {
 		return err
 	}
 
-	// 生成代理配置字符串
-	proxiesStr := generateProxiesConfig(proxies)
-	
-	// 生成代理组配置字符串
-	proxyGroupStr := generateProxyGroupConfig(proxyGroup)
-	
-	// 将代理组名称替换到规则中
-	templateContent = strings.ReplaceAll(templateContent, "PROXY", proxyGroup.Name)
-	
-	// 插入代理和代理组配置
-	configContent := strings.Replace(templateContent, 
-		"# proxies 和 proxy-groups 将根据用户输入自动生成", 
-		"proxies:\n" + proxiesStr + "\nproxy-groups:\n" + proxyGroupStr, 1)
-	
-	// 写入配置文件
-	configPath := filepath.Join(configDir, "config.yaml")
-	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+	// 写入前校验合并结果
+	config, err := parseClashConfig(configContent)
+	if err != nil {
+		return fmt.Errorf(T("解析生成的配置失败: %w"), err)
+	}
+	if err := validateConfig(config); err != nil {
 		return err
 	}
 
-	// 重启 Clash 服务以应用新配置
-	cmd := exec.Command("systemctl", "restart", "clash")
-	return cmd.Run()
+	// 写入配置文件，旧配置会保存为快照
+	if err := writeClashConfig(configContent); err != nil {
+		return err
+	}
+
+	// 重启 Clash 服务以应用新配置，启动失败时自动恢复旧配置
+	return restartClashWithHealthCheck()
 }
 
 func collectProxyConfigs() ([]ProxyConfig, error) {
 	var proxies []ProxyConfig
-	var proxyCount int
-	
-	fmt.Println("\n节点配置方式:")
-	fmt.Println("1. 手动输入参数")
-	fmt.Println("2. 通过协议链接(URL)导入")
-	fmt.Println("3. 从现有配置文件导入")
-	
-	var configMethod int
-	fmt.Print("请选择配置方式 (1/2/3): ")
-	fmt.Scanln(&configMethod)
-	
-	if configMethod == 2 {
+
+	methods := []string{T("手动输入参数"), T("通过协议链接(URL)导入"), T("从现有配置文件导入")}
+	configMethod := selectMenu(T("节点配置方式"), nil, methods, T("取消")) + 1
+
+	switch configMethod {
+	case 0:
+		return nil, errors.New(T("已取消配置"))
+	case 2:
 		// 通过URL导入
 		return collectProxyConfigsFromURL()
-	} else if configMethod == 3 {
+	case 3:
 		// 从现有配置文件导入
 		return collectProxyConfigsFromFile()
 	}
-	
+
 	// 原有的手动配置方式
-	fmt.Print("请输入需要配置的代理数量: ")
-	fmt.Scanln(&proxyCount)
-	
-	reader := bufio.NewReader(os.Stdin)
-	
+	reader := stdinReader
+	fmt.Print(T("请输入需要配置的代理数量: "))
+	countInput, _ := reader.ReadString('\n')
+	proxyCount, err := strconv.Atoi(strings.TrimSpace(countInput))
+	if err != nil || proxyCount < 1 {
+		return nil, fmt.Errorf(T("无效的代理数量: %s"), strings.TrimSpace(countInput))
+	}
+
 	for i := 0; i < proxyCount; i++ {
-		fmt.Printf("\n== 配置代理 %d ==\n", i+1)
-		
+		fmt.Printf(T("\n== 配置代理 %d ==\n"), i+1)
+
 		var proxy ProxyConfig
-		
-		fmt.Print("代理名称: ")
+
+		fmt.Print(T("代理名称: "))
 		proxy.Name, _ = reader.ReadString('\n')
 		proxy.Name = strings.TrimSpace(proxy.Name)
-		
-		fmt.Print("代理类型 (vmess/ss/trojan/...): ")
+
+		fmt.Print(T("代理类型 (vmess/ss/trojan/...): "))
 		proxy.Type, _ = reader.ReadString('\n')
 		proxy.Type = strings.TrimSpace(proxy.Type)
-		
-		fmt.Print("服务器地址: ")
+
+		fmt.Print(T("服务器地址: "))
 		proxy.Server, _ = reader.ReadString('\n')
 		proxy.Server = strings.TrimSpace(proxy.Server)
-		
-		fmt.Print("端口: ")
+
+		fmt.Print(T("端口: "))
 		proxy.Port, _ = reader.ReadString('\n')
 		proxy.Port = strings.TrimSpace(proxy.Port)
-		
+
 		if proxy.Type == "vmess" {
 			fmt.Print("UUID: ")
 			proxy.UUID, _ = reader.ReadString('\n')
 			proxy.UUID = strings.TrimSpace(proxy.UUID)
-			
-			fmt.Print("AlterID (默认为0): ")
+
+			fmt.Print(T("AlterID (默认为0): "))
 			proxy.AlterId, _ = reader.ReadString('\n')
 			proxy.AlterId = strings.TrimSpace(proxy.AlterId)
 			if proxy.AlterId == "" {
 				proxy.AlterId = "0"
 			}
-			
-			fmt.Print("加密方式 (默认为auto): ")
+
+			fmt.Print(T("加密方式 (默认为auto): "))
 			proxy.Cipher, _ = reader.ReadString('\n')
 			proxy.Cipher = strings.TrimSpace(proxy.Cipher)
 			if proxy.Cipher == "" {
 				proxy.Cipher = "auto"
 			}
 		} else if proxy.Type == "ss" || proxy.Type == "shadowsocks" {
-			fmt.Print("密码: ")
+			fmt.Print(T("密码: "))
 			proxy.Password, _ = reader.ReadString('\n')
 			proxy.Password = strings.TrimSpace(proxy.Password)
-			
-			fmt.Print("加密方式: ")
+
+			fmt.Print(T("加密方式: "))
 			proxy.Cipher, _ = reader.ReadString('\n')
 			proxy.Cipher = strings.TrimSpace(proxy.Cipher)
 		} else if proxy.Type == "trojan" {
-			fmt.Print("密码: ")
+			fmt.Print(T("密码: "))
 			proxy.Password, _ = reader.ReadString('\n')
 			proxy.Password = strings.TrimSpace(proxy.Password)
 		}
-		
+
 		proxies = append(proxies, proxy)
 	}
-	
+
 	return proxies, nil
 }
 
 // 通过URL链接导入代理配置
 func collectProxyConfigsFromURL() ([]ProxyConfig, error) {
-	reader := bufio.NewReader(os.Stdin)
-	
-	fmt.Print("\n请输入代理URL: ")
+	reader := stdinReader
+
+	fmt.Print(T("\n请输入代理URL: "))
 	urlStr, _ := reader.ReadString('\n')
 	urlStr = strings.TrimSpace(urlStr)
-	
+
 	if urlStr == "" {
-		return nil, fmt.Errorf("URL不能为空")
+		return nil, errors.New(T("URL不能为空"))
 	}
-	
+
 	// 检查URL格式并解析
 	var proxies []ProxyConfig
-	
+
 	// 如果URL是单个代理链接
 	if strings.HasPrefix(urlStr, "vmess://") {
 		proxy, err := parseVMessURL(urlStr)
@@ -536,24 +580,18 @@ func collectProxyConfigsFromURL() ([]ProxyConfig, error) {
 		proxies = append(proxies, proxy)
 	} else if strings.HasPrefix(urlStr, "http://") || strings.HasPrefix(urlStr, "https://") {
 		// 如果是订阅链接，尝试下载并解析
-		resp, err := http.Get(urlStr)
+		body, err := fetchSubscriptionCached(urlStr, promptSubscriptionFetchOptions())
 		if err != nil {
-			return nil, fmt.Errorf("获取订阅内容失败: %v", err)
+			return nil, fmt.Errorf(T("获取订阅内容失败: %w"), err)
 		}
-		defer resp.Body.Close()
-		
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("读取订阅内容失败: %v", err)
-		}
-		
+
 		// 尝试Base64解码
 		decodedBody, err := base64.StdEncoding.DecodeString(string(body))
 		if err != nil {
 			// 如果不是Base64编码，使用原始内容
 			decodedBody = body
 		}
-		
+
 		// 按行分割并解析每一行
 		lines := strings.Split(string(decodedBody), "\n")
 		for _, line := range lines {
@@ -561,10 +599,10 @@ func collectProxyConfigsFromURL() ([]ProxyConfig, error) {
 			if line == "" {
 				continue
 			}
-			
+
 			var proxy ProxyConfig
 			var err error
-			
+
 			if strings.HasPrefix(line, "vmess://") {
 				proxy, err = parseVMessURL(line)
 			} else if strings.HasPrefix(line, "ss://") {
@@ -574,46 +612,46 @@ func collectProxyConfigsFromURL() ([]ProxyConfig, error) {
 			} else {
 				continue // 跳过不支持的协议
 			}
-			
+
 			if err != nil {
-				fmt.Printf("解析代理失败: %v, 跳过\n", err)
+				fmt.Printf(T("解析代理失败: %v, 跳过\n"), err)
 				continue
 			}
-			
+
 			proxies = append(proxies, proxy)
 		}
 	} else {
-		return nil, fmt.Errorf("不支持的URL格式")
+		return nil, errors.New(T("不支持的URL格式"))
 	}
-	
+
 	if len(proxies) == 0 {
-		return nil, fmt.Errorf("未找到有效的代理")
+		return nil, errors.New(T("未找到有效的代理"))
 	}
-	
+
 	return proxies, nil
 }
 
 // 从现有配置文件导入代理配置
 func collectProxyConfigsFromFile() ([]ProxyConfig, error) {
-	reader := bufio.NewReader(os.Stdin)
-	
-	fmt.Print("\n请输入配置文件路径: ")
+	reader := stdinReader
+
+	fmt.Print(T("\n请输入配置文件路径: "))
 	filePath, _ := reader.ReadString('\n')
 	filePath = strings.TrimSpace(filePath)
-	
+
 	if filePath == "" {
-		return nil, fmt.Errorf("文件路径不能为空")
+		return nil, errors.New(T("文件路径不能为空"))
 	}
-	
+
 	// 读取文件内容
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("读取文件失败: %v", err)
+		return nil, fmt.Errorf(T("读取文件失败: %w"), err)
 	}
-	
+
 	// 尝试按照不同格式解析
 	var proxies []ProxyConfig
-	
+
 	// 首先尝试按行分割，查找代理URL
 	lines := strings.Split(string(content), "\n")
 	for _, line := range lines {
@@ -621,10 +659,10 @@ func collectProxyConfigsFromFile() ([]ProxyConfig, error) {
 		if line == "" {
 			continue
 		}
-		
+
 		var proxy ProxyConfig
 		var err error
-		
+
 		if strings.HasPrefix(line, "vmess://") {
 			proxy, err = parseVMessURL(line)
 		} else if strings.HasPrefix(line, "ss://") {
@@ -634,112 +672,72 @@ func collectProxyConfigsFromFile() ([]ProxyConfig, error) {
 		} else {
 			continue
 		}
-		
+
 		if err != nil {
-			fmt.Printf("解析代理失败: %v, 跳过\n", err)
+			fmt.Printf(T("解析代理失败: %v, 跳过\n"), err)
 			continue
 		}
-		
+
 		proxies = append(proxies, proxy)
 	}
-	
+
 	// 如果没有找到代理URL，尝试解析为Clash配置
 	if len(proxies) == 0 {
-		var config map[string]interface{}
-		if err := yaml.Unmarshal(content, &config); err != nil {
-			return nil, fmt.Errorf("解析配置文件失败: %v", err)
+		config, err := parseClashConfig(content)
+		if err != nil {
+			return nil, fmt.Errorf(T("解析配置文件失败: %w"), err)
 		}
-		
-		proxiesInterface, ok := config["proxies"].([]interface{})
-		if !ok {
-			return nil, fmt.Errorf("未找到代理配置")
+
+		if len(config.Proxies) == 0 {
+			return nil, errors.New(T("未找到代理配置"))
 		}
-		
-		for _, proxyInterface := range proxiesInterface {
-			proxyMap, ok := proxyInterface.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			
-			var proxy ProxyConfig
-			
-			if name, ok := proxyMap["name"].(string); ok {
-				proxy.Name = name
-			} else {
-				continue
-			}
-			
-			if proxyType, ok := proxyMap["type"].(string); ok {
-				proxy.Type = proxyType
-			} else {
-				continue
-			}
-			
-			if server, ok := proxyMap["server"].(string); ok {
-				proxy.Server = server
-			} else {
+
+		for _, p := range config.Proxies {
+			if p.Type == "" || p.Server == "" || p.Port.Number == 0 {
 				continue
 			}
-			
-			if port, ok := proxyMap["port"].(float64); ok {
-				proxy.Port = fmt.Sprintf("%d", int(port))
-			} else if port, ok := proxyMap["port"].(int); ok {
-				proxy.Port = fmt.Sprintf("%d", port)
-			} else if port, ok := proxyMap["port"].(string); ok {
-				proxy.Port = port
-			} else {
-				continue
+
+			proxy := ProxyConfig{
+				Name:   p.Name,
+				Type:   p.Type,
+				Server: p.Server,
+				Port:   p.Port.String(),
 			}
-			
+
 			if proxy.Type == "vmess" {
-				if uuid, ok := proxyMap["uuid"].(string); ok {
-					proxy.UUID = uuid
-				}
-				
-				if alterId, ok := proxyMap["alterId"].(float64); ok {
-					proxy.AlterId = fmt.Sprintf("%d", int(alterId))
-				} else if alterId, ok := proxyMap["alterId"].(int); ok {
-					proxy.AlterId = fmt.Sprintf("%d", alterId)
-				} else if alterId, ok := proxyMap["alterId"].(string); ok {
-					proxy.AlterId = alterId
-				} else {
+				proxy.UUID = p.GetString("uuid")
+
+				proxy.AlterId = p.GetString("alterId")
+				if proxy.AlterId == "" {
 					proxy.AlterId = "0"
 				}
-				
-				if cipher, ok := proxyMap["cipher"].(string); ok {
-					proxy.Cipher = cipher
-				} else {
+
+				proxy.Cipher = p.GetString("cipher")
+				if proxy.Cipher == "" {
 					proxy.Cipher = "auto"
 				}
 			} else if proxy.Type == "ss" || proxy.Type == "shadowsocks" {
-				if password, ok := proxyMap["password"].(string); ok {
-					proxy.Password = password
-				}
-				
-				if cipher, ok := proxyMap["cipher"].(string); ok {
-					proxy.Cipher = cipher
-				}
+				proxy.Password = p.GetString("password")
+				proxy.Cipher = p.GetString("cipher")
 			} else if proxy.Type == "trojan" {
-				if password, ok := proxyMap["password"].(string); ok {
-					proxy.Password = password
-				}
+				proxy.Password = p.GetString("password")
 			}
-			
+
 			proxies = append(proxies, proxy)
 		}
 	}
-	
+
 	if len(proxies) == 0 {
-		return nil, fmt.Errorf("未找到有效的代理")
+		return nil, errors.New(T("未找到有效的代理"))
 	}
-	
+
 	return proxies, nil
 }
 
 // 解析代理URL
 func parseProxyURL(urlStr string) (ProxyConfig, error) {
 	var proxy ProxyConfig
-	
+
 	if strings.HasPrefix(urlStr, "ss://") {
 		// 解析Shadowsocks URL
 		return parseSSURL(urlStr)
@@ -750,18 +748,18 @@ func parseProxyURL(urlStr string) (ProxyConfig, error) {
 		// 解析Trojan URL
 		return parseTrojanURL(urlStr)
 	}
-	
-	return proxy, fmt.Errorf("不支持的URL格式")
+
+	return proxy, errors.New(T("不支持的URL格式"))
 }
 
 // 解析Shadowsocks URL
 func parseSSURL(urlStr string) (ProxyConfig, error) {
 	var proxy ProxyConfig
 	proxy.Type = "ss"
-	
+
 	// ss://BASE64(method:password)@server:port#tag
 	// 或 ss://BASE64(method:password@server:port)#tag
-	
+
 	// 提取Tag/Name部分
 	parts := strings.SplitN(urlStr, "#", 2)
 	if len(parts) == 2 {
@@ -769,78 +767,78 @@ func parseSSURL(urlStr string) (ProxyConfig, error) {
 	} else {
 		proxy.Name = "Shadowsocks节点"
 	}
-	
+
 	// 处理主体部分
 	mainPart := parts[0][5:] // 去掉 "ss://" 前缀
-	
+
 	// 检查是否有@符号来确定编码方式
 	if strings.Contains(mainPart, "@") {
 		// 格式为 BASE64(method:password)@server:port
 		beforeAt := strings.Split(mainPart, "@")[0]
 		afterAt := strings.Split(mainPart, "@")[1]
-		
+
 		// 解码method:password部分
 		decodedBytes, err := decodeBase64UrlSafe(beforeAt)
 		if err != nil {
-			return proxy, fmt.Errorf("解码失败: %v", err)
+			return proxy, fmt.Errorf(T("解码失败: %w"), err)
 		}
 		methodPass := string(decodedBytes)
-		
+
 		// 分割method和password
 		methodPassParts := strings.SplitN(methodPass, ":", 2)
 		if len(methodPassParts) != 2 {
-			return proxy, fmt.Errorf("无效的编码格式")
+			return proxy, errors.New(T("无效的编码格式"))
 		}
-		
+
 		proxy.Cipher = methodPassParts[0]
 		proxy.Password = methodPassParts[1]
-		
+
 		// 解析服务器和端口
 		serverPortParts := strings.Split(afterAt, ":")
 		if len(serverPortParts) != 2 {
-			return proxy, fmt.Errorf("无效的服务器:端口格式")
+			return proxy, errors.New(T("无效的服务器:端口格式"))
 		}
-		
+
 		proxy.Server = serverPortParts[0]
 		proxy.Port = serverPortParts[1]
 	} else {
 		// 整个部分都被BASE64编码
 		decodedBytes, err := decodeBase64UrlSafe(mainPart)
 		if err != nil {
-			return proxy, fmt.Errorf("解码失败: %v", err)
+			return proxy, fmt.Errorf(T("解码失败: %w"), err)
 		}
-		
+
 		decoded := string(decodedBytes)
 		if !strings.Contains(decoded, "@") {
-			return proxy, fmt.Errorf("解码后的内容无效")
+			return proxy, errors.New(T("解码后的内容无效"))
 		}
-		
+
 		beforeAt := strings.Split(decoded, "@")[0]
 		afterAt := strings.Split(decoded, "@")[1]
-		
+
 		// 分割method和password
 		methodPassParts := strings.SplitN(beforeAt, ":", 2)
 		if len(methodPassParts) != 2 {
-			return proxy, fmt.Errorf("无效的编码格式")
+			return proxy, errors.New(T("无效的编码格式"))
 		}
-		
+
 		proxy.Cipher = methodPassParts[0]
 		proxy.Password = methodPassParts[1]
-		
+
 		// 解析服务器和端口
 		serverPortParts := strings.Split(afterAt, ":")
 		if len(serverPortParts) != 2 {
-			return proxy, fmt.Errorf("无效的服务器:端口格式")
+			return proxy, errors.New(T("无效的服务器:端口格式"))
 		}
-		
+
 		proxy.Server = serverPortParts[0]
 		proxy.Port = serverPortParts[1]
 	}
-	
+
 	if proxy.Name == "" {
 		proxy.Name = proxy.Server
 	}
-	
+
 	return proxy, nil
 }
 
@@ -848,22 +846,22 @@ func parseSSURL(urlStr string) (ProxyConfig, error) {
 func parseVMessURL(urlStr string) (ProxyConfig, error) {
 	var proxy ProxyConfig
 	proxy.Type = "vmess"
-	
+
 	// 移除 "vmess://" 前缀
 	base64Str := urlStr[8:]
-	
+
 	// 解码Base64内容
 	jsonBytes, err := decodeBase64UrlSafe(base64Str)
 	if err != nil {
-		return proxy, fmt.Errorf("解码VMess URL失败: %v", err)
+		return proxy, fmt.Errorf(T("解码VMess URL失败: %w"), err)
 	}
-	
+
 	// 解析JSON
 	var vmessConfig map[string]interface{}
 	if err := json.Unmarshal(jsonBytes, &vmessConfig); err != nil {
-		return proxy, fmt.Errorf("解析VMess配置失败: %v", err)
+		return proxy, fmt.Errorf(T("解析VMess配置失败: %w"), err)
 	}
-	
+
 	// 提取配置信息
 	if v, ok := vmessConfig["ps"].(string); ok {
 		proxy.Name = v
@@ -872,29 +870,29 @@ func parseVMessURL(urlStr string) (ProxyConfig, error) {
 	} else {
 		proxy.Name = "VMess节点"
 	}
-	
+
 	if v, ok := vmessConfig["add"].(string); ok {
 		proxy.Server = v
 	} else if v, ok := vmessConfig["host"].(string); ok {
 		proxy.Server = v
 	} else {
-		return proxy, fmt.Errorf("无法提取服务器地址")
+		return proxy, errors.New(T("无法提取服务器地址"))
 	}
-	
+
 	if v, ok := vmessConfig["port"].(float64); ok {
 		proxy.Port = fmt.Sprintf("%d", int(v))
 	} else if v, ok := vmessConfig["port"].(string); ok {
 		proxy.Port = v
 	} else {
-		return proxy, fmt.Errorf("无法提取端口")
+		return proxy, errors.New(T("无法提取端口"))
 	}
-	
+
 	if v, ok := vmessConfig["id"].(string); ok {
 		proxy.UUID = v
 	} else {
-		return proxy, fmt.Errorf("无法提取UUID")
+		return proxy, errors.New(T("无法提取UUID"))
 	}
-	
+
 	if v, ok := vmessConfig["aid"].(float64); ok {
 		proxy.AlterId = fmt.Sprintf("%d", int(v))
 	} else if v, ok := vmessConfig["aid"].(string); ok {
@@ -902,13 +900,13 @@ func parseVMessURL(urlStr string) (ProxyConfig, error) {
 	} else {
 		proxy.AlterId = "0"
 	}
-	
+
 	if v, ok := vmessConfig["security"].(string); ok {
 		proxy.Cipher = v
 	} else {
 		proxy.Cipher = "auto"
 	}
-	
+
 	return proxy, nil
 }
 
@@ -916,36 +914,36 @@ func parseVMessURL(urlStr string) (ProxyConfig, error) {
 func parseTrojanURL(urlStr string) (ProxyConfig, error) {
 	var proxy ProxyConfig
 	proxy.Type = "trojan"
-	
+
 	// trojan://password@server:port?allowInsecure=1#name
 	u, err := url.Parse(urlStr)
 	if err != nil {
-		return proxy, fmt.Errorf("解析Trojan URL失败: %v", err)
+		return proxy, fmt.Errorf(T("解析Trojan URL失败: %w"), err)
 	}
-	
+
 	// 提取密码
 	if u.User != nil {
 		proxy.Password = u.User.String()
 	} else {
-		return proxy, fmt.Errorf("无法提取密码")
+		return proxy, errors.New(T("无法提取密码"))
 	}
-	
+
 	// 提取服务器和端口
 	hostParts := strings.Split(u.Host, ":")
 	if len(hostParts) != 2 {
-		return proxy, fmt.Errorf("无效的服务器:端口格式")
+		return proxy, errors.New(T("无效的服务器:端口格式"))
 	}
-	
+
 	proxy.Server = hostParts[0]
 	proxy.Port = hostParts[1]
-	
+
 	// 提取节点名称
 	if u.Fragment != "" {
 		proxy.Name = u.Fragment
 	} else {
 		proxy.Name = "Trojan节点"
 	}
-	
+
 	return proxy, nil
 }
 
@@ -954,7 +952,7 @@ func decodeBase64UrlSafe(s string) ([]byte, error) {
 	// 替换URL安全字符
 	s = strings.ReplaceAll(s, "-", "+")
 	s = strings.ReplaceAll(s, "_", "/")
-	
+
 	// 添加缺失的=号
 	switch len(s) % 4 {
 	case 2:
@@ -962,132 +960,75 @@ func decodeBase64UrlSafe(s string) ([]byte, error) {
 	case 3:
 		s += "="
 	}
-	
+
 	return base64.StdEncoding.DecodeString(s)
 }
 
 func collectProxyGroupConfig(proxies []ProxyConfig) (ProxyGroupConfig, error) {
 	var group ProxyGroupConfig
-	reader := bufio.NewReader(os.Stdin)
-	
-	fmt.Println("\n== 配置代理组 ==")
-	
-	fmt.Print("代理组名称: ")
+	reader := stdinReader
+
+	fmt.Println(T("\n== 配置代理组 =="))
+
+	fmt.Print(T("代理组名称: "))
 	group.Name, _ = reader.ReadString('\n')
 	group.Name = strings.TrimSpace(group.Name)
-	
-	fmt.Print("代理组类型 (select/url-test/fallback/load-balance): ")
+
+	fmt.Print(T("代理组类型 (select/url-test/fallback/load-balance): "))
 	group.Type, _ = reader.ReadString('\n')
 	group.Type = strings.TrimSpace(group.Type)
 	if group.Type == "" {
 		group.Type = "select"
+	} else if !proxyGroupTypes[group.Type] || group.Type == "relay" {
+		// 安装时的代理组包含所有节点和 DIRECT，不适合作为 relay
+		fmt.Printf(T("不支持的代理组类型 %s，将使用 select\n"), group.Type)
+		group.Type = "select"
 	}
-	
+
 	// 收集所有代理名称
 	for _, proxy := range proxies {
 		group.ProxyNames = append(group.ProxyNames, proxy.Name)
 	}
-	
+
 	if len(proxies) > 0 && group.Type == "select" {
-		fmt.Println("可用的代理:")
-		for i, name := range group.ProxyNames {
-			fmt.Printf("%d. %s\n", i+1, name)
-		}
-		
-		var selectedIndex int
-		fmt.Print("请选择默认代理 (输入序号): ")
-		fmt.Scanln(&selectedIndex)
-		
-		if selectedIndex > 0 && selectedIndex <= len(group.ProxyNames) {
-			group.SelectedProxy = group.ProxyNames[selectedIndex-1]
+		// 返回时使用第一个代理
+		selectedIndex := selectMenu(T("选择默认代理"), nil, group.ProxyNames, T("使用第一个代理"))
+
+		if selectedIndex >= 0 {
+			group.SelectedProxy = group.ProxyNames[selectedIndex]
 		} else if len(group.ProxyNames) > 0 {
 			group.SelectedProxy = group.ProxyNames[0]
 		}
 	}
-	
+
 	return group, nil
 }
 
-func generateProxiesConfig(proxies []ProxyConfig) string {
-	var result strings.Builder
-	
-	for _, proxy := range proxies {
-		result.WriteString("    - { name: '")
-		result.WriteString(proxy.Name)
-		result.WriteString("', type: ")
-		result.WriteString(proxy.Type)
-		result.WriteString(", server: ")
-		result.WriteString(proxy.Server)
-		result.WriteString(", port: ")
-		result.WriteString(proxy.Port)
-		
-		if proxy.Type == "vmess" {
-			result.WriteString(", uuid: ")
-			result.WriteString(proxy.UUID)
-			result.WriteString(", alterId: ")
-			result.WriteString(proxy.AlterId)
-			result.WriteString(", cipher: ")
-			result.WriteString(proxy.Cipher)
-			result.WriteString(", udp: true")
-		} else if proxy.Type == "ss" || proxy.Type == "shadowsocks" {
-			result.WriteString(", password: ")
-			result.WriteString(proxy.Password)
-			result.WriteString(", cipher: ")
-			result.WriteString(proxy.Cipher)
-			result.WriteString(", udp: true")
-		} else if proxy.Type == "trojan" {
-			result.WriteString(", password: ")
-			result.WriteString(proxy.Password)
-			result.WriteString(", udp: true")
+// 将交互式收集的节点和代理组转换为配置中的节点层
+func generatedNodesConfig(proxies []ProxyConfig, group ProxyGroupConfig) (*ClashConfig, error) {
+	nodes := &ClashConfig{}
+	for _, pc := range proxies {
+		proxy, err := pc.toProxy()
+		if err != nil {
+			return nil, fmt.Errorf(T("节点 %s: %w"), pc.Name, err)
 		}
-		
-		result.WriteString(" }\n")
+		nodes.Proxies = append(nodes.Proxies, proxy)
 	}
-	
-	return result.String()
-}
 
-func generateProxyGroupConfig(group ProxyGroupConfig) string {
-	var sb strings.Builder
-	
-	sb.WriteString("  - name: ")
-	sb.WriteString(group.Name)
-	sb.WriteString("\n    type: ")
-	sb.WriteString(group.Type)
-	sb.WriteString("\n    proxies:\n")
-	
-	// 添加所有代理节点，保持原来的引号格式
-	for _, proxyName := range group.ProxyNames {
-		sb.WriteString("      - \"")
-		sb.WriteString(proxyName)
-		sb.WriteString("\"\n")
-	}
-	
-	// 添加内置策略 DIRECT，使用与其他代理相同的格式
-	sb.WriteString("      - \"DIRECT\"\n")
-	
-	// 如果有选中的代理，添加 selected 字段
+	// 代理组包含所有节点，并额外加入内置策略 DIRECT
+	proxyGroup := ProxyGroup{
+		Name:    group.Name,
+		Type:    group.Type,
+		Proxies: append(append([]string{}, group.ProxyNames...), "DIRECT"),
+	}
 	if group.Type == "select" && group.SelectedProxy != "" {
-		sb.WriteString("    selected: \"")
-		sb.WriteString(group.SelectedProxy)
-		sb.WriteString("\"\n")
+		proxyGroup.Selected = group.SelectedProxy
 	}
-	
-	return sb.String()
-}
+	// url-test 等类型需要健康检查参数
+	applyGroupDefaults(&proxyGroup)
+	nodes.ProxyGroups = append(nodes.ProxyGroups, proxyGroup)
 
-func readConfigTemplate() (string, error) {
-	// 从嵌入资源中读取模板
-	templateContent, err := readEmbeddedFile("resources/config-template.yaml")
-	if err != nil {
-		// 如果嵌入资源读取失败，尝试从文件系统读取作为备选
-		templateContent, err = os.ReadFile("resources/config-template.yaml")
-		if err != nil {
-			return "", err
-		}
-	}
-	
-	return string(templateContent), nil
+	return nodes, nil
 }
 
 // 安装内置的 Country.mmdb 文件
@@ -1095,106 +1036,113 @@ func installCountryMMDBFromEmbedded() error {
 	// 确保配置目录存在
 	configDir := "/etc/clash"
 	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("创建Clash配置目录失败: %v", err)
+		return fmt.Errorf(T("创建Clash配置目录失败: %w"), err)
 	}
-	
+
 	// 从嵌入资源中读取Country.mmdb文件
 	mmdbContent, err := readEmbeddedFile("resources/Country.mmdb")
 	if err != nil {
-		return fmt.Errorf("读取嵌入的Country.mmdb文件失败: %v", err)
+		return fmt.Errorf(T("读取嵌入的Country.mmdb文件失败: %w"), err)
 	}
-	
+
 	// 写入到标准位置
 	mmdbPath := filepath.Join(configDir, "Country.mmdb")
 	if err := os.WriteFile(mmdbPath, mmdbContent, 0644); err != nil {
-		return fmt.Errorf("写入Country.mmdb文件失败: %v", err)
+		return fmt.Errorf(T("写入Country.mmdb文件失败: %w"), err)
 	}
-	
+
 	// 创建从标准位置到Clash工作目录的符号链接
-	clashDir := "/srv/clash"
+	clashDir := clashWorkDir()
 	if err := os.MkdirAll(clashDir, 0755); err != nil {
-		return fmt.Errorf("创建Clash工作目录失败: %v", err)
+		return fmt.Errorf(T("创建Clash工作目录失败: %w"), err)
 	}
-	
+
 	clashMMDBPath := filepath.Join(clashDir, "Country.mmdb")
 	// 如果已存在，先删除
 	if _, err := os.Stat(clashMMDBPath); err == nil {
 		os.Remove(clashMMDBPath)
 	}
-	
+
 	// 创建符号链接
 	if err := os.Symlink(mmdbPath, clashMMDBPath); err != nil {
-		return fmt.Errorf("创建Country.mmdb符号链接失败: %v", err)
+		return fmt.Errorf(T("创建Country.mmdb符号链接失败: %w"), err)
 	}
-	
+
 	// 添加一个到/root/.config/clash/Country.mmdb的符号链接
 	rootConfigDir := "/root/.config/clash"
 	if err := os.MkdirAll(rootConfigDir, 0755); err != nil {
-		return fmt.Errorf("创建root用户Clash配置目录失败: %v", err)
+		return fmt.Errorf(T("创建root用户Clash配置目录失败: %w"), err)
 	}
-	
+
 	rootMMDBPath := filepath.Join(rootConfigDir, "Country.mmdb")
 	// 如果已存在，先删除
 	if _, err := os.Stat(rootMMDBPath); err == nil {
 		os.Remove(rootMMDBPath)
 	}
-	
+
 	// 创建符号链接
 	if err := os.Symlink(mmdbPath, rootMMDBPath); err != nil {
-		return fmt.Errorf("创建到root用户目录的Country.mmdb符号链接失败: %v", err)
+		return fmt.Errorf(T("创建到root用户目录的Country.mmdb符号链接失败: %w"), err)
 	}
-	
+
 	// 设置GEOIP_DATABASE环境变量到systemd服务
 	// 创建systemd环境变量目录
 	envDir := "/etc/systemd/system/clash.service.d"
 	if err := os.MkdirAll(envDir, 0755); err != nil {
-		return fmt.Errorf("创建systemd服务环境变量目录失败: %v", err)
+		return fmt.Errorf(T("创建systemd服务环境变量目录失败: %w"), err)
 	}
-	
+
 	// 创建环境变量配置文件
 	envContent := `[Service]
 Environment="GEOIP_DATABASE=` + mmdbPath + `"
 `
 	envPath := filepath.Join(envDir, "environment.conf")
 	if err := os.WriteFile(envPath, []byte(envContent), 0644); err != nil {
-		return fmt.Errorf("写入systemd环境变量配置失败: %v", err)
+		return fmt.Errorf(T("写入systemd环境变量配置失败: %w"), err)
 	}
-	
+
 	// 重新加载systemd配置
 	reloadCmd := exec.Command("systemctl", "daemon-reload")
 	if err := reloadCmd.Run(); err != nil {
-		return fmt.Errorf("重新加载systemd配置失败: %v", err)
+		return fmt.Errorf(T("重新加载systemd配置失败: %w"), err)
 	}
-	
-	fmt.Printf("Country.mmdb已安装到%s，并创建了符号链接到%s和%s\n", 
+
+	fmt.Printf(T("Country.mmdb已安装到%s，并创建了符号链接到%s和%s\n"),
 		mmdbPath, clashMMDBPath, rootMMDBPath)
-	fmt.Printf("已设置GEOIP_DATABASE环境变量到%s\n", mmdbPath)
+	fmt.Printf(T("已设置GEOIP_DATABASE环境变量到%s\n"), mmdbPath)
 	return nil
 }
 
 // 读取Clash配置文件
-func readClashConfig() (map[string]interface{}, error) {
-	configPath := "/srv/clash/config.yaml"
-	content, err := os.ReadFile(configPath)
+func readClashConfig() (*ClashConfig, error) {
+	content, err := os.ReadFile(clashConfigPath())
 	if err != nil {
-		return nil, err
+		return nil, withKind(ErrConfigRead, err)
 	}
-	
-	var config map[string]interface{}
-	if err := yaml.Unmarshal(content, &config); err != nil {
-		return nil, err
-	}
-	
-	return config, nil
+
+	config, err := parseClashConfig(content)
+	return config, withKind(ErrConfigRead, err)
 }
 
 // 保存Clash配置文件
-func saveClashConfig(config map[string]interface{}) error {
-	configPath := "/srv/clash/config.yaml"
-	content, err := yaml.Marshal(config)
-	if err != nil {
+func saveClashConfig(config *ClashConfig) error {
+	// 保存前校验，避免写入 Clash 无法加载的配置；校验失败返回 *ConfigValidationError，可用 errors.As 判断
+	if err := validateConfig(config); err != nil {
 		return err
 	}
-	
-	return os.WriteFile(configPath, content, 0644)
-}
\ No newline at end of file
+
+	// 在原文件基础上修改，保留注释和字段顺序
+	var content []byte
+	var err error
+	if original, readErr := os.ReadFile(clashConfigPath()); readErr == nil {
+		content, err = renderClashConfig(original, config)
+	} else {
+		content, err = yaml.Marshal(config)
+	}
+	if err != nil {
+		return withKind(ErrConfigSave, err)
+	}
+
+	// 原子写入，并为旧配置保存快照
+	return withKind(ErrConfigSave, writeClashConfig(content))
+}
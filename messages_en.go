@@ -0,0 +1,1120 @@
+package main
+
+// 英文消息目录，键为代码中的中文原文
+var messagesEN = map[string]string{
+	// chain.go
+	"读取配置文件失败: %v\n":                                      "Failed to read config file: %v\n",
+	"chain %s 失败: %v\n":                                   "chain %s failed: %v\n",
+	"未知的参数: --%s\n":                                       "Unknown option: --%s\n",
+	"未知的 chain 子命令: %s\n":                                 "Unknown chain subcommand: %s\n",
+	"保存配置失败: %v\n":                                        "Failed to save config: %v\n",
+	"\n配置已更新，您需要重启 Clash 服务以应用更改":                         "\nConfig updated. Restart the Clash service to apply the changes",
+	"用法: %s chain <子命令> [参数]\n\n":                         "Usage: %s chain <subcommand> [options]\n\n",
+	"可用子命令:":                                              "Subcommands:",
+	"  list                                        列出代理链": "  list                                        List proxy chains",
+	"  create <名称> <第一跳> <第二跳>... [--mode relay|dialer]":  "  create <name> <hop1> <hop2>... [--mode relay|dialer]",
+	"                                              创建代理链，流量依次经过各跳，最后一跳为出口": "                                              Create a proxy chain; traffic passes through each hop in order, the last hop is the exit",
+	"  delete <名称>                               删除代理链":                    "  delete <name>                               Delete a proxy chain",
+	"\n--mode relay  (默认) 生成 relay 代理组，各跳可以是节点或代理组":                        "\n--mode relay  (default) create a relay group; hops may be nodes or groups",
+	"--mode dialer 复制第二跳之后的节点并通过 dialer-proxy 串联，需要 Meta 内核":               "--mode dialer copy the nodes after the second hop and link them via dialer-proxy; requires the Meta core",
+	"代理链至少需要两跳":                               "A proxy chain needs at least two hops",
+	"第 %d 跳不能是内置策略 %s":                        "Hop %d cannot be the built-in policy %s",
+	"第 %d 跳与前一跳相同: %s":                        "Hop %d is the same as the previous hop: %s",
+	"第 %d 跳 %s 不是已有的节点或代理组":                   "Hop %d %s is not an existing node or group",
+	"第 %d 跳 %s 本身是 relay 代理组，不能嵌套":            "Hop %d %s is itself a relay group and cannot be nested",
+	"dialer 模式下只有第一跳可以是代理组，%s 需要是节点":          "In dialer mode only the first hop may be a group; %s must be a node",
+	"%s (%s) 基于 UDP 传输，只能作为第一跳":               "%s (%s) uses UDP transport and can only be the first hop",
+	"%s (%s) 基于 UDP 传输，前一跳 %s 需要是开启了 udp 的节点": "%s (%s) uses UDP transport; the previous hop %s must be a node with udp enabled",
+	"无效的代理链名称: %q":                            "Invalid proxy chain name: %q",
+	"名称 %s 已被节点、代理组或内置策略使用":                   "Name %s is already used by a node, group or built-in policy",
+	"不支持的模式: %s (可选 relay、dialer)":            "Unsupported mode: %s (choose relay or dialer)",
+	"名称 %s 已被使用":                              "Name %s is already in use",
+	"已将代理链 %s 加入代理组 %s\n":                     "Added proxy chain %s to group %s\n",
+	"配置文件中没有代理链":                              "No proxy chains in the config file",
+	"共有 %d 条代理链:\n\n":                         "%d proxy chain(s):\n\n",
+	"%s 不是代理链":                                "%s is not a proxy chain",
+	"代理链 %s 仍被规则 %s 使用":                       "Proxy chain %s is still used by rule %s",
+	"\n可用的节点:":                                "\nAvailable nodes:",
+	"\n代理链名称: ":                               "\nProxy chain name: ",
+	"按转发顺序输入各跳的节点或代理组，用逗号分隔，最后一个为出口: ":         "Enter the nodes or groups of each hop in forwarding order, separated by commas; the last one is the exit: ",
+	"使用 dialer-proxy 串联 (需要 Meta 内核)? [y/N]: ": "Link hops with dialer-proxy (requires the Meta core)? [y/N]: ",
+
+	// clash_api.go
+	"Clash 控制接口 %s %s 返回状态码 %d":     "Clash controller %s %s returned status code %d",
+	"Clash 控制接口 %s %s 返回状态码 %d: %s": "Clash controller %s %s returned status code %d: %s",
+	"无法连接 Clash 控制接口: %w":           "Cannot connect to the Clash controller: %w",
+	"解析 Clash 控制接口 %s 的响应失败: %w":    "Failed to parse the Clash controller response for %s: %w",
+
+	// clash_config.go
+	"无效的端口: %s": "Invalid port: %s",
+	"代理缺少名称":    "Proxy is missing a name",
+
+	// config_backup.go
+	"读取配置历史失败: %v\n":                           "Failed to read config history: %v\n",
+	"回滚配置失败: %v\n":                             "Failed to roll back config: %v\n",
+	"比较配置失败: %v\n":                             "Failed to compare configs: %v\n",
+	"生成配置失败: %v\n":                             "Failed to render config: %v\n",
+	"未知的 config 子命令: %s\n":                     "Unknown config subcommand: %s\n",
+	"用法: %s config <子命令> [参数]\n\n":             "Usage: %s config <subcommand> [options]\n\n",
+	"  history                  列出配置快照，最新的在最前": "  history                  List config snapshots, newest first",
+	"  rollback [序号|时间戳]   恢复指定快照并重启 Clash，默认恢复最近一次":               "  rollback [index|stamp]   Restore a snapshot and restart Clash; defaults to the latest one",
+	"  diff [-u] <a> [b]        比较两份配置，a/b 可以是文件路径、快照序号、时间戳或 live": "  diff [-u] <a> [b]        Compare two configs; a/b may be a file path, snapshot index, timestamp or live",
+	"                           b 默认为当前配置，-u 输出统一格式的文本差异":          "                           b defaults to the current config; -u prints a unified text diff",
+	"  render                   输出内置模板、conf.d/*.yaml 和当前节点合并后的配置":  "  render                   Print the built-in template merged with conf.d/*.yaml and the current nodes",
+	"备份配置失败: %w": "Failed to back up config: %w",
+	"还没有配置快照，每次保存配置时会自动创建":                    "No config snapshots yet; one is created automatically every time the config is saved",
+	"共有 %d 个配置快照 (保存在 %s):\n\n":               "%d config snapshot(s) (stored in %s):\n\n",
+	"%2d. %s  %s  %d 字节\n":                    "%2d. %s  %s  %d bytes\n",
+	"\n使用 %s config rollback <序号|时间戳> 恢复快照\n": "\nUse %s config rollback <index|timestamp> to restore a snapshot\n",
+	"没有可用的配置快照":                               "No config snapshots available",
+	"快照序号超出范围: %d (共 %d 个)":                   "Snapshot index out of range: %d (%d in total)",
+	"未找到时间戳为 %s 的快照":                          "No snapshot with timestamp %s",
+	"时间戳 %s 匹配到 %d 个快照，请提供更完整的时间戳":            "Timestamp %s matches %d snapshots; please give a more complete timestamp",
+	"读取快照失败: %w":                              "Failed to read snapshot: %w",
+	"写入配置失败: %w":                              "Failed to write config: %w",
+	"已恢复配置快照 %s\n":                            "Restored config snapshot %s\n",
+	"正在重启 Clash 服务...":                        "Restarting the Clash service...",
+	"Clash 服务已重启并正常运行":                        "The Clash service has restarted and is running",
+	"Clash 未能正常启动: %v\n":                      "Clash failed to start: %v\n",
+	"Clash 未能正常启动，且没有可恢复的配置快照":                "Clash failed to start and there is no config snapshot to restore",
+	"正在恢复上一份配置 (快照 %s)...\n":                  "Restoring the previous config (snapshot %s)...\n",
+	"恢复配置失败: %w":                              "Failed to restore config: %w",
+	"已恢复上一份配置，但 Clash 仍未能启动: %w":              "Restored the previous config, but Clash still failed to start: %w",
+	"新配置导致 Clash 无法启动，已自动恢复上一份配置 (出错的配置已保存为快照，可用 config history 查看)": "The new config prevented Clash from starting; the previous config was restored automatically (the failing config was saved as a snapshot, see config history)",
+	"systemctl restart 失败: %w %s": "systemctl restart failed: %w %s",
+	"Clash 服务已退出":                 "The Clash service has exited",
+	"等待超时":                        "Timed out waiting",
+	"%v 内未就绪: %w":                 "not ready within %v: %w",
+
+	// config_diff.go
+	"~ proxy-provider %s 已修改": "~ proxy-provider %s modified",
+	"~ rule-provider %s 已修改":  "~ rule-provider %s modified",
+	"%c 规则 %s":                "%c rule %s",
+	"- 节点 %s":                 "- node %s",
+	"+ 节点 %s (%s %s)":         "+ node %s (%s %s)",
+	"~ 节点 %s: %s":             "~ node %s: %s",
+	"%s 已修改":                  "%s modified",
+	"%s 已删除":                  "%s removed",
+	"- 代理组 %s":                "- group %s",
+	"+ 代理组 %s (%s, %d 个成员)":   "+ group %s (%s, %d members)",
+	"类型 %s -> %s":             "type %s -> %s",
+	"成员顺序已调整":                 "member order changed",
+	"选中 %s -> %s":             "selected %s -> %s",
+	"其他参数已修改":                 "other options modified",
+	"~ 代理组 %s: %s":            "~ group %s: %s",
+	"无":                       "none",
+	"配置没有变化":                  "No config changes",
+	"共 %d 处变化:\n":             "%d change(s):\n",
+	"\n配置没有变化，无需保存":           "\nNo config changes, nothing to save",
+	"即将对配置做如下修改，共 %d 处变化:": "The following %d change(s) will be made to the config:",
+	"确认修改":      "Confirm changes",
+	"确认保存以上修改？": "Save the changes above?",
+	"已取消，配置未修改": "Cancelled, config not modified",
+	"用法: config diff [-u] <a> [b]，b 默认为当前配置 (live)": "Usage: config diff [-u] <a> [b]; b defaults to the current config (live)",
+	"两份配置内容相同":             "The two configs are identical",
+	"解析 %s 失败: %w":         "Failed to parse %s: %w",
+	"%s 既不是文件也不是有效的快照: %w": "%s is neither a file nor a valid snapshot: %w",
+
+	// config_overlay.go
+	"读取 %s 失败: %w":                       "Failed to read %s: %w",
+	"生成的节点":                              "generated nodes",
+	"%s 的顶层必须是映射":                        "The top level of %s must be a mapping",
+	"合并 %s 失败: %w":                       "Failed to merge %s: %w",
+	"%s 中的条目缺少 name":                     "An entry in %s is missing name",
+	"prepend-rules 和 append-rules 必须是列表": "prepend-rules and append-rules must be lists",
+	"读取当前配置失败: %w":                       "Failed to read the current config: %w",
+	"\n注意: 合并结果未通过校验: %v\n":              "\nNote: the merged result failed validation: %v\n",
+
+	// config_template.go
+	"内置模板":             "built-in template",
+	"读取配置模板失败: %w":     "Failed to read config template: %w",
+	"解析配置模板 %s 失败: %w": "Failed to parse config template %s: %w",
+	"渲染配置模板 %s 失败: %w": "Failed to render config template %s: %w",
+
+	// config_validate.go
+	"配置校验失败:\n  - ":                         "Config validation failed:\n  - ",
+	"代理 %s 缺少类型":                            "Proxy %s is missing a type",
+	"代理 %s (%s) 缺少必要字段 %s":                  "Proxy %s (%s) is missing required field %s",
+	"代理 %s 的端口超出范围: %d":                     "Port of proxy %s is out of range: %d",
+	"%s 超出端口范围: %d":                         "%s is out of the port range: %d",
+	"代理 %s 的端口无效: %q":                       "Port of proxy %s is invalid: %q",
+	"%s 的端口无效: %q":                          "%s has an invalid port: %q",
+	"第 %d 个代理缺少名称":                          "Proxy #%d is missing a name",
+	"代理名称重复: %s":                            "Duplicate proxy name: %s",
+	"存在缺少名称的代理组":                            "A proxy group is missing a name",
+	"代理组 %s 与代理节点重名":                        "Group %s has the same name as a proxy",
+	"代理组名称重复: %s":                           "Duplicate group name: %s",
+	"代理组 %s 的类型无效: %q":                      "Group %s has an invalid type: %q",
+	"代理组 %s 没有任何成员":                         "Group %s has no members",
+	"代理组 %s 引用了自身":                          "Group %s references itself",
+	"代理组 %s 引用了不存在的节点或代理组: %s":              "Group %s references a nonexistent node or group: %s",
+	"代理组 %s 引用了不存在的 proxy-provider: %s":     "Group %s references a nonexistent proxy-provider: %s",
+	"代理组 %s 选中的节点 %s 不在组内":                  "Group %s has selected node %s, which is not in the group",
+	"代理组 %s (%s) 缺少健康检查的 url 或 interval":    "Group %s (%s) is missing the health check url or interval",
+	"代理组 %s 的负载均衡策略无效: %q":                  "Group %s has an invalid load-balance strategy: %q",
+	"relay 代理组 %s 不能包含内置策略 %s":              "Relay group %s cannot contain built-in policy %s",
+	"代理组之间存在循环引用: %s":                       "Circular reference between groups: %s",
+	"rule-provider %s 缺少 url":               "rule-provider %s is missing url",
+	"rule-provider %s 缺少 path":              "rule-provider %s is missing path",
+	"rule-provider %s 的类型无效: %q":            "rule-provider %s has an invalid type: %q",
+	"rule-provider %s 的 behavior 无效: %q":    "rule-provider %s has an invalid behavior: %q",
+	"第 %d 条规则 %q: %v":                       "Rule #%d %q: %v",
+	"第 %d 条规则 %q 的目标 %s 不存在":                "Rule #%d %q targets %s, which does not exist",
+	"第 %d 条规则 %q 之后的规则不会生效，MATCH 必须是最后一条规则": "Rules after rule #%d %q never match; MATCH must be the last rule",
+
+	// dns_client.go
+	"系统 DNS": "system DNS",
+	"nameserver 和 fallback 均解析失败: %w; %w": "both nameserver and fallback failed to resolve: %w; %w",
+	"没有可用的 DNS 服务器":                       "No DNS server available",
+	"没有 %s 的解析记录":                         "No records for %s",
+	"不支持的 DNS 协议: %s":                     "Unsupported DNS protocol: %s",
+	"通过 default-nameserver 解析 %s 失败: %w":  "Failed to resolve %s via default-nameserver: %w",
+	"状态码: %d":                             "status code: %d",
+	"DNS 响应过短":                            "DNS response too short",
+	"DNS 响应 ID 不匹配":                       "DNS response ID mismatch",
+	"DNS 服务器返回错误码 %d":                     "DNS server returned error code %d",
+	"DNS 响应被截断":                           "DNS response truncated",
+	"没有解析记录":                              "No records",
+
+	// i18n.go
+	"不支持的语言: %s，可选 zh-CN、en":    "Unsupported language: %s (choose zh-CN or en)",
+	"读取配置文件失败":                  "failed to read config file",
+	"保存配置失败":                    "failed to save config",
+	"节点不存在":                     "node not found",
+	"代理组不存在":                    "group not found",
+	"profile 不存在":               "profile not found",
+	"rule-provider 不存在":         "rule-provider not found",
+	"名称已被使用":                    "name already in use",
+	"仍被规则引用":                    "still referenced by rules",
+	"仍被代理链引用":                   "still referenced by a proxy chain",
+	"节点未停用":                     "node is not disabled",
+	"无法连接 Clash 控制接口":           "cannot connect to the Clash controller",
+	"Clash 控制接口拒绝访问，请检查 secret": "the Clash controller denied access, check the secret",
+
+	// main.go
+	"用法: %s [--config 配置文件] [--workdir 工作目录] [--template 模板] [--lang 语言] <命令> [参数]\n\n": "Usage: %s [--config file] [--workdir dir] [--template template] [--lang language] <command> [options]\n\n",
+	"全局参数:": "Global options:",
+	"  --config   Clash 配置文件路径，也可通过 CLASH_CONFIG_PATH 环境变量指定":                 "  --config   Path of the Clash config file; can also be set with CLASH_CONFIG_PATH",
+	"  --workdir  工作目录(快照、订阅缓存、providers 等)，也可通过 CLASH_WORKDIR 环境变量指定":        "  --workdir  Working directory (snapshots, subscription cache, providers...); can also be set with CLASH_WORKDIR",
+	"  --template reset-config 和 config render 使用的配置模板 (Go text/template 格式)": "  --template Config template used by reset-config and config render (Go text/template format)",
+	"  --lang     界面语言 zh-CN 或 en，默认根据 LC_ALL、LC_MESSAGES、LANG 环境变量选择":        "  --lang     Interface language zh-CN or en; defaults to LC_ALL, LC_MESSAGES, LANG",
+	"可用命令:": "Commands:",
+	"  install    安装并配置 Clash Premium": "  install    Install and configure Clash Premium",
+	"  start      启动 Clash 服务":         "  start      Start the Clash service",
+	"  stop       停止 Clash 服务":         "  stop       Stop the Clash service",
+	"  restart    重启 Clash 服务":         "  restart    Restart the Clash service",
+	"  status     检查 Clash 服务状态":       "  status     Check the Clash service status",
+	"  update     更新 Clash Premium":    "  update     Update Clash Premium",
+	"  reset-config     重置配置文件":        "  reset-config     Reset the config file",
+	"  proxy      节点配置管理 (edit <节点> --set key=value 修改节点，disable/enable <节点>... 停用或启用节点，disabled 列出停用的节点，bulk 批量操作)": "  proxy      Manage nodes (edit <node> --set key=value edits a node, disable/enable <node>... parks or restores nodes, disabled lists parked nodes, bulk for bulk operations)",
+	"  providers  proxy-providers 管理 (list/refresh)":                        "  providers  Manage proxy-providers (list/refresh)",
+	"  subscription  订阅管理 (list/refresh)":                                   "  subscription  Manage subscriptions (list/refresh)",
+	"  config     配置快照管理 (history/rollback/diff/render)":                    "  config     Manage config snapshots (history/rollback/diff/render)",
+	"  profile    多套配置管理 (list/create/clone/switch/delete)":                 "  profile    Manage multiple configs (list/create/clone/switch/delete)",
+	"  group      代理组管理 (list/create/edit/delete/add-member/remove-member)": "  group      Manage proxy groups (list/create/edit/delete/add-member/remove-member)",
+	"  chain      代理链管理 (list/create/delete)":                               "  chain      Manage proxy chains (list/create/delete)",
+	"  rules      分流规则管理 (list/add/remove/move/test)":                       "  rules      Manage routing rules (list/add/remove/move/test)",
+	"  ruleset    规则集管理 (list/add/remove/refresh/timer)":                    "  ruleset    Manage rule sets (list/add/remove/refresh/timer)",
+	"  version    显示版本信息":                                                   "  version    Show version information",
+	"  help       显示帮助信息":                                                   "  help       Show help",
+	"未知命令: %s\n":                                                            "Unknown command: %s\n",
+	"Clash 管理工具 v%s\n":                                                      "Clash management tool v%s\n",
+	"开始自动配置 Clash Premium...":                                               "Configuring Clash Premium automatically...",
+	"创建工作目录失败: %v\n":                                                        "Failed to create working directory: %v\n",
+	"提取内置 clash-premium-installer...":                                       "Extracting the bundled clash-premium-installer...",
+	"提取 clash-premium-installer 失败: %v\n":                                   "Failed to extract clash-premium-installer: %v\n",
+	"修改 installer.sh 脚本...":                                                 "Patching installer.sh...",
+	"修改 installer.sh 失败: %v\n":                                              "Failed to patch installer.sh: %v\n",
+	"修改 setup-tun.sh 脚本...":                                                 "Patching setup-tun.sh...",
+	"修改 setup-tun.sh 失败: %v\n":                                              "Failed to patch setup-tun.sh: %v\n",
+	"安装 Clash Premium...":                                                   "Installing Clash Premium...",
+	"安装 Clash Premium 失败: %v\n":                                             "Failed to install Clash Premium: %v\n",
+	"安装 Country.mmdb 文件...":                                                 "Installing Country.mmdb...",
+	"安装 Country.mmdb 失败: %v\n":                                              "Failed to install Country.mmdb: %v\n",
+	"生成 Clash 配置文件...":                                                      "Generating the Clash config file...",
+	"生成配置文件失败: %v\n":                                                        "Failed to generate config file: %v\n",
+	"配置 systemd resolved...":                                                "Configuring systemd-resolved...",
+	"配置 systemd resolved 失败: %v\n":                                          "Failed to configure systemd-resolved: %v\n",
+	"Clash Premium 配置完成！":                                                   "Clash Premium is configured!",
+	"正在启动 Clash 服务...":                                                      "Starting the Clash service...",
+	"启动 Clash 服务失败: %v\n":                                                   "Failed to start the Clash service: %v\n",
+	"Clash 服务已启动":                                                           "The Clash service has started",
+	"正在停止 Clash 服务...":                                                      "Stopping the Clash service...",
+	"停止 Clash 服务失败: %v\n":                                                   "Failed to stop the Clash service: %v\n",
+	"Clash 服务已停止":                                                           "The Clash service has stopped",
+	"重启 Clash 服务失败: %v\n":                                                   "Failed to restart the Clash service: %v\n",
+	"Clash 服务已重启":                                                           "The Clash service has restarted",
+	"检查 Clash 服务状态...":                                                      "Checking the Clash service status...",
+	"开始更新 Clash Premium...":                                                 "Updating Clash Premium...",
+	"更新 Clash Premium...":                                                   "Updating Clash Premium...",
+	"更新 Clash Premium 失败: %v\n":                                             "Failed to update Clash Premium: %v\n",
+	"Clash Premium 更新完成！":                                                   "Clash Premium updated!",
+	"配置文件已生成，您可能需要重启 Clash 服务以应用新配置。":                                       "Config file generated; you may need to restart the Clash service to apply it.",
+	"解析生成的配置失败: %w":                                                         "Failed to parse the generated config: %w",
+	"手动输入参数":                                                                "Enter parameters manually",
+	"通过协议链接(URL)导入":                                                         "Import from protocol links (URL)",
+	"从现有配置文件导入":                                                             "Import from an existing config file",
+	"节点配置方式":                                                                "How to configure nodes",
+	"取消":                                                                    "Cancel",
+	"已取消配置":                                                                 "Configuration cancelled",
+	"请输入需要配置的代理数量: ":                                                        "Number of proxies to configure: ",
+	"无效的代理数量: %s":                                                           "Invalid number of proxies: %s",
+	"\n== 配置代理 %d ==\n":                                                     "\n== Configure proxy %d ==\n",
+	"代理名称: ":                                                                "Proxy name: ",
+	"代理类型 (vmess/ss/trojan/...): ":                                          "Proxy type (vmess/ss/trojan/...): ",
+	"服务器地址: ":                                                               "Server address: ",
+	"端口: ":                                                                  "Port: ",
+	"AlterID (默认为0): ":                                                      "AlterID (default 0): ",
+	"加密方式 (默认为auto): ":                                                      "Cipher (default auto): ",
+	"密码: ":                                                                  "Password: ",
+	"加密方式: ":                                                                "Cipher: ",
+	"\n请输入代理URL: ":                                                          "\nEnter the proxy URL: ",
+	"URL不能为空":                                                               "URL must not be empty",
+	"获取订阅内容失败: %w":                                                          "Failed to fetch subscription: %w",
+	"解析代理失败: %v, 跳过\n":                                                      "Failed to parse proxy: %v, skipping\n",
+	"不支持的URL格式":                                                             "Unsupported URL format",
+	"未找到有效的代理":                                                              "No valid proxies found",
+	"\n请输入配置文件路径: ":                                                         "\nEnter the config file path: ",
+	"文件路径不能为空":                                                              "File path must not be empty",
+	"读取文件失败: %w":                                                            "Failed to read file: %w",
+	"解析配置文件失败: %w":                                                          "Failed to parse config file: %w",
+	"未找到代理配置":                                                               "No proxy configuration found",
+	"解码失败: %w":                                                              "Decoding failed: %w",
+	"无效的编码格式":                                                               "Invalid encoding",
+	"无效的服务器:端口格式":                                                           "Invalid server:port format",
+	"解码后的内容无效":                                                              "Decoded content is invalid",
+	"解码VMess URL失败: %w":                                                     "Failed to decode VMess URL: %w",
+	"解析VMess配置失败: %w":                                                       "Failed to parse VMess config: %w",
+	"无法提取服务器地址":                                                             "Cannot extract the server address",
+	"无法提取端口":                                                                "Cannot extract the port",
+	"无法提取UUID":                                                              "Cannot extract the UUID",
+	"解析Trojan URL失败: %w":                                                    "Failed to parse Trojan URL: %w",
+	"无法提取密码":                                                                "Cannot extract the password",
+	"\n== 配置代理组 ==":                                                         "\n== Configure proxy group ==",
+	"代理组名称: ":                                                               "Group name: ",
+	"代理组类型 (select/url-test/fallback/load-balance): ":                       "Group type (select/url-test/fallback/load-balance): ",
+	"不支持的代理组类型 %s，将使用 select\n":                                             "Unsupported group type %s, using select\n",
+	"选择默认代理":                                                                "Select the default proxy",
+	"使用第一个代理":                                                               "Use the first proxy",
+	"节点 %s: %w":                                                             "Node %s: %w",
+	"创建Clash配置目录失败: %w":                                                     "Failed to create the Clash config directory: %w",
+	"读取嵌入的Country.mmdb文件失败: %w":                                             "Failed to read the embedded Country.mmdb: %w",
+	"写入Country.mmdb文件失败: %w":                                                "Failed to write Country.mmdb: %w",
+	"创建Clash工作目录失败: %w":                                                     "Failed to create the Clash working directory: %w",
+	"创建Country.mmdb符号链接失败: %w":                                              "Failed to create the Country.mmdb symlink: %w",
+	"创建root用户Clash配置目录失败: %w":                                               "Failed to create the Clash config directory for root: %w",
+	"创建到root用户目录的Country.mmdb符号链接失败: %w":                                    "Failed to create the Country.mmdb symlink in root's directory: %w",
+	"创建systemd服务环境变量目录失败: %w":                                               "Failed to create the systemd service environment directory: %w",
+	"写入systemd环境变量配置失败: %w":                                                 "Failed to write the systemd environment config: %w",
+	"重新加载systemd配置失败: %w":                                                   "Failed to reload systemd: %w",
+	"Country.mmdb已安装到%s，并创建了符号链接到%s和%s\n":                                   "Country.mmdb installed to %s, with symlinks at %s and %s\n",
+	"已设置GEOIP_DATABASE环境变量到%s\n":                                            "Set the GEOIP_DATABASE environment variable to %s\n",
+
+	// mmdb.go
+	"未找到 Country.mmdb":        "Country.mmdb not found",
+	"%s 不是有效的 MaxMind DB 文件":  "%s is not a valid MaxMind DB file",
+	"解析 MaxMind DB 元数据失败: %w": "Failed to parse MaxMind DB metadata: %w",
+	"MaxMind DB 元数据格式无效":      "Invalid MaxMind DB metadata",
+	"不支持的 record_size: %d":    "Unsupported record_size: %d",
+	"MaxMind DB 文件已损坏":        "The MaxMind DB file is corrupted",
+	"数据库只包含 IPv4 地址":          "The database only contains IPv4 addresses",
+	"MaxMind DB 搜索树无效":        "Invalid MaxMind DB search tree",
+	"数据偏移超出范围: %d":            "Data offset out of range: %d",
+	"数据被截断":                   "Data truncated",
+	"无效的 double 长度: %d":       "Invalid double length: %d",
+	"无效的 float 长度: %d":        "Invalid float length: %d",
+	"不支持的数据类型: %d":            "Unsupported data type: %d",
+
+	// paths.go
+	"Clash 配置文件路径": "Path of the Clash config file",
+	"工作目录，快照、订阅缓存、providers 等文件保存在这里": "Working directory for snapshots, subscription cache, providers and other files",
+	"生成配置时使用的模板文件":                    "Template file used when generating the config",
+	"界面语言: zh-CN 或 en":                "Interface language: zh-CN or en",
+
+	// profile.go
+	"未知的 profile 子命令: %s\n":                                      "Unknown profile subcommand: %s\n",
+	"profile %s 失败: %v\n":                                        "profile %s failed: %v\n",
+	"用法: %s profile <子命令> [参数]\n\n":                              "Usage: %s profile <subcommand> [options]\n\n",
+	"  list                   列出所有 profile，* 表示当前使用的 profile":    "  list                   List all profiles; * marks the profile in use",
+	"  create <名称> [文件]   从指定文件创建 profile，默认使用当前配置":              "  create <name> [file]   Create a profile from a file, defaults to the current config",
+	"  clone <源> <新名称>    复制已有的 profile":                         "  clone <src> <new>      Copy an existing profile",
+	"  switch <名称>          校验并切换到指定 profile，然后重启 Clash":         "  switch <name>          Validate and switch to a profile, then restart Clash",
+	"  delete <名称>          删除 profile，不能删除正在使用的 profile":        "  delete <name>          Delete a profile; the profile in use cannot be deleted",
+	"profile 名称不能为空":                                             "Profile name must not be empty",
+	"profile 名称不能以 . 开头":                                         "Profile name must not start with .",
+	"profile 名称只能包含字母、数字、-、_ 和 .: %s":                            "Profile name may only contain letters, digits, -, _ and .: %s",
+	"还没有 profile，可以使用 %s profile create <名称> 将当前配置保存为 profile\n": "No profiles yet; use %s profile create <name> to save the current config as a profile\n",
+	"共有 %d 个 profile (保存在 %s):\n\n":                              "%d profile(s) (stored in %s):\n\n",
+	"无法解析": "unparsable",
+	"%d 个节点, %d 个代理组, %d 条规则":                  "%d nodes, %d groups, %d rules",
+	"\n注意: 上次切换到的 profile 为 %s，但 %s 当前没有指向它\n": "\nNote: the last profile switched to is %s, but %s does not currently point to it\n",
+	"profile '%s' 已存在":                         "Profile '%s' already exists",
+	"%s 不是有效的 Clash 配置: %w":                    "%s is not a valid Clash config: %w",
+	"已创建 profile '%s' (%s)\n":                  "Created profile '%s' (%s)\n",
+	"profile '%s' 不存在":                         "Profile '%s' does not exist",
+	"解析 profile '%s' 失败: %w":                   "Failed to parse profile '%s': %w",
+	"profile '%s' 未通过校验: %w":                   "Profile '%s' failed validation: %w",
+	"当前已经在使用 profile '%s'\n":                   "Profile '%s' is already in use\n",
+	"备份当前配置失败: %w":                             "Failed to back up the current config: %w",
+	"当前配置不属于任何 profile，已保存为快照 %s\n":            "The current config does not belong to any profile; saved it as snapshot %s\n",
+	"切换配置文件失败: %w":                             "Failed to switch config file: %w",
+	"保存当前 profile 失败: %w":                      "Failed to save the current profile: %w",
+	"已切换到 profile '%s'\n":                      "Switched to profile '%s'\n",
+	"%s 不是 Clash 服务加载的配置，跳过重启\n":               "%s is not the config loaded by the Clash service, skipping restart\n",
+	"profile '%s' 导致 Clash 无法启动，且切换前没有可恢复的配置":  "Profile '%s' prevented Clash from starting, and there is no config from before the switch to restore",
+	"正在恢复切换前的配置...":                            "Restoring the config from before the switch...",
+	"保存当前 profile 失败: %v\n":                    "Failed to save the current profile: %v\n",
+	"已恢复切换前的配置，但 Clash 仍未能启动: %w":              "Restored the config from before the switch, but Clash still failed to start: %w",
+	"profile '%s' 导致 Clash 无法启动，已恢复切换前的配置":     "Profile '%s' prevented Clash from starting; the config from before the switch was restored",
+	"profile '%s' 正在使用中，请先切换到其他 profile":       "Profile '%s' is in use; switch to another profile first",
+	"确定要删除 profile '%s' 吗？":                    "Delete profile '%s'?",
+	"删除 profile":                               "Delete profile",
+	"将删除文件 ":                                   "This will delete the file ",
+	"已取消":                                      "Cancelled",
+	"已删除 profile '%s'\n":                       "Deleted profile '%s'\n",
+
+	// proxy_bulk.go
+	"名称匹配 ":                       "name matches ",
+	"类型为 ":                        "type is ",
+	"标签包含 ":                       "tags include ",
+	"测速失败":                        "speed test failed",
+	"全部节点":                        "all nodes",
+	"无效的正则表达式 %s: %w":             "Invalid regular expression %s: %w",
+	"failed 只能是 true 或 false: %s": "failed must be true or false: %s",
+	"未知的筛选条件: %s":                 "Unknown filter: %s",
+	"读取设置失败，测速结果未保存: %v\n":                   "Failed to read settings, speed test results not saved: %v\n",
+	"保存测速结果失败: %v\n":                         "Failed to save speed test results: %v\n",
+	"%s 替换后的名称无效: %q":                        "Name of %s after replacement is invalid: %q",
+	"%s 和 %s 替换后重名: %s":                      "%s and %s have the same name after replacement: %s",
+	"%s 替换后的名称 %s 已被使用":                      "New name of %s after replacement, %s, is already in use",
+	"不支持的排序方式: %s (可选 name、latency、region)":  "Unsupported sort order: %s (choose name, latency or region)",
+	"没有符合条件的节点":                              "No nodes match",
+	"共有 %d 个节点:\n\n":                         "%d node(s):\n\n",
+	" 测速失败":                                  " test failed",
+	"用法: %s proxy bulk <操作> [筛选条件] [参数]\n\n": "Usage: %s proxy bulk <action> [filters] [options]\n\n",
+	"操作:": "Actions:",
+	"  list                             列出选中的节点":                     "  list                             List the selected nodes",
+	"  delete                           删除选中的节点，必须指定筛选条件":            "  delete                           Delete the selected nodes; a filter is required",
+	"  rename --match <正则> --replace <替换>":                           "  rename --match <regex> --replace <replacement>",
+	"                                   替换选中节点名称中匹配的部分，可以使用 $1 引用分组": "                                   Replace the matching part of the selected node names; $1 refers to groups",
+	"  sort --by name|latency|region    排序所有节点和代理组成员":                "  sort --by name|latency|region    Sort all nodes and group members",
+	"  tag --add <标签,...>             为选中的节点添加标签":                    "  tag --add <tag,...>              Add tags to the selected nodes",
+	"  tag --remove <标签,...>          移除选中节点的标签":                     "  tag --remove <tag,...>           Remove tags from the selected nodes",
+	"  test                             测试选中节点的延迟并记录结果":              "  test                             Test the latency of the selected nodes and record the results",
+	"\n筛选条件 (可以组合):":                                                 "\nFilters (can be combined):",
+	"  --match <正则>   名称匹配正则表达式":                                     "  --match <regex>  Name matches the regular expression",
+	"  --type <类型>    节点类型，例如 ss、vmess":                              "  --type <type>    Node type, e.g. ss, vmess",
+	"  --tag <标签>     包含指定标签":                                        "  --tag <tag>      Has the given tag",
+	"  --failed true    最近一次测速失败":                                    "  --failed true    The last speed test failed",
+	"读取设置失败: %v\n":                                                   "Failed to read settings: %v\n",
+	"多余的参数: %s":                                                      "Extra argument: %s",
+	"proxy bulk %s 失败: %v\n":                                         "proxy bulk %s failed: %v\n",
+	"删除需要指定筛选条件":                                                     "Delete requires a filter",
+	"删除 %d 个节点\n":                                                    "Deleting %d node(s)\n",
+	"重命名需要 --match 和 --replace":                                      "Rename requires --match and --replace",
+	"重命名 %d 个节点\n":                                                   "Renaming %d node(s)\n",
+	"需要指定 --add 或 --remove":                                          "Specify --add or --remove",
+	"已更新 %d 个节点的标签\n":                                                "Updated tags of %d node(s)\n",
+	"未知的操作: %s\n":                                                    "Unknown action: %s\n",
+	"未知的参数: --%s":                                                    "Unknown option: --%s",
+	"保存设置失败: %v\n":                                                   "Failed to save settings: %v\n",
+	"筛选条件: %s，选中 %d 个节点":                                             "Filter: %s, %d node(s) selected",
+	"有尚未保存的修改":                                                       "There are unsaved changes",
+	"批量管理节点":                                                         "Bulk node management",
+	"放弃未保存的修改并返回":                                                    "Discard unsaved changes and go back",
+	"\n直接回车表示不限制":                                                    "\nPress Enter to leave a field unrestricted",
+	"只选择测速失败的节点? [y/N]: ":                                            "Only select nodes whose speed test failed? [y/N]: ",
+	"删除需要先设置筛选条件":                                                    "Set a filter before deleting",
+	"已删除 %d 个节点\n":                                                   "Deleted %d node(s)\n",
+	"要替换的正则表达式: ":                                                    "Regular expression to replace: ",
+	"无效的正则表达式: %w":                                                   "Invalid regular expression: %w",
+	"替换为 (可以使用 $1 引用分组): ":                                           "Replace with ($1 refers to groups): ",
+	"已重命名 %d 个节点\n":                                                  "Renamed %d node(s)\n",
+	"排序方式 name/latency/region [name]: ":                              "Sort by name/latency/region [name]: ",
+	"已排序":               "Sorted",
+	"标签 (多个用逗号分隔): ":    "Tags (comma separated): ",
+	"放弃未保存的修改？":         "Discard unsaved changes?",
+	"配置和节点标签的修改都不会保存":   "Neither the config nor the node tag changes will be saved",
+	"操作失败: %v\n":        "Operation failed: %v\n",
+	"设置筛选条件":            "Set filters",
+	"查看选中的节点":           "Show selected nodes",
+	"删除选中的节点":           "Delete selected nodes",
+	"按正则重命名选中的节点":       "Rename selected nodes by regex",
+	"排序所有节点 (名称/延迟/地区)": "Sort all nodes (name/latency/region)",
+	"为选中的节点添加标签":        "Add tags to selected nodes",
+	"移除选中节点的标签":         "Remove tags from selected nodes",
+	"测速选中的节点":           "Speed test selected nodes",
+	"保存并应用修改":           "Save and apply changes",
+
+	// proxy_core.go
+	"Clash 代理节点管理":                             "Clash proxy node management",
+	"返回主菜单":                                    "Back to main menu",
+	"正在返回主菜单...":                               "Returning to the main menu...",
+	"\n按回车键继续...":                              "\nPress Enter to continue...",
+	"===== 添加新代理节点 =====":                      "===== Add a proxy node =====",
+	"收集代理配置失败: %v\n":                           "Failed to collect proxy config: %v\n",
+	"节点名称 '%s' 已存在，请使用不同的名称\n":                 "Node name '%s' already exists, please use a different name\n",
+	"保存配置文件失败: %v\n":                           "Failed to save config file: %v\n",
+	"\n代理节点 '%s' 已添加！\n":                       "\nProxy node '%s' added!\n",
+	"===== 删除代理节点 =====":                       "===== Delete a proxy node =====",
+	"没有配置任何代理节点":                               "No proxy nodes configured",
+	"删除代理节点":                                   "Delete proxy node",
+	"操作已取消":                                    "Operation cancelled",
+	"\n代理节点 '%s' 已删除！\n":                       "\nProxy node '%s' deleted!\n",
+	"请选择代理类型":                                  "Select the proxy type",
+	"无效的代理类型选择":                                "Invalid proxy type selection",
+	"\n请输入节点名称: ":                              "\nNode name: ",
+	"请输入服务器地址: ":                               "Server address: ",
+	"请输入端口: ":                                  "Port: ",
+	"请输入加密方法(默认为aes-256-gcm): ":                "Cipher (default aes-256-gcm): ",
+	"请输入密码: ":                                  "Password: ",
+	"请输入UUID: ":                                "UUID: ",
+	"请输入alterId(默认为0): ":                       "alterId (default 0): ",
+	"请输入加密方法(默认为auto): ":                       "Cipher (default auto): ",
+	"请输入SNI(可选): ":                             "SNI (optional): ",
+	"服务器地址和端口是必需的":                             "Server address and port are required",
+	"密码是必需的":                                   "Password is required",
+	"UUID是必需的":                                 "UUID is required",
+	"===== 切换代理节点 =====":                       "===== Switch proxy node =====",
+	"当前选中的代理组: ":                               "Current proxy group: ",
+	"当前使用的节点: ":                                "Current node: ",
+	"切换使用的节点":                                  "Switch node",
+	"是否需要先测速再选择节点？":                            "Run a speed test before choosing a node?",
+	"测速将帮助您选择最快的节点":                            "A speed test helps you pick the fastest node",
+	"\n开始测试节点延迟，请稍候...":                        "\nTesting node latency, please wait...",
+	"获取代理延迟信息失败: %v":                           "Failed to get proxy delays: %v",
+	"%s - 延迟: %d ms":                           "%s - latency: %d ms",
+	" - 无法连接":                                  " - unreachable",
+	"\n请输入要设置的代理组名称: ":                         "\nName of the group to switch: ",
+	"切换代理失败: %v\n":                             "Failed to switch proxy: %v\n",
+	"已将代理组 %s 切换到 %s\n":                        "Switched group %s to %s\n",
+	"===== Clash 配置文件内容 =====":                 "===== Clash config file =====",
+	"配置文件: %s\n":                               "Config file: %s\n",
+	"\n--- 配置文件内容开始 ---":                       "\n--- config file begins ---",
+	"--- 配置文件内容结束 ---":                         "--- config file ends ---",
+	"\n是否要将配置文件内容保存到单独的文件？[y/n]: ":             "\nSave the config to a separate file? [y/n]: ",
+	"请输入保存路径(默认为./clash_config_backup.yaml): ": "Save path (default ./clash_config_backup.yaml): ",
+	"保存文件失败: %v\n":                             "Failed to save file: %v\n",
+	"配置文件已保存到 %s\n":                            "Config saved to %s\n",
+	"查看所有节点":                                   "Show all nodes",
+	"添加新节点":                                    "Add a node",
+	"删除节点":                                     "Delete a node",
+	"导入节点":                                     "Import nodes",
+	"查看节点状态和连接速度":                              "Show node status and speed",
+	"输出配置文件内容":                                 "Print the config file",
+	"管理分流规则":                                   "Manage routing rules",
+	"管理代理组":                                    "Manage proxy groups",
+	"停用/启用节点":                                  "Disable/enable nodes",
+	"编辑节点":                                     "Edit a node",
+
+	// proxy_disable.go
+	"节点 %s 仍被规则 %s 使用":            "Node %s is still used by rule %s",
+	"节点 %s 是代理链 %s 的前一跳":          "Node %s is the previous hop of proxy chain %s",
+	"节点 %s 是代理链 %s 的一跳":           "Node %s is a hop of proxy chain %s",
+	"节点 %s 不存在":                   "Node %s does not exist",
+	"停用列表中已有同名节点 %s":              "A node named %s is already disabled",
+	"节点 %s 不在停用列表中":               "Node %s is not in the disabled list",
+	"配置中已存在名为 %s 的节点或代理组":         "A node or group named %s already exists in the config",
+	"代理组 %s 已不存在，跳过\n":            "Group %s no longer exists, skipping\n",
+	"没有停用的节点":                     "No disabled nodes",
+	"共有 %d 个停用的节点:\n\n":           "%d disabled node(s):\n\n",
+	"   停用于 %s":                   "   disabled at %s",
+	"，原代理组: %s":                   ", previous groups: %s",
+	"保存停用列表失败: %w":                "Failed to save the disabled list: %w",
+	"恢复停用列表失败: %v\n":              "Failed to restore the disabled list: %v\n",
+	"配置已保存，但更新停用列表失败: %w":         "Config saved, but updating the disabled list failed: %w",
+	"读取停用列表失败: %v\n":              "Failed to read the disabled list: %v\n",
+	"用法: %s proxy %s <节点名称>...\n": "Usage: %s proxy %s <node>...\n",
+	"proxy %s 失败: %v\n":           "proxy %s failed: %v\n",
+	"===== 停用/启用节点 =====":         "===== Disable/enable nodes =====",
+	"停用节点":                        "Disable nodes",
+	"启用节点":                        "Enable nodes",
+	"返回":                          "Back",
+	"\n当前节点:":                     "\nCurrent nodes:",
+	"\n要停用的节点名称 (多个用逗号分隔): ":      "\nNodes to disable (comma separated): ",
+	"\n要启用的节点名称 (多个用逗号分隔): ":      "\nNodes to enable (comma separated): ",
+
+	// proxy_edit.go
+	"字段名不能为空":                  "Field name must not be empty",
+	"请通过重命名修改节点名称":             "Rename the node to change its name",
+	"%s 不能为空":                  "%s must not be empty",
+	"不能删除字段 %s":                "Field %s cannot be removed",
+	"节点 %s 没有字段 %s":            "Node %s has no field %s",
+	"无效的节点名称: %q":              "Invalid node name: %q",
+	"--set 的格式为 key=value: %s": "--set expects key=value: %s",
+	"没有指定要修改的字段":               "No fields to change",
+	"用法: %s proxy edit <节点名称> --set key=value... [--unset key]...\n": "Usage: %s proxy edit <node> --set key=value... [--unset key]...\n",
+	"\n--set name=<新名称> 重命名节点，并更新代理组和规则中的引用":                         "\n--set name=<new name> renames the node and updates references in groups and rules",
+	"字段值按 YAML 解析，原字段是字符串时保持为字符串":                                    "Values are parsed as YAML; fields that were strings stay strings",
+	"修改节点失败: %v\n":                         "Failed to edit node: %v\n",
+	"===== 编辑代理节点 =====":                   "===== Edit a proxy node =====",
+	"编辑代理节点":                               "Edit proxy node",
+	"===== 编辑代理节点 %s =====\n":              "===== Edit proxy node %s =====\n",
+	"\n直接回车保持当前值":                          "\nPress Enter to keep the current value",
+	"%s: (嵌套字段，请使用 proxy edit --set 修改)\n": "%s: (nested field, use proxy edit --set to change it)\n",
+	"添加其它字段 (key=value，直接回车结束): ":          "Add another field (key=value, Enter to finish): ",
+	"格式应为 key=value":                       "Expected key=value",
+	"没有修改":                                 "No changes",
+	"读取设置失败，节点标签未更新: %v\n":                 "Failed to read settings, node tags not updated: %v\n",
+	"保存节点标签失败: %v\n":                       "Failed to save node tags: %v\n",
+
+	// proxy_fetch.go
+	"无效的代理地址: %s":                                           "Invalid proxy address: %s",
+	"读取 CA 证书失败: %w":                                        "Failed to read CA certificate: %w",
+	"CA 证书文件 %s 中没有有效的证书":                                   "No valid certificates in CA file %s",
+	"第 %d 次重试，等待 %v...\n":                                   "Retry %d, waiting %v...\n",
+	"订阅服务器返回状态码 %d":                                         "The subscription server returned status code %d",
+	"读取设置失败，将使用默认下载选项: %v\n":                                "Failed to read settings, using default download options: %v\n",
+	"是否自定义下载选项(User-Agent/请求头/代理/超时/CA证书)？[y/n]: ":          "Customise download options (User-Agent/headers/proxy/timeout/CA certificate)? [y/n]: ",
+	"是否将这些选项保存为默认设置？[y/n]: ":                                "Save these options as the defaults? [y/n]: ",
+	"下载选项已保存到 %s\n":                                         "Download options saved to %s\n",
+	"%s(当前为%q): ":                                           "%s (currently %q): ",
+	"\n直接回车保留当前值，输入 - 清空":                                   "\nPress Enter to keep the current value, enter - to clear it",
+	"User-Agent (例如 clash.meta)":                            "User-Agent (e.g. clash.meta)",
+	"上游代理 (例如 socks5://127.0.0.1:1080，输入 clash 使用本机 Clash)": "Upstream proxy (e.g. socks5://127.0.0.1:1080, enter clash to use the local Clash)",
+	"CA 证书文件路径":                                             "CA certificate file path",
+	"超时时间，单位秒":                                              "Timeout in seconds",
+	"%s(当前为%d): ":                                           "%s (currently %d): ",
+	"请输入不小于 %d 的整数\n":                                       "Please enter an integer of at least %d\n",
+	"首次重试前的等待时间，单位秒，之后每次翻倍":                                 "Wait before the first retry in seconds, doubled after each retry",
+	"重试次数": "Retries",
+	"请输入额外的请求头，格式为 Key: Value，每行一个，空行结束:": "Enter extra request headers as Key: Value, one per line, empty line to finish:",
+	"格式无效，已忽略": "Invalid format, ignored",
+
+	// proxy_group.go
+	"代理组不能包含自身":                      "A group cannot contain itself",
+	"成员 %s 不是已有的节点、代理组或内置策略":         "Member %s is not an existing node, group or built-in policy",
+	"relay 代理组不能包含内置策略 %s":           "A relay group cannot contain built-in policy %s",
+	"%s 已经是代理组 %s 的成员":               "%s is already a member of group %s",
+	"%s 代理组不使用 url":                  "%s groups do not use url",
+	"无效的测试地址: %s":                    "Invalid test URL: %s",
+	"%s 必须是正整数: %s":                  "%s must be a positive integer: %s",
+	"%s 代理组不使用 interval":             "%s groups do not use interval",
+	"只有 url-test 代理组使用 tolerance":    "Only url-test groups use tolerance",
+	"%s 代理组不使用 lazy":                 "%s groups do not use lazy",
+	"lazy 只能是 true 或 false: %s":      "lazy must be true or false: %s",
+	"只有 load-balance 代理组使用 strategy": "Only load-balance groups use strategy",
+	"不支持的负载均衡策略: %s (可选 consistent-hashing、round-robin、sticky-sessions)": "Unsupported load-balance strategy: %s (choose consistent-hashing, round-robin or sticky-sessions)",
+	"未知的代理组参数: %s":                                    "Unknown group option: %s",
+	"代理组名称不能为空":                                       "Group name must not be empty",
+	"代理组名称不能包含逗号，否则无法在规则中引用":                          "Group name must not contain commas, otherwise rules cannot reference it",
+	"不支持的代理组类型: %s (可选 %s)":                           "Unsupported group type: %s (choose %s)",
+	"代理组至少需要一个成员或 proxy-provider":                     "A group needs at least one member or proxy-provider",
+	"代理组 %s 不存在":                                      "Group %s does not exist",
+	"代理组 %s 仍被 %d 条规则使用: %s":                          "Group %s is still used by %d rule(s): %s",
+	"代理组 %s 是代理链 %s 的前一跳":                             "Group %s is the previous hop of proxy chain %s",
+	"%s 不是代理组 %s 的成员":                                 "%s is not a member of group %s",
+	"代理组 %s 至少需要保留一个成员":                               "Group %s must keep at least one member",
+	"无效的代理组名称: %q":                                    "Invalid group name: %q",
+	"relay 代理组不能包含内置策略 %s，请先移除该成员":                    "A relay group cannot contain built-in policy %s; remove that member first",
+	"group regions 失败: %v\n":                          "group regions failed: %v\n",
+	"group %s 失败: %v\n":                               "group %s failed: %v\n",
+	"未知的 group 子命令: %s\n":                             "Unknown group subcommand: %s\n",
+	"%s 需要指定参数值":                                      "%s requires a value",
+	"用法: %s group <子命令> [参数]\n\n":                     "Usage: %s group <subcommand> [options]\n\n",
+	"  list                                  列出所有代理组": "  list                                  List all groups",
+	"  create <名称> <类型> [成员...] [选项]  创建代理组，成员可以是节点、其它代理组或 DIRECT/REJECT":        "  create <name> <type> [members...] [options]  Create a group; members may be nodes, other groups or DIRECT/REJECT",
+	"  edit <名称> [--name 新名称] [--type 类型] [选项]":                                  "  edit <name> [--name new] [--type type] [options]",
+	"                                        修改代理组，重命名时同步更新规则和其它代理组":             "                                        Edit a group; renaming also updates rules and other groups",
+	"  delete <名称>                         删除代理组，仍被规则使用时拒绝删除":                    "  delete <name>                         Delete a group; refused while rules still use it",
+	"  add-member <名称> <成员...>           添加成员":                                   "  add-member <name> <members...>        Add members",
+	"  remove-member <名称> <成员...>        移除成员":                                   "  remove-member <name> <members...>     Remove members",
+	"  regions [show|enable|update|disable]  按节点名称和 GeoIP 自动生成各地区的 url-test 代理组": "  regions [show|enable|update|disable]  Generate per-region url-test groups from node names and GeoIP",
+	"\n选项:": "\nOptions:",
+	"  --url URL         健康检查地址 (url-test/fallback/load-balance)":                       "  --url URL         Health check URL (url-test/fallback/load-balance)",
+	"  --interval 秒     健康检查间隔":                                                         "  --interval sec    Health check interval",
+	"  --tolerance 毫秒  url-test 切换节点的延迟容差":                                              "  --tolerance ms    Latency tolerance before url-test switches nodes",
+	"  --lazy true|false 未使用时是否跳过健康检查":                                                  "  --lazy true|false Skip health checks while unused",
+	"  --strategy 策略   load-balance 策略: consistent-hashing、round-robin、sticky-sessions": "  --strategy name   load-balance strategy: consistent-hashing, round-robin, sticky-sessions",
+	"  --use a,b         使用的 proxy-providers":                                           "  --use a,b         proxy-providers to use",
+	"\n支持的类型: %s，relay 按成员顺序依次转发\n":                                                     "\nSupported types: %s; relay forwards through members in order\n",
+	"配置文件中没有代理组":                                                                        "No proxy groups in the config file",
+	"共有 %d 个代理组:\n\n":                                                                   "%d group(s):\n\n",
+	"   转发链: %s\n":                                                                      "   chain: %s\n",
+	"   成员: %s\n":                                                                       "   members: %s\n",
+	"   参数: %s\n":                                                                       "   options: %s\n",
+	"\n[名称] 表示嵌套的代理组，* 表示当前选中的成员":                                                       "\n[name] marks a nested group, * marks the selected member",
+	"===== 代理组管理 =====":                                                                 "===== Proxy group management =====",
+	"代理组管理":                                                                             "Proxy group management",
+	"请输入要删除的代理组名称: ":                                                                    "Name of the group to delete: ",
+	"要添加的成员 (多个用逗号分隔): ":                                                                "Members to add (comma separated): ",
+	"要移除的成员 (多个用逗号分隔): ":                                                                "Members to remove (comma separated): ",
+	"请输入要删除的代理链名称: ":                                                                    "Name of the proxy chain to delete: ",
+	"当前类型: ":                                                                            "Current type: ",
+	"保持不变":                                                                              "Keep unchanged",
+	"代理组类型":                                                                             "Group type",
+	"代理组类型: %s\n":                                                                       "Group type: %s\n",
+	"\n代理组名称: ":                                                                         "\nGroup name: ",
+	"无效的代理组类型":                                                                          "Invalid group type",
+	"\n可选的成员:":                                                                          "\nAvailable members:",
+	"成员 (多个用逗号分隔，可以包含 DIRECT): ":                                                        "Members (comma separated, DIRECT allowed): ",
+	"转发链上的节点，按转发顺序用逗号分隔: ":                                                              "Nodes of the chain in forwarding order, comma separated: ",
+	"新名称 [%s]: ":                                                                        "New name [%s]: ",
+	"创建代理组":                                                                             "Create a group",
+	"修改代理组参数":                                                                           "Edit group options",
+	"删除代理组":                                                                             "Delete a group",
+	"添加成员":                                                                              "Add members",
+	"移除成员":                                                                              "Remove members",
+	"启用/停用按地区自动分组":                                                                      "Enable/disable automatic region groups",
+	"创建代理链":                                                                             "Create a proxy chain",
+	"删除代理链":                                                                             "Delete a proxy chain",
+
+	// proxy_import.go
+	"从订阅链接导入":                             "Import from a subscription link",
+	"从Base64编码字符串导入":                      "Import from a Base64 string",
+	"从节点链接(URI)导入":                        "Import from node links (URI)",
+	"从YAML文件导入":                           "Import from a YAML file",
+	"导入代理节点":                              "Import proxy nodes",
+	"===== 从订阅链接导入 =====":                 "===== Import from a subscription link =====",
+	"请输入订阅链接: ":                           "Subscription link: ",
+	"订阅链接不能为空":                            "Subscription link must not be empty",
+	"将订阅节点直接写入配置文件":                       "Write the subscription nodes into the config file",
+	"创建 proxy-provider，由 Clash 自动更新订阅":    "Create a proxy-provider so Clash updates the subscription itself",
+	"选择导入方式":                              "Choose how to import",
+	"订阅链接: ":                              "Subscription link: ",
+	"正在获取订阅内容...":                         "Fetching subscription...",
+	"获取订阅内容失败: %v\n":                      "Failed to fetch subscription: %v\n",
+	"订阅内容中未找到有效的节点链接":                     "No valid node links found in the subscription",
+	"订阅中共解析出 %d 个节点\n":                    "Parsed %d node(s) from the subscription\n",
+	"保存订阅记录失败: %v\n":                      "Failed to save the subscription record: %v\n",
+	"===== 从Base64编码字符串导入 =====":          "===== Import from a Base64 string =====",
+	"请输入Base64编码字符串: ":                    "Base64 string: ",
+	"Base64编码字符串不能为空":                     "Base64 string must not be empty",
+	"解码Base64内容失败: %v\n":                  "Failed to decode Base64 content: %v\n",
+	"解码内容中未找到有效的节点链接":                     "No valid node links found in the decoded content",
+	"===== 从节点链接(URI)导入 =====":            "===== Import from node links (URI) =====",
+	"支持的链接格式: ss://, vmess://, trojan://": "Supported links: ss://, vmess://, trojan://",
+	"可以一次输入多个链接，每行一个":                     "You can enter several links, one per line",
+	"输入完成后，按Ctrl+D(Linux/Mac)或Ctrl+Z(Windows)或Ctrl+C结束输入": "When done, press Ctrl+D (Linux/Mac), Ctrl+Z (Windows) or Ctrl+C to finish",
+	"\n结束输入":                "\nInput finished",
+	"读取输入失败: %v\n":          "Failed to read input: %v\n",
+	"\n未输入任何链接":             "\nNo links entered",
+	"\n共读取到 %d 个链接\n":       "\nRead %d link(s)\n",
+	"===== 从YAML文件导入 =====": "===== Import from a YAML file =====",
+	"请输入YAML文件路径: ":         "YAML file path: ",
+	"读取文件失败: %v\n":          "Failed to read file: %v\n",
+	"解析YAML失败: %v\n":        "Failed to parse YAML: %v\n",
+	"YAML中未找到有效的代理配置":       "No valid proxy config found in the YAML",
+	"跳过无效的代理: %v\n":         "Skipping invalid proxy: %v\n",
+	"跳过已存在的代理: %s\n":        "Skipping existing proxy: %s\n",
+	"已导入代理: %s\n":           "Imported proxy: %s\n",
+	"保存配置失败: %w":            "Failed to save config: %w",
+	"导入已取消":                 "Import cancelled",
+	"\n导入完成: 成功导入 %d 个代理，跳过 %d 个代理\n": "\nImport finished: %d imported, %d skipped\n",
+	"读取配置失败: %v\n":   "Failed to read config: %v\n",
+	"处理节点 %d/%d: ":   "Processing node %d/%d: ",
+	"跳过不支持的协议: %s\n": "Skipping unsupported protocol: %s\n",
+	"解析失败: %v\n":     "Failed to parse: %v\n",
+	"已导入: %s\n":      "Imported: %s\n",
+	"\n导入完成: 成功导入 %d 个代理，跳过 %d 个代理，错误 %d 个\n": "\nImport finished: %d imported, %d skipped, %d errors\n",
+
+	// proxy_list.go
+	"测速中":    "testing",
+	"失败":     "failed",
+	"所有代理节点": "All proxy nodes",
+	"搜索: ":   "Search: ",
+	"第 %d/%d 页，显示 %d/%d 个节点，* 表示当前使用的节点": "Page %d/%d, showing %d/%d nodes, * marks the node in use",
+	"，正在测速 %d/%d": ", testing %d/%d",
+	"输入文字搜索  ↑↓ 移动  ←→/PgUp/PgDn 翻页  Tab 切换排序列  Ctrl-R 反向排序  Ctrl-T 测速  回车查看详情  Esc 返回": "Type to search  ↑↓ move  ←→/PgUp/PgDn page  Tab sort column  Ctrl-R reverse  Ctrl-T speed test  Enter details  Esc back",
+	"类型: ":     "Type: ",
+	"服务器: ":    "Server: ",
+	"按任意键返回列表": "Press any key to return to the list",
+	"测速完成，%d 个节点可用，保存结果失败: %v": "Speed test finished, %d node(s) reachable; failed to save the results: %v",
+	"测速完成，%d/%d 个节点可用":         "Speed test finished, %d/%d node(s) reachable",
+	"===== 所有代理节点 =====":       "===== All proxy nodes =====",
+	"正在读取节点信息...":              "Reading nodes...",
+	"名称":                       "Name",
+	"类型":                       "Type",
+	"服务器":                      "Server",
+	"端口":                       "Port",
+	"延迟":                       "Latency",
+	"地区":                       "Region",
+	"订阅":                       "Subscription",
+	"代理组":                      "Groups",
+
+	// proxy_provider.go
+	"列出 proxy-providers 失败: %v\n":                                     "Failed to list proxy-providers: %v\n",
+	"刷新 proxy-providers 失败: %v\n":                                     "Failed to refresh proxy-providers: %v\n",
+	"未知的 providers 子命令: %s\n":                                         "Unknown providers subcommand: %s\n",
+	"用法: %s providers <子命令> [参数]\n\n":                                 "Usage: %s providers <subcommand> [options]\n\n",
+	"  list               列出配置中的 proxy-providers":                     "  list               List the proxy-providers in the config",
+	"  refresh [名称...]  通过 Clash API 刷新指定的 proxy-provider，不指定名称时刷新全部": "  refresh [name...]  Refresh proxy-providers through the Clash API; refreshes all when no name is given",
+	"读取配置文件失败: %w":                                                    "Failed to read config file: %w",
+	"配置文件中没有 proxy-providers":                                         "No proxy-providers in the config file",
+	"共找到 %d 个 proxy-provider:\n\n":                                    "Found %d proxy-provider(s):\n\n",
+	"名称: %s\n":                                                        "Name: %s\n",
+	"   类型: %s\n":                                                     "   type: %s\n",
+	"   订阅地址: %s\n":                                                   "   url: %s\n",
+	"   更新间隔: %d 秒\n":                                                 "   interval: %d seconds\n",
+	"   缓存路径: %s\n":                                                   "   cache path: %s\n",
+	"正在刷新 %s...":                                                      "Refreshing %s...",
+	"失败: %v\n":                                                        "failed: %v\n",
+	"成功":                                                              "OK",
+	"%d 个 proxy-provider 刷新失败":                                        "%d proxy-provider(s) failed to refresh",
+	"请输入 proxy-provider 名称(默认为%s): ":                                  "proxy-provider name (default %s): ",
+	"请输入更新间隔，单位秒(默认为3600): ":                                          "Update interval in seconds (default 3600): ",
+	"添加 proxy-provider 失败: %v\n":                                      "Failed to add proxy-provider: %v\n",
+	"\nproxy-provider '%s' 已添加，节点将由 Clash 从订阅地址自动拉取\n":                "\nproxy-provider '%s' added; Clash will fetch its nodes from the subscription URL\n",
+	"提示: Clash Premium 内核要求订阅返回 Clash 格式的 YAML，Base64 节点列表需要 Meta 内核": "Note: the Clash Premium core needs subscriptions to return Clash YAML; Base64 node lists require the Meta core",
+	"proxy-provider 名称不能为空":   "proxy-provider name must not be empty",
+	"proxy-provider '%s' 已存在": "proxy-provider '%s' already exists",
+	"创建 providers 目录失败: %w":   "Failed to create the providers directory: %w",
+
+	// proxy_speed.go
+	"===== 代理节点状态 =====":   "===== Proxy node status =====",
+	"Clash服务状态: %s\n\n":    "Clash service: %s\n\n",
+	"Clash服务未运行，无法获取代理状态":  "The Clash service is not running, cannot get proxy status",
+	"请启动Clash服务后再试":        "Start the Clash service and try again",
+	"Clash API状态: %s\n":    "Clash API: %s\n",
+	"无法连接到Clash API，请检查配置": "Cannot connect to the Clash API, please check the config",
+	"API配置检查结果: %v\n":      "API config check: %v\n",
+	"\n正在尝试诊断API连接问题...":   "\nDiagnosing the API connection...",
+	"诊断结果: %v\n":           "Diagnosis: %v\n",
+	"Clash API状态: %s\n\n":  "Clash API: %s\n\n",
+	"获取当前选中代理信息失败: %v\n":   "Failed to get the selected proxy: %v\n",
+	"当前选中的代理组: %s\n":       "Current proxy group: %s\n",
+	"当前使用的节点: %s\n\n":      "Current node: %s\n\n",
+	"获取代理列表失败: %v\n":       "Failed to get the proxy list: %v\n",
+	"没有找到任何代理节点":           "No proxy nodes found",
+	"代理节点状态":               "Proxy node status",
+	"是否需要测试节点延迟？":          "Test node latency?",
+	"测速将耗费一些时间":            "The speed test takes a while",
+	"\n获取代理延迟信息失败: %v\n":   "\nFailed to get proxy delays: %v\n",
+	"\n节点延迟测试结果:":          "\nLatency results:",
+	" (来源: %s)":            " (source: %s)",
+	"节点 %s 延迟: %d ms%s\n":  "Node %s latency: %d ms%s\n",
+	"节点 %s 无法连接\n":         "Node %s unreachable\n",
+	"节点 %s 未测试\n":          "Node %s not tested\n",
+	"选择测速方式":               "Choose a speed test method",
+	"使用直连模式？":              "Use direct mode?",
+	"使用直连模式测速可能会提高准确性，但会临时断开当前连接。": "Testing in direct mode may be more accurate, but temporarily interrupts current connections.",
+	"无法获取代理信息":                      "Cannot get proxy information",
+	"切换组 %s 到直连模式失败: %v\n":          "Failed to switch group %s to direct mode: %v\n",
+	"已将组 %s 切换到直连模式\n":              "Switched group %s to direct mode\n",
+	"警告: 无法获取当前代理设置，将不使用直连模式":       "Warning: cannot get the current proxy settings, not using direct mode",
+	"临时切换到直连模式进行测速...":              "Temporarily switching to direct mode for the speed test...",
+	"警告: 切换到直连模式失败: %v，将使用当前代理测速\n": "Warning: failed to switch to direct mode: %v, testing through the current proxy\n",
+	"恢复原始代理设置 (%s -> %s)...\n":      "Restoring the original proxy (%s -> %s)...\n",
+	"恢复原始代理失败: %v\n":                "Failed to restore the original proxy: %v\n",
+	"已恢复原始代理设置":                     "Original proxy restored",
+	"测试代理 %d/%d: %s\n":              "Testing proxy %d/%d: %s\n",
+	"请求失败: %v\n":                    "Request failed: %v\n",
+	"读取配置失败: %w":                    "Failed to read config: %w",
+	"配置中未找到代理列表":                    "No proxy list found in the config",
+	"\n使用简化方法测试节点连接...":             "\nTesting node connections with the simple method...",
+	"测试代理链 %d/%d: %s\n":             "Testing proxy chain %d/%d: %s\n",
+	"  代理链延迟: %d ms\n":              "  chain latency: %d ms\n",
+	"  代理链测试失败\n":                   "  chain test failed\n",
+	"无法找到代理 %s 的配置\n":               "Cannot find the config of proxy %s\n",
+	"无法获取服务器地址\n":                   "Cannot get the server address\n",
+	"无法获取端口\n":                      "Cannot get the port\n",
+	"  平均延迟: %d ms\n":               "  average latency: %d ms\n",
+	"  连接失败\n":                      "  connection failed\n",
+	"\n使用可靠方法测试节点连接...":             "\nTesting node connections with the reliable method...",
+	"测试节点 %d/%d: %s\n":              "Testing node %d/%d: %s\n",
+	"  代理链测试失败，请确认 Clash 服务正在运行":    "  chain test failed, make sure the Clash service is running",
+	"  无法获取节点IP: %v\n":              "  cannot get the node IP: %v\n",
+	"  服务器 IP: %s\n":                "  server IP: %s\n",
+	"  ICMP Ping 延迟: %d ms\n":       "  ICMP ping latency: %d ms\n",
+	"  ICMP Ping 失败或不可用":            "  ICMP ping failed or unavailable",
+	"  TCP 连接延迟: %d ms\n":           "  TCP connect latency: %d ms\n",
+	"  TCP 连接测试失败":                  "  TCP connect test failed",
+	"  所有连接测试均失败":                   "  all connection tests failed",
+	"未找到代理 %s":                      "Proxy %s not found",
+	"代理配置中未找到服务器信息":                 "No server in the proxy config",
+	"解析服务器域名失败: %w":                 "Failed to resolve the server domain: %w",
+	"未找到服务器IP":                      "No server IP found",
+	"  Ping失败: %v\n":                "  ping failed: %v\n",
+	"使用 Clash API 测速":               "Test through the Clash API",
+	"使用简化方法测速 (直接测试节点连接)":           "Simple test (connect to nodes directly)",
+	"使用可靠方法测速 (推荐)":                 "Reliable test (recommended)",
+
+	// proxy_subscription.go
+	"列出订阅失败: %v\n":                       "Failed to list subscriptions: %v\n",
+	"刷新订阅失败: %v\n":                       "Failed to refresh subscriptions: %v\n",
+	"未知的 subscription 子命令: %s\n":         "Unknown subscription subcommand: %s\n",
+	"用法: %s subscription <子命令> [参数]\n\n": "Usage: %s subscription <subcommand> [options]\n\n",
+	"  list               列出已导入的订阅及缓存状态": "  list               List imported subscriptions and cache status",
+	"  refresh [名称...]  重新下载订阅并替换对应节点，不指定名称时刷新全部": "  refresh [name...]  Download subscriptions again and replace their nodes; refreshes all when no name is given",
+	"还没有导入任何订阅":                         "No subscriptions imported yet",
+	"   节点数量: %d\n":                     "   nodes: %d\n",
+	"   上次更新: %s\n":                     "   last update: %s\n",
+	"   本地缓存: %s 下载，%d 个节点\n":           "   local cache: downloaded %s, %d nodes\n",
+	"   本地缓存: 无":                        "   local cache: none",
+	"\n正在刷新订阅 %s...\n":                  "\nRefreshing subscription %s...\n",
+	"刷新失败: %v\n":                        "Refresh failed: %v\n",
+	"未找到订阅: %s\n":                       "Subscription not found: %s\n",
+	"保存订阅记录失败: %w":                      "Failed to save the subscription record: %w",
+	"\n节点没有变化，配置未修改":                    "\nNodes unchanged, config not modified",
+	"%d 个订阅刷新失败":                        "%d subscription(s) failed to refresh",
+	"订阅中没有有效节点，保留现有的 %d 个节点":            "No valid nodes in the subscription, keeping the existing %d nodes",
+	"跳过与其他节点重名的节点: %s\n":                "Skipping node with the same name as another node: %s\n",
+	"订阅 %s: 共 %d 个节点，新增 %d 个，移除 %d 个\n": "Subscription %s: %d nodes, %d added, %d removed\n",
+	"请输入订阅名称，用于之后刷新(默认为%s): ":           "Subscription name, used for later refreshes (default %s): ",
+	"下载订阅失败 (%v)，使用 %s 的本地缓存\n":         "Failed to download the subscription (%v), using the local cache from %s\n",
+	"服务器返回 304，但本地没有缓存":                 "The server returned 304, but there is no local cache",
+	"订阅内容未变化，使用本地缓存":                    "Subscription unchanged, using the local cache",
+	"订阅内容中没有有效节点":                       "No valid nodes in the subscription",
+	"订阅返回了 HTML 页面，可能是错误页":              "The subscription returned an HTML page, probably an error page",
+	"%s，使用 %s 的本地缓存\n":                  "%s, using the local cache from %s\n",
+	"警告: 写入订阅缓存失败: %v\n":                "Warning: failed to write the subscription cache: %v\n",
+
+	// proxy_uri.go
+	"不是有效的Shadowsocks URI":   "Not a valid Shadowsocks URI",
+	"无效的方法和密码格式":             "Invalid method and password format",
+	"无效的主机和端口格式":             "Invalid host and port format",
+	"解码Base64失败: %w":         "Failed to decode Base64: %w",
+	"无效的URI格式":               "Invalid URI format",
+	"不是有效的VMess URI":         "Not a valid VMess URI",
+	"VMess配置缺少地址字段":          "VMess config is missing the address field",
+	"VMess配置缺少端口字段":          "VMess config is missing the port field",
+	"VMess配置缺少ID字段":          "VMess config is missing the ID field",
+	"不是有效的Trojan URI":        "Not a valid Trojan URI",
+	"无效的Trojan URI格式，缺少@分隔符": "Invalid Trojan URI, missing the @ separator",
+	"无效的服务器地址和端口格式":          "Invalid server address and port format",
+
+	// proxy_utils.go
+	"未找到选中的代理":  "No selected proxy found",
+	"未找到可用的代理组": "No usable proxy group found",
+	"无法获取代理列表":  "Cannot get the proxy list",
+	"配置文件中未找到 external-controller 设置或设置为空": "external-controller is not set or empty in the config file",
+	"提示: API地址为 %s\n":                      "Note: API address: %s\n",
+	"提示: API仅允许本地访问":                       "Note: the API only accepts local connections",
+	"提示: API允许所有网络接口访问":                    "Note: the API accepts connections on all interfaces",
+	"提示: API已设置访问密钥":                       "Note: the API has a secret set",
+	"提示: API未设置访问密钥，可能存在安全风险":              "Note: the API has no secret set, which may be a security risk",
+	"提示: 已配置Web UI，路径为: %s\n":              "Note: Web UI configured at: %s\n",
+	"提示: 未配置Web UI":                        "Note: no Web UI configured",
+	"正在检查Clash API端点:":                     "Checking Clash API endpoints:",
+	"测试端点: %s...":                          "Testing endpoint: %s...",
+	"提示: 请确认配置文件中的 secret 与 Clash 正在使用的一致": "Note: make sure the secret in the config file matches the one Clash is using",
+	"API检查完成，所有端点都可以访问":                    "API check finished, all endpoints are reachable",
+	"配置已保存到 %s，该文件不是 Clash 服务加载的配置，跳过重启\n": "Config saved to %s; this file is not the config loaded by the Clash service, skipping restart\n",
+	"重启 Clash": "Restart Clash",
+	"是否需要重启Clash服务来应用更改?": "Restart the Clash service to apply the changes?",
+	"配置已保存到 ":             "Config saved to ",
+	"重启Clash服务失败: %v\n":   "Failed to restart the Clash service: %v\n",
+	"运行中":                 "running",
+	"已停止":                 "stopped",
+	"可用":                  "available",
+	"不可用":                 "unavailable",
+
+	// region_group.go
+	"无法使用 GeoIP 识别地区: %v\n":                                "Cannot use GeoIP to detect regions: %v\n",
+	"已存在名为 %s 的代理组或节点，跳过该地区\n":                             "A group or node named %s already exists, skipping this region\n",
+	"%s 已没有节点，但仍被规则使用，暂时只包含 DIRECT\n":                      "%s has no nodes left but is still used by rules; it contains only DIRECT for now\n",
+	"已存在名为 %s 的代理组或节点，跳过地区选择组\n":                           "A group or node named %s already exists, skipping the region selector group\n",
+	"\n正在按地区重新生成代理组...":                                    "\nRegenerating region groups...",
+	"保存地区分组设置失败: %v\n":                                     "Failed to save region group settings: %v\n",
+	"按地区分组未启用，请先执行 group regions enable":                   "Region groups are not enabled; run group regions enable first",
+	"未知的 regions 操作: %s (可选 show、enable、update、disable)":   "Unknown regions action: %s (choose show, enable, update or disable)",
+	"保存地区分组设置失败: %w":                                       "Failed to save region group settings: %w",
+	"已将 %s 加入代理组 %s\n":                                     "Added %s to group %s\n",
+	"\n按地区分组已启用，将停用并删除生成的地区代理组":                            "\nRegion groups are enabled; they will be disabled and the generated region groups deleted",
+	"\n将按节点名称和 GeoIP 生成各地区的 url-test 代理组，之后导入节点或刷新订阅时自动更新": "\nurl-test groups will be generated per region from node names and GeoIP, and updated automatically when importing nodes or refreshing subscriptions",
+	"代理组 %s 仍被规则 %s 使用，请先修改规则":                             "Group %s is still used by rule %s; change the rule first",
+	"%s: %d 个节点\n": "%s: %d node(s)\n",
+	"\n按地区分组: 已启用，导入节点或刷新订阅时自动更新":             "\nRegion groups: enabled, updated automatically when importing nodes or refreshing subscriptions",
+	"\n按地区分组: 未启用，使用 group regions enable 启用": "\nRegion groups: disabled, enable them with group regions enable",
+
+	// resources.go
+	"正在详细记录嵌入资源提取过程...": "Logging the extraction of embedded resources in detail...",
+	"开始读取嵌入的资源...":      "Reading embedded resources...",
+	"开始解压资源到临时目录...":    "Extracting resources to a temporary directory...",
+	"验证提取的文件...":        "Verifying extracted files...",
+	"安装脚本未找到: %s":       "Installer script not found: %s",
+
+	// rule_provider.go
+	"未知的 ruleset 子命令: %s\n":                                                          "Unknown ruleset subcommand: %s\n",
+	"ruleset %s 失败: %v\n":                                                            "ruleset %s failed: %v\n",
+	"用法: %s ruleset <子命令> [参数]\n\n":                                                  "Usage: %s ruleset <subcommand> [options]\n\n",
+	"  list                          列出 rule-providers 及其来源":                         "  list                          List rule-providers and their sources",
+	"  add <名称> <文件|URL> [选项]  将域名/IP 列表转换为 rule-provider":                           "  add <name> <file|URL> [opts]  Convert a domain/IP list into a rule-provider",
+	"      --format auto|plain|adblock|gfwlist|clash  来源格式，默认自动识别":                   "      --format auto|plain|adblock|gfwlist|clash  source format, detected automatically by default",
+	"      --behavior domain|ipcidr|classical         规则集类型，默认根据内容推断":                "      --behavior domain|ipcidr|classical         rule set type, inferred from the content by default",
+	"      --type file|http          file 由本工具转换并刷新，http 由 Clash 直接下载 (仅限 clash 格式)": "      --type file|http          file is converted and refreshed by this tool, http is downloaded by Clash (clash format only)",
+	"      --interval 秒             http 类型的更新间隔，默认 86400":                           "      --interval sec            update interval of http rule sets, default 86400",
+	"      --policy 策略             同时添加 RULE-SET,<名称>,<策略> 规则":                       "      --policy policy           also add a RULE-SET,<name>,<policy> rule",
+	"      --position N              RULE-SET 规则的插入位置，默认在 MATCH 之前":                  "      --position N              position of the RULE-SET rule, default before MATCH",
+	"  remove <名称>                 删除 rule-provider、引用它的规则和规则集文件":                    "  remove <name>                 Delete a rule-provider, the rules using it and its file",
+	"  refresh [名称...]             重新下载并转换规则集，通知 Clash 重新加载":                         "  refresh [name...]             Download and convert rule sets again and tell Clash to reload them",
+	"  timer enable [OnCalendar]     启用 systemd 定时刷新，默认 daily":                       "  timer enable [OnCalendar]     Enable a systemd refresh timer, default daily",
+	"  timer disable|status          停用定时刷新或查看状态":                                    "  timer disable|status          Disable the refresh timer or show its status",
+	"\n纯文本列表中的裸域名按后缀匹配处理，full: 前缀表示完整域名":                                             "\nBare domains in plain lists match as suffixes; the full: prefix means an exact domain",
+	"规则集文件保存在 %s\n":                                                                  "Rule set files are stored in %s\n",
+	"名称不能为空":                                                                         "Name must not be empty",
+	"名称 %q 不能包含逗号、空格或路径分隔符":                                                          "Name %q must not contain commas, spaces or path separators",
+	"配置文件中没有 rule-providers":                                                         "No rule-providers in the config file",
+	"共找到 %d 个 rule-provider:\n\n":                                                    "Found %d rule-provider(s):\n\n",
+	"   类型: %s, behavior: %s\n":                                                      "   type: %s, behavior: %s\n",
+	"   下载地址: %s\n":                                                                  "   url: %s\n",
+	"   文件路径: %s\n":                                                                  "   file path: %s\n",
+	"   导入来源: %s (%s)\n":                                                             "   source: %s (%s)\n",
+	"   条目数量: %d\n":                                                                  "   entries: %d\n",
+	"   导入来源: 配置文件中手动添加":                                                             "   source: added manually in the config file",
+	"   引用规则: 无":                                                                     "   used by rules: none",
+	"   引用规则: %s\n":                                                                  "   used by rules: %s\n",
+	"%s 的值无效: %s":                                                                    "Invalid value for %s: %s",
+	"未知的参数: %s":                                                                      "Unknown option: %s",
+	"不支持的格式: %s (可选 auto、%s)":                                                        "Unsupported format: %s (choose auto, %s)",
+	"不支持的 behavior: %s (可选 domain、ipcidr、classical)":                                 "Unsupported behavior: %s (choose domain, ipcidr or classical)",
+	"不支持的类型: %s (可选 file、http)":                                                      "Unsupported type: %s (choose file or http)",
+	"http 类型的来源必须是 http(s) 地址":                                                       "The source of an http rule set must be an http(s) URL",
+	"rule-provider %s 已存在":                                                           "rule-provider %s already exists",
+	"正在读取 %s...\n":                                                                   "Reading %s...\n",
+	"识别到的格式: %s\n":                                                                   "Detected format: %s\n",
+	"http 类型由 Clash 直接下载，只支持 clash 格式的来源，%s 格式请使用 --type file":                       "http rule sets are downloaded by Clash and only support clash format sources; use --type file for %s format",
+	"来源中没有可用于 %s 规则集的条目":                                                             "The source has no entries usable for a %s rule set",
+	"已转换 %d 条 %s 条目":                                                                 "Converted %d %s entries",
+	"，跳过 %d 条不适用于该类型的条目":                                                             ", skipped %d entries not applicable to this type",
+	"保存规则集记录失败: %w":                                                                  "Failed to save the rule set record: %w",
+	"\n提示: 使用 rules add RULE-SET,%s,<策略> 引用该规则集\n":                                   "\nTip: use rules add RULE-SET,%s,<policy> to reference this rule set\n",
+	"rule-provider %s 不存在":                                                           "rule-provider %s does not exist",
+	"删除规则集文件失败: %v\n":                                                                "Failed to delete the rule set file: %v\n",
+	"还没有通过 ruleset add 导入任何规则集":                                                      "No rule sets have been imported with ruleset add yet",
+	"\n正在刷新规则集 %s...\n":                                                              "\nRefreshing rule set %s...\n",
+	"配置文件中已没有该 rule-provider，跳过":                                                     "The rule-provider is no longer in the config file, skipping",
+	"内容没有变化":                                                                         "Content unchanged",
+	"通知 Clash 重新加载失败: %v，将在下次启动时生效\n":                                                "Failed to tell Clash to reload: %v; it will take effect on the next start\n",
+	"Clash 已重新加载该规则集":                                                                "Clash reloaded the rule set",
+	"未找到规则集: %s\n":                                                                   "Rule set not found: %s\n",
+	"%d 个规则集刷新失败":                                                                    "%d rule set(s) failed to refresh",
+	"来源中没有可用的条目，保留现有的 %d 条":                                                          "The source has no usable entries, keeping the existing %d",
+	"创建规则集目录失败: %w":                                                                  "Failed to create the rule set directory: %w",
+	"写入规则集文件失败: %w":                                                                  "Failed to write the rule set file: %w",
+	"条目数量: %d -> %d\n":                                                               "Entries: %d -> %d\n",
+	"下载 %s 失败: %w":                                                                   "Failed to download %s: %w",
+	"无法解码 gfwlist，内容不是有效的 base64":                                                    "Cannot decode gfwlist, the content is not valid base64",
+	"解析 clash 规则集失败: %w":                                                             "Failed to parse the clash rule set: %w",
+	"不支持的格式: %s":                                                                     "Unsupported format: %s",
+	"未知的 timer 操作: %s (可选 enable、disable、status)":                                    "Unknown timer action: %s (choose enable, disable or status)",
+	"无效的执行计划: %q":                                                                    "Invalid schedule: %q",
+	"无效的执行计划 %s: %s":                                                                 "Invalid schedule %s: %s",
+	"获取程序路径失败: %w":                                                                   "Failed to get the program path: %w",
+	"写入 systemd 服务文件失败: %w":                                                          "Failed to write the systemd service file: %w",
+	"写入 systemd 定时器文件失败: %w":                                                         "Failed to write the systemd timer file: %w",
+	"重新加载 systemd 配置失败: %w, %s":                                                      "Failed to reload systemd: %w, %s",
+	"启用定时器失败: %w, %s":                                                                "Failed to enable the timer: %w, %s",
+	"已启用定时刷新 (%s)，执行计划: %s\n":                                                        "Enabled scheduled refresh (%s), schedule: %s\n",
+	"定时刷新未启用":                                                                        "Scheduled refresh is not enabled",
+	"停用定时器失败: %v, %s\n":                                                              "Failed to disable the timer: %v, %s\n",
+	"删除 %s 失败: %w":                                                                   "Failed to delete %s: %w",
+	"已停用定时刷新":                                                                        "Scheduled refresh disabled",
+	"定时刷新: 未启用":                                                                      "Scheduled refresh: disabled",
+	"定时刷新: 已启用":                                                                      "Scheduled refresh: enabled",
+	"   %s: %d 条，上次更新 %s\n":                                                          "   %s: %d entries, last updated %s\n",
+
+	// rule_sim.go
+	"请指定要测试的域名或 IP":              "Specify a domain or IP to test",
+	"无效的 URL: %s":                "Invalid URL: %s",
+	"无法读取 Country.mmdb: %v":      "Cannot read Country.mmdb: %v",
+	"未指定端口，可使用 --port 指定":        "No port given, use --port to specify one",
+	"依赖连接来源，无法在本地判断":             "depends on the connection source, cannot be decided locally",
+	"需要 GeoSite 数据库，无法在本地判断":     "needs the GeoSite database, cannot be decided locally",
+	"需要 ASN 数据库，无法在本地判断":         "needs an ASN database, cannot be decided locally",
+	"取决于连接使用 TCP 还是 UDP，无法在本地判断": "depends on whether the connection uses TCP or UDP, cannot be decided locally",
+	"不支持模拟 %s 规则":                "simulating %s rules is not supported",
+	"无法判断子规则 %s":                 "cannot decide sub-rule %s",
+	"rule-provider %s 没有本地文件":    "rule-provider %s has no local file",
+	"读取 rule-provider %s 失败: %w": "Failed to read rule-provider %s: %w",
+	"配置文件":                       "config file",
+	"代理组 %s (%s) 由 Clash 根据测速或负载均衡选择节点，Clash 未运行时无法确定": "Group %s (%s) picks nodes by speed test or load balancing; cannot be determined while Clash is not running",
+	"--port 需要指定端口":        "--port requires a port",
+	"只能指定一个域名或 IP":         "Only one domain or IP can be given",
+	"目标: %s":               "Target: %s",
+	" (端口 %d)":             " (port %d)",
+	"DNS 解析失败: %v\n":       "DNS resolution failed: %v\n",
+	"DNS 解析: %s (来自 %s)\n": "DNS resolution: %s (from %s)\n",
+	"IP 归属: %s\n":          "IP location: %s\n",
+	"\n以下规则无法在本地判断，已跳过:":   "\nThe following rules cannot be decided locally and were skipped:",
+	"\n没有命中任何规则，Clash 将直接连接 (DIRECT)": "\nNo rule matched; Clash will connect directly (DIRECT)",
+	"\n命中规则: 第 %d 条 %s\n":             "\nMatched rule: #%d %s\n",
+	"策略: %s\n":                        "Policy: %s\n",
+	"当前节点: %s (来自%s)\n":               "Current node: %s (from %s)\n",
+
+	// rules.go
+	"缺少目标策略":                                 "Missing target policy",
+	"格式应为 类型,内容,策略":                          "Expected type,content,policy",
+	"%s 规则的内容无效: %w":                         "Invalid content for %s rule: %w",
+	"逻辑规则至少需要一条子规则":                          "A logical rule needs at least one sub-rule",
+	"NOT 规则只能包含一条子规则":                        "A NOT rule can contain only one sub-rule",
+	"子规则 (%s): %w":                           "Sub-rule (%s): %w",
+	"不支持的规则类型 %s":                            "Unsupported rule type %s",
+	"逻辑规则的内容应为 ((类型,内容),...): %s":            "Logical rule content should be ((type,content),...): %s",
+	"内容不能为空":                                 "Content must not be empty",
+	"域名不能为空":                                 "Domain must not be empty",
+	"域名中不能包含空格或 /: %s":                       "Domain must not contain spaces or /: %s",
+	"无效的 CIDR: %s":                           "Invalid CIDR: %s",
+	"IP-CIDR6 需要 IPv6 地址段: %s":               "IP-CIDR6 requires an IPv6 range: %s",
+	"国家代码不能为空":                               "Country code must not be empty",
+	"无效的国家代码: %s":                            "Invalid country code: %s",
+	"无效的端口或端口范围: %s":                         "Invalid port or port range: %s",
+	"正则表达式不能为空":                              "Regular expression must not be empty",
+	"无效的正则表达式: %v":                           "Invalid regular expression: %v",
+	"网络类型只能是 TCP 或 UDP: %s":                  "Network must be TCP or UDP: %s",
+	"rule-provider 名称不能为空":                   "rule-provider name must not be empty",
+	"目标 %s 不是已有的代理组、节点或 DIRECT/REJECT":       "Target %s is not an existing group, node or DIRECT/REJECT",
+	"已存在 MATCH 规则 (第 %d 条)，请先删除":             "A MATCH rule already exists (#%d); delete it first",
+	"位置 %d 超出范围，规则必须位于 MATCH 之前 (1-%d)":      "Position %d out of range; rules must come before MATCH (1-%d)",
+	"规则序号超出范围: %d (共 %d 条)":                  "Rule index out of range: %d (%d in total)",
+	"无效的规则: %w":                              "Invalid rule: %w",
+	"未找到规则: %s":                              "Rule not found: %s",
+	"规则序号超出范围 (共 %d 条)":                      "Rule index out of range (%d in total)",
+	"MATCH 规则必须位于最后，不能移动":                    "The MATCH rule must stay last and cannot be moved",
+	"规则只能移动到 MATCH 之前 (1-%d)":                "Rules can only be moved before MATCH (1-%d)",
+	"rules test 失败: %v\n":                    "rules test failed: %v\n",
+	"无效的位置: %s\n":                            "Invalid position: %s\n",
+	"rules move 需要两个规则序号":                    "rules move requires two rule indexes",
+	"未知的 rules 子命令: %s\n":                    "Unknown rules subcommand: %s\n",
+	"rules %s 失败: %v\n":                      "rules %s failed: %v\n",
+	"用法: %s rules <子命令> [参数]\n\n":            "Usage: %s rules <subcommand> [options]\n\n",
+	"  list                          列出所有规则": "  list                          List all rules",
+	"  add <规则> [--position N]     添加规则，例如 DOMAIN-SUFFIX,example.com,DIRECT": "  add <rule> [--position N]     Add a rule, e.g. DOMAIN-SUFFIX,example.com,DIRECT",
+	"                                默认添加在 MATCH 之前，--position 指定插入位置":       "                                added before MATCH by default; --position sets the position",
+	"  remove <序号|规则>            删除规则":                                       "  remove <index|rule>           Delete a rule",
+	"  move <序号> <新位置>          调整规则顺序，MATCH 始终保持在最后":                        "  move <index> <new position>   Reorder rules; MATCH always stays last",
+	"  test <域名|IP|URL> [--port N] 在本地模拟规则匹配，显示命中的规则、策略和当前节点":                "  test <domain|IP|URL> [--port N] Simulate rule matching locally, showing the matched rule, policy and current node",
+	"\n支持的规则类型: %s\n":    "\nSupported rule types: %s\n",
+	"配置文件中没有规则":          "No rules in the config file",
+	"共有 %d 条规则:\n\n":     "%d rule(s):\n\n",
+	"%3d. %s (无效: %v)\n": "%3d. %s (invalid: %v)\n",
+	"===== 规则管理 =====":   "===== Rule management =====",
+	"添加规则":               "Add a rule",
+	"删除规则":               "Delete a rule",
+	"调整规则顺序":             "Reorder rules",
+	"规则管理":               "Rule management",
+	"\n请输入要删除的规则序号: ":    "\nIndex of the rule to delete: ",
+	"\n请输入要移动的规则序号: ":    "\nIndex of the rule to move: ",
+	"请输入新的位置: ":          "New position: ",
+	"规则序号必须是数字":          "Rule index must be a number",
+	"选择规则类型":             "Choose a rule type",
+	"规则类型: %s\n":         "Rule type: %s\n",
+	"请输入匹配内容 (域名、CIDR、国家代码、进程名、端口或 rule-provider 名称): ": "Match content (domain, CIDR, country code, process name, port or rule-provider name): ",
+	"\n目标策略:": "\nTarget policy:",
+	"请选择目标策略序号，或直接输入节点名称: ":                "Enter the number of the target policy, or a node name: ",
+	"是否添加 no-resolve (不为域名解析 IP)? [y/n]: ": "Add no-resolve (do not resolve domains to IPs)? [y/n]: ",
+	"请输入插入位置 (直接回车则放在 MATCH 之前): ":         "Insert position (press Enter to place before MATCH): ",
+
+	// settings.go
+	"解析设置文件 %s 失败: %w": "Failed to parse settings file %s: %w",
+
+	// terminal.go
+	"标准输入不是终端: %w": "Standard input is not a terminal: %w",
+
+	// tui.go
+	" 正在检查 Clash 状态...": " Checking Clash status...",
+	" | 配置: ":           " | config: ",
+	"\x1b[2m… 还有 %d 行，PgUp/PgDn 滚动\x1b[0m": "\x1b[2m… %d more line(s), PgUp/PgDn to scroll\x1b[0m",
+	"↑↓ 移动  回车确认  数字键选择  Esc/q 返回":         "↑↓ move  Enter confirm  digits select  Esc/q back",
+	"请选择 [0-%d]: ":                  "Choose [0-%d]: ",
+	"无效的选择，请重试":                     "Invalid choice, please try again",
+	"\x1b[2m… 还有 %d 行，↑↓ 滚动\x1b[0m": "\x1b[2m… %d more line(s), ↑↓ to scroll\x1b[0m",
+	"  是  ": "  Yes  ",
+	"  否  ": "  No   ",
+	"←→ 切换  y/n 直接选择  回车确认  Esc 取消": "←→ switch  y/n choose  Enter confirm  Esc cancel",
+
+	// utils.go
+	"Clash 二进制文件未找到: %w": "Clash binary not found: %w",
+	"Clash 配置文件未找到: %w":  "Clash config file not found: %w",
+	"Country.mmdb 文件未找到": "Country.mmdb not found",
+}
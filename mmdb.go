@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+)
+
+// MaxMind DB 元数据开始的标记
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// 只读的 MaxMind DB (Country.mmdb) 读取器，格式见 https://maxmind.github.io/MaxMind-DB/
+type mmdbReader struct {
+	buf        []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// 数据区在文件中的起始位置
+	dataStart uint
+	// IPv6 树中 IPv4 地址所在子树的节点
+	ipv4Start uint
+}
+
+// Clash 可能使用的 Country.mmdb 位置，工作目录优先
+func countryMMDBPaths() []string {
+	paths := []string{
+		clashWorkPath("Country.mmdb"),
+		"/etc/clash/Country.mmdb",
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(homeDir, ".config", "clash", "Country.mmdb"))
+	}
+	return paths
+}
+
+// 打开第一个存在的 Country.mmdb
+func openCountryMMDB() (*mmdbReader, string, error) {
+	for _, path := range countryMMDBPaths() {
+		if _, err := os.Stat(path); err == nil {
+			reader, err := openMMDB(path)
+			return reader, path, err
+		}
+	}
+	return nil, "", errors.New(T("未找到 Country.mmdb"))
+}
+
+// 读取并解析 MaxMind DB 文件
+func openMMDB(path string) (*mmdbReader, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 元数据位于文件末尾 128KiB 内，取最后一个标记
+	searchFrom := 0
+	if len(buf) > 128*1024 {
+		searchFrom = len(buf) - 128*1024
+	}
+	index := bytes.LastIndex(buf[searchFrom:], mmdbMetadataMarker)
+	if index < 0 {
+		return nil, fmt.Errorf(T("%s 不是有效的 MaxMind DB 文件"), path)
+	}
+	metaStart := uint(searchFrom + index + len(mmdbMetadataMarker))
+
+	decoder := mmdbDecoder{buf: buf[metaStart:]}
+	value, _, err := decoder.decode(0)
+	if err != nil {
+		return nil, fmt.Errorf(T("解析 MaxMind DB 元数据失败: %w"), err)
+	}
+	meta, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New(T("MaxMind DB 元数据格式无效"))
+	}
+
+	reader := &mmdbReader{
+		buf:        buf,
+		nodeCount:  uint(mmdbUint(meta["node_count"])),
+		recordSize: uint(mmdbUint(meta["record_size"])),
+		ipVersion:  uint(mmdbUint(meta["ip_version"])),
+	}
+	switch reader.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf(T("不支持的 record_size: %d"), reader.recordSize)
+	}
+
+	treeSize := reader.nodeCount * reader.recordSize / 4
+	reader.dataStart = treeSize + 16
+	if reader.dataStart > uint(len(buf)) {
+		return nil, errors.New(T("MaxMind DB 文件已损坏"))
+	}
+
+	// IPv4 地址在 IPv6 树中位于 ::/96 之下
+	if reader.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < reader.nodeCount; i++ {
+			node = reader.readRecord(node, 0)
+		}
+		reader.ipv4Start = node
+	}
+	return reader, nil
+}
+
+// 查找 IP 对应的记录，未找到时返回 nil
+func (r *mmdbReader) lookup(ip netip.Addr) (interface{}, error) {
+	ip = ip.Unmap()
+
+	var addr []byte
+	node := uint(0)
+	if ip.Is4() {
+		a := ip.As4()
+		addr = a[:]
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+		}
+	} else {
+		if r.ipVersion == 4 {
+			return nil, errors.New(T("数据库只包含 IPv4 地址"))
+		}
+		a := ip.As16()
+		addr = a[:]
+	}
+
+	for i := 0; i < len(addr)*8 && node < r.nodeCount; i++ {
+		bit := uint(addr[i/8]>>(7-uint(i%8))) & 1
+		node = r.readRecord(node, bit)
+	}
+
+	if node == r.nodeCount {
+		return nil, nil
+	}
+	if node < r.nodeCount {
+		return nil, errors.New(T("MaxMind DB 搜索树无效"))
+	}
+
+	offset := node - r.nodeCount - 16
+	decoder := mmdbDecoder{buf: r.buf[r.dataStart:]}
+	value, _, err := decoder.decode(offset)
+	return value, err
+}
+
+// 查找 IP 所属国家的 ISO 代码，例如 CN
+func (r *mmdbReader) country(ip netip.Addr) (string, error) {
+	record, err := r.lookup(ip)
+	if err != nil || record == nil {
+		return "", err
+	}
+
+	m, _ := record.(map[string]interface{})
+	for _, key := range []string{"country", "registered_country"} {
+		if country, ok := m[key].(map[string]interface{}); ok {
+			if code, ok := country["iso_code"].(string); ok && code != "" {
+				return code, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// 读取节点的左 (bit=0) 或右 (bit=1) 记录
+func (r *mmdbReader) readRecord(node, bit uint) uint {
+	size := r.recordSize / 4
+	b := r.buf[node*size : node*size+size]
+
+	switch r.recordSize {
+	case 24:
+		if bit == 0 {
+			return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3])<<16 | uint(b[4])<<8 | uint(b[5])
+	case 28:
+		// 中间字节的高 4 位属于左记录，低 4 位属于右记录
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		if bit == 0 {
+			return uint(binary.BigEndian.Uint32(b[0:4]))
+		}
+		return uint(binary.BigEndian.Uint32(b[4:8]))
+	}
+}
+
+// 数据区解码器，偏移量相对于数据区开头
+type mmdbDecoder struct {
+	buf []byte
+}
+
+// 解码 offset 处的值，返回值和下一个值的偏移量
+func (d *mmdbDecoder) decode(offset uint) (interface{}, uint, error) {
+	if offset >= uint(len(d.buf)) {
+		return nil, 0, fmt.Errorf(T("数据偏移超出范围: %d"), offset)
+	}
+
+	ctrl := d.buf[offset]
+	offset++
+	kind := uint(ctrl >> 5)
+
+	// 指针: 指向的值解码后继续从指针之后读取
+	if kind == 1 {
+		pointer, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer)
+		return value, next, err
+	}
+
+	if kind == 0 {
+		if offset >= uint(len(d.buf)) {
+			return nil, 0, errors.New(T("数据被截断"))
+		}
+		kind = 7 + uint(d.buf[offset])
+		offset++
+	}
+
+	size := uint(ctrl & 0x1F)
+	if size >= 29 {
+		extra := size - 28
+		if offset+extra > uint(len(d.buf)) {
+			return nil, 0, errors.New(T("数据被截断"))
+		}
+		n := uint(0)
+		for _, b := range d.buf[offset : offset+extra] {
+			n = n<<8 | uint(b)
+		}
+		offset += extra
+		switch size {
+		case 29:
+			size = 29 + n
+		case 30:
+			size = 285 + n
+		default:
+			size = 65821 + n
+		}
+	}
+
+	switch kind {
+	case 7: // map
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			value, after, err := d.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+			if k, ok := key.(string); ok {
+				m[k] = value
+			}
+			offset = after
+		}
+		return m, offset, nil
+	case 11: // array
+		list := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			list = append(list, value)
+			offset = next
+		}
+		return list, offset, nil
+	case 14: // boolean，值保存在 size 中
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errors.New(T("数据被截断"))
+	}
+	data := d.buf[offset : offset+size]
+	next := offset + size
+
+	switch kind {
+	case 2: // utf8 string
+		return string(data), next, nil
+	case 3: // double
+		if size != 8 {
+			return nil, 0, fmt.Errorf(T("无效的 double 长度: %d"), size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), next, nil
+	case 4: // bytes
+		return append([]byte{}, data...), next, nil
+	case 5, 6, 9: // uint16, uint32, uint64
+		n := uint64(0)
+		for _, b := range data {
+			n = n<<8 | uint64(b)
+		}
+		return n, next, nil
+	case 8: // int32
+		n := int32(0)
+		for _, b := range data {
+			n = n<<8 | int32(b)
+		}
+		return int64(n), next, nil
+	case 10: // uint128，只用于少见的字段，保留原始字节
+		return append([]byte{}, data...), next, nil
+	case 15: // float
+		if size != 4 {
+			return nil, 0, fmt.Errorf(T("无效的 float 长度: %d"), size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), next, nil
+	default:
+		return nil, 0, fmt.Errorf(T("不支持的数据类型: %d"), kind)
+	}
+}
+
+// 解析指针，返回指向的偏移量和指针之后的偏移量
+func (d *mmdbDecoder) pointer(ctrl byte, offset uint) (uint, uint, error) {
+	size := uint(ctrl>>3)&0x3 + 1
+	if offset+size > uint(len(d.buf)) {
+		return 0, 0, errors.New(T("数据被截断"))
+	}
+
+	n := uint(0)
+	for _, b := range d.buf[offset : offset+size] {
+		n = n<<8 | uint(b)
+	}
+
+	switch size {
+	case 1:
+		n = uint(ctrl&0x7)<<8 | n
+	case 2:
+		n = (uint(ctrl&0x7)<<16 | n) + 2048
+	case 3:
+		n = (uint(ctrl&0x7)<<24 | n) + 526336
+	}
+	return n, offset + size, nil
+}
+
+// 将元数据中的无符号整数转换为 uint64
+func mmdbUint(v interface{}) uint64 {
+	n, _ := v.(uint64)
+	return n
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Clash 服务默认的工作目录，安装脚本以 clash -d /srv/clash 启动服务
+const defaultClashWorkDir = "/srv/clash"
+
+// 当前使用的工作目录和配置文件，由 initClashPaths 设置
+var (
+	activeWorkDir    = defaultClashWorkDir
+	activeConfigPath = filepath.Join(defaultClashWorkDir, "config.yaml")
+	// 生成配置时使用的模板，为空时使用内置模板
+	activeTemplatePath string
+)
+
+// 解析命令前的全局参数 --config、--workdir、--template 和 --lang，返回剩余的命令行参数
+func parseGlobalFlags(args []string) ([]string, error) {
+	fs := flag.NewFlagSet("clash-setup", flag.ContinueOnError)
+	fs.Usage = flag.Usage
+	configPath := fs.String("config", "", T("Clash 配置文件路径"))
+	workDir := fs.String("workdir", "", T("工作目录，快照、订阅缓存、providers 等文件保存在这里"))
+	templatePath := fs.String("template", "", T("生成配置时使用的模板文件"))
+	lang := fs.String("lang", "", T("界面语言: zh-CN 或 en"))
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if err := initLanguage(*lang); err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	initClashPaths(*workDir, *configPath)
+	if *templatePath != "" {
+		activeTemplatePath, _ = filepath.Abs(*templatePath)
+	}
+	return fs.Args(), nil
+}
+
+// 确定工作目录和配置文件路径
+// 配置文件: --config > CLASH_CONFIG_PATH > <工作目录>/config.yaml > /srv/clash/config.yaml
+// 所有命令使用同一规则，未指定路径时读写 Clash 服务加载的 /srv/clash
+// 工作目录: --workdir > CLASH_WORKDIR > 指定配置文件所在目录 > /srv/clash
+func initClashPaths(workDir, configPath string) {
+	if workDir == "" {
+		workDir = os.Getenv("CLASH_WORKDIR")
+	}
+	if configPath == "" {
+		configPath = os.Getenv("CLASH_CONFIG_PATH")
+	}
+
+	switch {
+	case configPath != "":
+		// 已明确指定配置文件
+	case workDir != "":
+		configPath = filepath.Join(workDir, "config.yaml")
+	default:
+		configPath = filepath.Join(defaultClashWorkDir, "config.yaml")
+	}
+
+	if workDir == "" {
+		if filepath.Dir(configPath) != "." {
+			workDir = filepath.Dir(configPath)
+		} else {
+			workDir = defaultClashWorkDir
+		}
+	}
+
+	activeWorkDir, _ = filepath.Abs(workDir)
+	activeConfigPath, _ = filepath.Abs(configPath)
+}
+
+// 当前使用的 Clash 配置文件路径
+func clashConfigPath() string {
+	return activeConfigPath
+}
+
+// 用户通过 --template 指定的配置模板
+func configTemplatePath() string {
+	return activeTemplatePath
+}
+
+// 当前使用的工作目录
+func clashWorkDir() string {
+	return activeWorkDir
+}
+
+// 工作目录下的路径
+func clashWorkPath(elem ...string) string {
+	return filepath.Join(append([]string{activeWorkDir}, elem...)...)
+}
+
+// 当前配置是否就是 Clash 服务实际加载的配置，只有这种情况下重启服务才有意义
+func isServiceConfig() bool {
+	return activeConfigPath == filepath.Join(defaultClashWorkDir, "config.yaml")
+}
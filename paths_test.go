@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestInitClashPaths(t *testing.T) {
+	tests := []struct {
+		name        string
+		workDir     string
+		configPath  string
+		envWorkDir  string
+		envConfig   string
+		wantWorkDir string
+		wantConfig  string
+	}{
+		{
+			name:        "默认使用 Clash 服务的目录",
+			wantWorkDir: "/srv/clash",
+			wantConfig:  "/srv/clash/config.yaml",
+		},
+		{
+			name:        "指定工作目录时使用其中的配置文件",
+			workDir:     "/tmp/wd",
+			wantWorkDir: "/tmp/wd",
+			wantConfig:  "/tmp/wd/config.yaml",
+		},
+		{
+			name:        "指定配置文件时工作目录为其所在目录",
+			configPath:  "/opt/clash/main.yaml",
+			wantWorkDir: "/opt/clash",
+			wantConfig:  "/opt/clash/main.yaml",
+		},
+		{
+			name:        "环境变量",
+			envWorkDir:  "/var/clash",
+			envConfig:   "/etc/clash/config.yaml",
+			wantWorkDir: "/var/clash",
+			wantConfig:  "/etc/clash/config.yaml",
+		},
+		{
+			name:        "参数优先于环境变量",
+			workDir:     "/tmp/wd",
+			envWorkDir:  "/var/clash",
+			envConfig:   "/etc/clash/config.yaml",
+			wantWorkDir: "/tmp/wd",
+			wantConfig:  "/etc/clash/config.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workDir, configPath := activeWorkDir, activeConfigPath
+			defer func() { activeWorkDir, activeConfigPath = workDir, configPath }()
+			t.Setenv("CLASH_WORKDIR", tt.envWorkDir)
+			t.Setenv("CLASH_CONFIG_PATH", tt.envConfig)
+
+			initClashPaths(tt.workDir, tt.configPath)
+			if clashWorkDir() != tt.wantWorkDir || clashConfigPath() != tt.wantConfig {
+				t.Errorf("得到 %s, %s，期望 %s, %s", clashWorkDir(), clashConfigPath(), tt.wantWorkDir, tt.wantConfig)
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 一个保存在 profiles 目录中的配置
+type configProfile struct {
+	Name    string
+	Path    string
+	ModTime time.Time
+}
+
+// 保存 profile 的目录
+func clashProfilesDir() string {
+	return clashWorkPath("profiles")
+}
+
+// profile 对应的配置文件路径
+func profilePath(name string) string {
+	return filepath.Join(clashProfilesDir(), name+".yaml")
+}
+
+// 处理 profile 子命令
+func manageProfiles(args []string) {
+	if len(args) == 0 {
+		printProfileUsage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch args[0] {
+	case "list":
+		err = listProfiles()
+	case "create":
+		if len(args) < 2 || len(args) > 3 {
+			printProfileUsage()
+			os.Exit(1)
+		}
+		source := clashConfigPath()
+		if len(args) == 3 {
+			source = args[2]
+		}
+		err = createProfile(args[1], source)
+	case "clone":
+		if len(args) != 3 {
+			printProfileUsage()
+			os.Exit(1)
+		}
+		err = createProfile(args[2], profilePath(args[1]))
+	case "switch":
+		if len(args) != 2 {
+			printProfileUsage()
+			os.Exit(1)
+		}
+		err = switchProfile(args[1])
+	case "delete":
+		if len(args) != 2 {
+			printProfileUsage()
+			os.Exit(1)
+		}
+		err = deleteProfile(args[1])
+	default:
+		fmt.Printf(T("未知的 profile 子命令: %s\n"), args[0])
+		printProfileUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf(T("profile %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+}
+
+// 显示 profile 子命令的用法
+func printProfileUsage() {
+	fmt.Printf(T("用法: %s profile <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  list                   列出所有 profile，* 表示当前使用的 profile"))
+	fmt.Println(T("  create <名称> [文件]   从指定文件创建 profile，默认使用当前配置"))
+	fmt.Println(T("  clone <源> <新名称>    复制已有的 profile"))
+	fmt.Println(T("  switch <名称>          校验并切换到指定 profile，然后重启 Clash"))
+	fmt.Println(T("  delete <名称>          删除 profile，不能删除正在使用的 profile"))
+}
+
+// 检查 profile 名称，名称会直接用作文件名
+func validateProfileName(name string) error {
+	if name == "" {
+		return errors.New(T("profile 名称不能为空"))
+	}
+	if strings.HasPrefix(name, ".") {
+		return errors.New(T("profile 名称不能以 . 开头"))
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.' {
+			return fmt.Errorf(T("profile 名称只能包含字母、数字、-、_ 和 .: %s"), name)
+		}
+	}
+	return nil
+}
+
+// 列出 profiles 目录中的所有 profile，按名称排序
+func loadProfiles() ([]configProfile, error) {
+	entries, err := os.ReadDir(clashProfilesDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []configProfile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		profiles = append(profiles, configProfile{
+			Name:    strings.TrimSuffix(name, ".yaml"),
+			Path:    filepath.Join(clashProfilesDir(), name),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// 当前配置文件链接到的 profile，配置文件不是指向 profiles 目录的符号链接时返回空
+func linkedProfileName() string {
+	info, err := os.Lstat(clashConfigPath())
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return ""
+	}
+
+	target, err := filepath.EvalSymlinks(clashConfigPath())
+	if err != nil {
+		return ""
+	}
+	dir, err := filepath.EvalSymlinks(clashProfilesDir())
+	if err != nil || filepath.Dir(target) != dir {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.Base(target), ".yaml")
+}
+
+// 显示 profile 列表
+func listProfiles() error {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	if len(profiles) == 0 {
+		fmt.Printf(T("还没有 profile，可以使用 %s profile create <名称> 将当前配置保存为 profile\n"), os.Args[0])
+		return nil
+	}
+
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+	linked := linkedProfileName()
+
+	fmt.Printf(T("共有 %d 个 profile (保存在 %s):\n\n"), len(profiles), clashProfilesDir())
+	for _, profile := range profiles {
+		marker := " "
+		if profile.Name == linked {
+			marker = "*"
+		}
+
+		summary := T("无法解析")
+		if content, err := os.ReadFile(profile.Path); err == nil {
+			if config, err := parseClashConfig(content); err == nil {
+				summary = fmt.Sprintf(T("%d 个节点, %d 个代理组, %d 条规则"),
+					len(config.Proxies), len(config.ProxyGroups), len(config.Rules))
+			}
+		}
+
+		fmt.Printf("%s %-16s %s  %s\n", marker, profile.Name,
+			profile.ModTime.Format("2006-01-02 15:04:05"), summary)
+	}
+
+	// 配置文件被手动替换后，记录的 profile 可能已经不再生效
+	if settings.ActiveProfile != "" && settings.ActiveProfile != linked {
+		fmt.Printf(T("\n注意: 上次切换到的 profile 为 %s，但 %s 当前没有指向它\n"), settings.ActiveProfile, clashConfigPath())
+	}
+	return nil
+}
+
+// 从文件创建新的 profile
+func createProfile(name, source string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	path := profilePath(name)
+	if _, err := os.Stat(path); err == nil {
+		return errorOf(ErrNameConflict, T("profile '%s' 已存在"), name)
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf(T("读取 %s 失败: %w"), source, err)
+	}
+	if _, err := parseClashConfig(content); err != nil {
+		return fmt.Errorf(T("%s 不是有效的 Clash 配置: %w"), source, err)
+	}
+
+	if err := writeFileAtomic(path, content, 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf(T("已创建 profile '%s' (%s)\n"), name, path)
+	return nil
+}
+
+// 校验目标 profile，将配置文件原子地替换为指向它的符号链接，然后重启 Clash
+// Clash 未能正常启动时恢复切换前的配置
+func switchProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	path := profilePath(name)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errorOf(ErrProfileNotFound, T("profile '%s' 不存在"), name)
+		}
+		return err
+	}
+
+	config, err := parseClashConfig(content)
+	if err != nil {
+		return fmt.Errorf(T("解析 profile '%s' 失败: %w"), name, err)
+	}
+	if err := validateConfig(config); err != nil {
+		return fmt.Errorf(T("profile '%s' 未通过校验: %w"), name, err)
+	}
+
+	if linkedProfileName() == name {
+		fmt.Printf(T("当前已经在使用 profile '%s'\n"), name)
+		return nil
+	}
+
+	// 记录切换前的状态，启动失败时用于恢复
+	previousLink, _ := os.Readlink(clashConfigPath())
+	previousContent, readErr := os.ReadFile(clashConfigPath())
+	hasPrevious := readErr == nil
+
+	// 当前配置不属于任何 profile 时，切换后只能从快照中找回
+	if hasPrevious && linkedProfileName() == "" {
+		backup, err := backupClashConfig()
+		if err != nil {
+			return fmt.Errorf(T("备份当前配置失败: %w"), err)
+		}
+		fmt.Printf(T("当前配置不属于任何 profile，已保存为快照 %s\n"), filepath.Base(backup))
+	}
+
+	if err := replaceWithSymlink(clashConfigPath(), path); err != nil {
+		return fmt.Errorf(T("切换配置文件失败: %w"), err)
+	}
+
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+	previousProfile := settings.ActiveProfile
+	settings.ActiveProfile = name
+	if err := saveManagerSettings(settings); err != nil {
+		return fmt.Errorf(T("保存当前 profile 失败: %w"), err)
+	}
+	fmt.Printf(T("已切换到 profile '%s'\n"), name)
+
+	if !isServiceConfig() {
+		fmt.Printf(T("%s 不是 Clash 服务加载的配置，跳过重启\n"), clashConfigPath())
+		return nil
+	}
+
+	fmt.Println(T("正在重启 Clash 服务..."))
+	err = restartClashAndWait()
+	if err == nil {
+		fmt.Println(T("Clash 服务已重启并正常运行"))
+		return nil
+	}
+	fmt.Printf(T("Clash 未能正常启动: %v\n"), err)
+
+	if !hasPrevious {
+		return fmt.Errorf(T("profile '%s' 导致 Clash 无法启动，且切换前没有可恢复的配置"), name)
+	}
+
+	fmt.Println(T("正在恢复切换前的配置..."))
+	if previousLink != "" {
+		err = replaceWithSymlink(clashConfigPath(), previousLink)
+	} else {
+		// 先删除符号链接，避免恢复的内容写入 profile 文件
+		if err = os.Remove(clashConfigPath()); err == nil {
+			err = writeFileAtomic(clashConfigPath(), previousContent, 0644)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf(T("恢复配置失败: %w"), err)
+	}
+
+	settings.ActiveProfile = previousProfile
+	if err := saveManagerSettings(settings); err != nil {
+		fmt.Printf(T("保存当前 profile 失败: %v\n"), err)
+	}
+
+	if retryErr := restartClashAndWait(); retryErr != nil {
+		return fmt.Errorf(T("已恢复切换前的配置，但 Clash 仍未能启动: %w"), retryErr)
+	}
+	return fmt.Errorf(T("profile '%s' 导致 Clash 无法启动，已恢复切换前的配置"), name)
+}
+
+// 原子地将 path 替换为指向 target 的符号链接
+func replaceWithSymlink(path, target string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmpPath := filepath.Join(dir, fmt.Sprintf(".%s.link-%d", filepath.Base(path), os.Getpid()))
+	os.Remove(tmpPath)
+	if err := os.Symlink(target, tmpPath); err != nil {
+		return err
+	}
+	// rename 替换的是链接本身，不会影响原来指向的文件
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// 删除 profile，正在使用的 profile 需要先切换到其他 profile
+func deleteProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	path := profilePath(name)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return errorOf(ErrProfileNotFound, T("profile '%s' 不存在"), name)
+		}
+		return err
+	}
+
+	if linkedProfileName() == name {
+		return fmt.Errorf(T("profile '%s' 正在使用中，请先切换到其他 profile"), name)
+	}
+
+	question := fmt.Sprintf(T("确定要删除 profile '%s' 吗？"), name)
+	if !confirmDialog(T("删除 profile"), question, []string{T("将删除文件 ") + path}, false) {
+		fmt.Println(T("已取消"))
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	if settings, err := loadManagerSettings(); err == nil && settings.ActiveProfile == name {
+		settings.ActiveProfile = ""
+		saveManagerSettings(settings)
+	}
+
+	fmt.Printf(T("已删除 profile '%s'\n"), name)
+	return nil
+}
@@ -5,7 +5,7 @@ package main
 // - proxy_utils.go - 工具函数
 // - proxy_speed.go - 测速相关功能
 // - proxy_import.go - 导入相关功能
-// - proxy_uri.go - URI解析功能 
+// - proxy_uri.go - URI解析功能
 
 // proxy.go 作为代理管理功能的主要入口点
-// 不再包含任何实际实现，避免函数名冲突
\ No newline at end of file
+// 不再包含任何实际实现，避免函数名冲突
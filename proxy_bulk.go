@@ -0,0 +1,655 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 节点的附加信息，保存在管理工具设置中，不写入 Clash 配置
+type ProxyMeta struct {
+	Tags []string `yaml:"tags,omitempty"`
+	// 最近一次测速的延迟 (毫秒)，-1 表示测速失败，0 表示未测速
+	Delay    int       `yaml:"delay,omitempty"`
+	TestedAt time.Time `yaml:"tested-at,omitempty"`
+}
+
+// 批量操作的节点筛选条件，多个条件同时满足才会选中
+type proxySelector struct {
+	Pattern *regexp.Regexp
+	Type    string
+	Tag     string
+	// 最近一次测速失败的节点
+	Failed bool
+}
+
+// 是否没有设置任何筛选条件
+func (s *proxySelector) empty() bool {
+	return s.Pattern == nil && s.Type == "" && s.Tag == "" && !s.Failed
+}
+
+// 筛选条件的文字描述
+func (s *proxySelector) String() string {
+	var parts []string
+	if s.Pattern != nil {
+		parts = append(parts, T("名称匹配 ")+s.Pattern.String())
+	}
+	if s.Type != "" {
+		parts = append(parts, T("类型为 ")+s.Type)
+	}
+	if s.Tag != "" {
+		parts = append(parts, T("标签包含 ")+s.Tag)
+	}
+	if s.Failed {
+		parts = append(parts, T("测速失败"))
+	}
+	if len(parts) == 0 {
+		return T("全部节点")
+	}
+	return strings.Join(parts, "，")
+}
+
+// 设置一个筛选条件，key 为 match、type、tag 或 failed
+func (s *proxySelector) set(key, value string) error {
+	switch key {
+	case "match":
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return fmt.Errorf(T("无效的正则表达式 %s: %w"), value, err)
+		}
+		s.Pattern = pattern
+	case "type":
+		s.Type = value
+	case "tag":
+		s.Tag = value
+	case "failed":
+		failed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf(T("failed 只能是 true 或 false: %s"), value)
+		}
+		s.Failed = failed
+	default:
+		return fmt.Errorf(T("未知的筛选条件: %s"), key)
+	}
+	return nil
+}
+
+// 按筛选条件选出节点，保持配置中的顺序
+func selectProxies(config *ClashConfig, settings *ManagerSettings, selector *proxySelector) []string {
+	var names []string
+	for _, proxy := range config.Proxies {
+		meta := settings.ProxyMeta[proxy.Name]
+		if selector.Pattern != nil && !selector.Pattern.MatchString(proxy.Name) {
+			continue
+		}
+		if selector.Type != "" && proxy.Type != selector.Type {
+			continue
+		}
+		if selector.Tag != "" && (meta == nil || !containsString(meta.Tags, selector.Tag)) {
+			continue
+		}
+		if selector.Failed && (meta == nil || meta.Delay >= 0) {
+			continue
+		}
+		names = append(names, proxy.Name)
+	}
+	return names
+}
+
+// 获取节点的附加信息，不存在时创建
+func proxyMeta(settings *ManagerSettings, name string) *ProxyMeta {
+	if settings.ProxyMeta == nil {
+		settings.ProxyMeta = make(map[string]*ProxyMeta)
+	}
+	meta := settings.ProxyMeta[name]
+	if meta == nil {
+		meta = &ProxyMeta{}
+		settings.ProxyMeta[name] = meta
+	}
+	return meta
+}
+
+// 清理没有内容的附加信息
+func pruneProxyMeta(settings *ManagerSettings, name string) {
+	if meta := settings.ProxyMeta[name]; meta != nil && len(meta.Tags) == 0 && meta.Delay == 0 {
+		delete(settings.ProxyMeta, name)
+	}
+}
+
+// 记录测速结果
+func applyProxyDelays(settings *ManagerSettings, delays map[string]int) {
+	now := time.Now()
+	for name, delay := range delays {
+		if delay <= 0 {
+			delay = -1
+		}
+		meta := proxyMeta(settings, name)
+		meta.Delay = delay
+		meta.TestedAt = now
+	}
+}
+
+// 保存菜单中的测速结果，供按延迟排序和筛选测速失败的节点使用
+func recordProxyDelays(delays map[string]int) {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		fmt.Printf(T("读取设置失败，测速结果未保存: %v\n"), err)
+		return
+	}
+	applyProxyDelays(settings, delays)
+	if err := saveManagerSettings(settings); err != nil {
+		fmt.Printf(T("保存测速结果失败: %v\n"), err)
+	}
+}
+
+// 重命名节点后同步它的附加信息
+func renameProxyMeta(settings *ManagerSettings, old, new string) {
+	if meta, ok := settings.ProxyMeta[old]; ok {
+		delete(settings.ProxyMeta, old)
+		settings.ProxyMeta[new] = meta
+	}
+}
+
+// 批量删除节点，任意一个节点不能删除时不做任何修改
+func bulkDeleteProxies(config *ClashConfig, settings *ManagerSettings, names []string) error {
+	for _, name := range names {
+		if err := checkProxyRemovable(config, name); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		config.RemoveProxy(name)
+		removeFromProxyGroups(config, name)
+		delete(settings.ProxyMeta, name)
+	}
+	return nil
+}
+
+// 用正则表达式替换节点名称，replace 中可以使用 $1 等引用分组
+// 先检查所有新名称，存在冲突时不做任何修改
+func bulkRenameProxies(config *ClashConfig, settings *ManagerSettings, names []string, pattern *regexp.Regexp, replace string) (int, error) {
+	renames := make(map[string]string)
+	taken := make(map[string]string)
+	for _, name := range names {
+		newName := strings.TrimSpace(pattern.ReplaceAllString(name, replace))
+		if newName == name {
+			continue
+		}
+		if newName == "" || strings.Contains(newName, ",") {
+			return 0, fmt.Errorf(T("%s 替换后的名称无效: %q"), name, newName)
+		}
+		if other, ok := taken[newName]; ok {
+			return 0, fmt.Errorf(T("%s 和 %s 替换后重名: %s"), other, name, newName)
+		}
+		if policyNameExists(config, newName) {
+			return 0, errorOf(ErrNameConflict, T("%s 替换后的名称 %s 已被使用"), name, newName)
+		}
+		taken[newName] = name
+		renames[name] = newName
+	}
+
+	for _, name := range names {
+		newName, ok := renames[name]
+		if !ok {
+			continue
+		}
+		if err := renameProxy(config, name, newName); err != nil {
+			return 0, err
+		}
+		renameProxyMeta(settings, name, newName)
+		renameSubscriptionNode(settings, name, newName)
+	}
+	return len(renames), nil
+}
+
+// 按数字大小比较名称中的数字部分，例如 "HK 2" 排在 "HK 10" 之前
+func naturalLess(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+			si := i
+			for i < len(ra) && unicode.IsDigit(ra[i]) {
+				i++
+			}
+			sj := j
+			for j < len(rb) && unicode.IsDigit(rb[j]) {
+				j++
+			}
+			na := strings.TrimLeft(string(ra[si:i]), "0")
+			nb := strings.TrimLeft(string(rb[sj:j]), "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			continue
+		}
+		if ra[i] != rb[j] {
+			return ra[i] < rb[j]
+		}
+		i++
+		j++
+	}
+	return len(ra)-i < len(rb)-j
+}
+
+// 按名称、延迟或地区排序节点，代理组中的节点按同样的顺序排列
+// 代理组中的其它成员 (代理组、内置策略) 位置不变，relay 代理组的顺序是转发顺序，不会改变
+func sortProxies(config *ClashConfig, settings *ManagerSettings, by string) error {
+	var less func(a, b string) bool
+	switch by {
+	case "name":
+		less = naturalLess
+	case "latency":
+		delay := func(name string) int {
+			// 未测速和测速失败的节点排在最后
+			if meta := settings.ProxyMeta[name]; meta != nil && meta.Delay > 0 {
+				return meta.Delay
+			}
+			return int(^uint(0) >> 1)
+		}
+		less = func(a, b string) bool {
+			if delay(a) != delay(b) {
+				return delay(a) < delay(b)
+			}
+			return naturalLess(a, b)
+		}
+	case "region":
+		assignments := classifyProxyRegions(config.Proxies)
+		var codes []string
+		seen := make(map[string]bool)
+		for _, assignment := range assignments {
+			if !seen[assignment.Code] {
+				seen[assignment.Code] = true
+				codes = append(codes, assignment.Code)
+			}
+		}
+		sortRegionCodes(codes)
+		rank := make(map[string]int)
+		for i, code := range codes {
+			rank[code] = i
+		}
+		less = func(a, b string) bool {
+			ra, rb := rank[assignments[a].Code], rank[assignments[b].Code]
+			if ra != rb {
+				return ra < rb
+			}
+			return naturalLess(a, b)
+		}
+	default:
+		return fmt.Errorf(T("不支持的排序方式: %s (可选 name、latency、region)"), by)
+	}
+
+	sort.SliceStable(config.Proxies, func(i, j int) bool {
+		return less(config.Proxies[i].Name, config.Proxies[j].Name)
+	})
+
+	isNode := make(map[string]bool)
+	for _, proxy := range config.Proxies {
+		isNode[proxy.Name] = true
+	}
+	for i := range config.ProxyGroups {
+		group := &config.ProxyGroups[i]
+		if group.Type == "relay" {
+			continue
+		}
+		var nodes []string
+		for _, member := range group.Proxies {
+			if isNode[member] {
+				nodes = append(nodes, member)
+			}
+		}
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return less(nodes[i], nodes[j])
+		})
+		for j, member := range group.Proxies {
+			if isNode[member] {
+				group.Proxies[j] = nodes[0]
+				nodes = nodes[1:]
+			}
+		}
+	}
+	return nil
+}
+
+// 为节点添加或移除标签
+func tagProxies(settings *ManagerSettings, names []string, tags []string, remove bool) {
+	for _, name := range names {
+		meta := proxyMeta(settings, name)
+		for _, tag := range tags {
+			if remove {
+				var kept []string
+				for _, existing := range meta.Tags {
+					if existing != tag {
+						kept = append(kept, existing)
+					}
+				}
+				meta.Tags = kept
+			} else if !containsString(meta.Tags, tag) {
+				meta.Tags = append(meta.Tags, tag)
+			}
+		}
+		pruneProxyMeta(settings, name)
+	}
+}
+
+// 测试节点的连接延迟并记录结果
+func testProxiesDelay(settings *ManagerSettings, names []string) error {
+	delays, err := getProxyDelaysSimple(names)
+	if err != nil {
+		return err
+	}
+	applyProxyDelays(settings, delays)
+	return nil
+}
+
+// 打印选中的节点及其标签和测速结果
+func printSelectedProxies(config *ClashConfig, settings *ManagerSettings, names []string) {
+	if len(names) == 0 {
+		fmt.Println(T("没有符合条件的节点"))
+		return
+	}
+	fmt.Printf(T("共有 %d 个节点:\n\n"), len(names))
+	for i, name := range names {
+		proxy := config.FindProxy(name)
+		line := fmt.Sprintf("%d. %s (%s)", i+1, name, proxy.Type)
+		if meta := settings.ProxyMeta[name]; meta != nil {
+			switch {
+			case meta.Delay > 0:
+				line += fmt.Sprintf(" %d ms", meta.Delay)
+			case meta.Delay < 0:
+				line += T(" 测速失败")
+			}
+			if len(meta.Tags) > 0 {
+				line += " [" + strings.Join(meta.Tags, ", ") + "]"
+			}
+		}
+		fmt.Println(line)
+	}
+}
+
+// 显示 proxy bulk 的用法
+func printBulkUsage() {
+	fmt.Printf(T("用法: %s proxy bulk <操作> [筛选条件] [参数]\n\n"), os.Args[0])
+	fmt.Println(T("操作:"))
+	fmt.Println(T("  list                             列出选中的节点"))
+	fmt.Println(T("  delete                           删除选中的节点，必须指定筛选条件"))
+	fmt.Println(T("  rename --match <正则> --replace <替换>"))
+	fmt.Println(T("                                   替换选中节点名称中匹配的部分，可以使用 $1 引用分组"))
+	fmt.Println(T("  sort --by name|latency|region    排序所有节点和代理组成员"))
+	fmt.Println(T("  tag --add <标签,...>             为选中的节点添加标签"))
+	fmt.Println(T("  tag --remove <标签,...>          移除选中节点的标签"))
+	fmt.Println(T("  test                             测试选中节点的延迟并记录结果"))
+	fmt.Println(T("\n筛选条件 (可以组合):"))
+	fmt.Println(T("  --match <正则>   名称匹配正则表达式"))
+	fmt.Println(T("  --type <类型>    节点类型，例如 ss、vmess"))
+	fmt.Println(T("  --tag <标签>     包含指定标签"))
+	fmt.Println(T("  --failed true    最近一次测速失败"))
+}
+
+// 处理 proxy bulk 子命令
+func manageBulkProxies(args []string) {
+	if len(args) == 0 {
+		printBulkUsage()
+		os.Exit(1)
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		os.Exit(1)
+	}
+	current, _ := readClashConfig()
+	settings, err := loadManagerSettings()
+	if err != nil {
+		fmt.Printf(T("读取设置失败: %v\n"), err)
+		os.Exit(1)
+	}
+
+	positional, options, err := parseGroupArgs(args[1:])
+	if err == nil && len(positional) > 0 {
+		err = fmt.Errorf(T("多余的参数: %s"), strings.Join(positional, " "))
+	}
+	selector := &proxySelector{}
+	params := make(map[string]string)
+	for _, option := range options {
+		if err != nil {
+			break
+		}
+		switch option[0] {
+		case "match", "type", "tag", "failed":
+			// tag 操作的 --tag 同样作为筛选条件
+			err = selector.set(option[0], option[1])
+		default:
+			params[option[0]] = option[1]
+		}
+	}
+	if err != nil {
+		fmt.Printf(T("proxy bulk %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+	names := selectProxies(config, settings, selector)
+
+	// 修改 Clash 配置的操作需要保存配置，其它操作只保存设置
+	configChanged := false
+	switch args[0] {
+	case "list":
+		printSelectedProxies(config, settings, names)
+		return
+	case "delete":
+		if selector.empty() {
+			err = errors.New(T("删除需要指定筛选条件"))
+		} else if err = bulkDeleteProxies(config, settings, names); err == nil {
+			fmt.Printf(T("删除 %d 个节点\n"), len(names))
+		}
+		configChanged = true
+	case "rename":
+		if _, ok := params["replace"]; selector.Pattern == nil || !ok {
+			err = errors.New(T("重命名需要 --match 和 --replace"))
+			break
+		}
+		var count int
+		if count, err = bulkRenameProxies(config, settings, names, selector.Pattern, params["replace"]); err == nil {
+			fmt.Printf(T("重命名 %d 个节点\n"), count)
+		}
+		configChanged = true
+	case "sort":
+		err = sortProxies(config, settings, params["by"])
+		configChanged = true
+	case "tag":
+		switch {
+		case params["add"] != "":
+			tagProxies(settings, names, splitList(params["add"]), false)
+		case params["remove"] != "":
+			tagProxies(settings, names, splitList(params["remove"]), true)
+		default:
+			err = errors.New(T("需要指定 --add 或 --remove"))
+		}
+		if err == nil {
+			fmt.Printf(T("已更新 %d 个节点的标签\n"), len(names))
+		}
+	case "test":
+		err = testProxiesDelay(settings, names)
+	default:
+		fmt.Printf(T("未知的操作: %s\n"), args[0])
+		printBulkUsage()
+		os.Exit(1)
+	}
+	for key := range params {
+		if err == nil && !bulkParamAllowed(args[0], key) {
+			err = fmt.Errorf(T("未知的参数: --%s"), key)
+		}
+	}
+	if err != nil {
+		fmt.Printf(T("proxy bulk %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+
+	if configChanged {
+		printConfigChanges(diffClashConfigs(current, config))
+		if err := saveClashConfig(config); err != nil {
+			fmt.Printf(T("保存配置失败: %v\n"), err)
+			os.Exit(1)
+		}
+	}
+	if err := saveManagerSettings(settings); err != nil {
+		fmt.Printf(T("保存设置失败: %v\n"), err)
+		os.Exit(1)
+	}
+	if configChanged {
+		fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+	}
+}
+
+// 各操作可以使用的参数
+func bulkParamAllowed(op, key string) bool {
+	switch op {
+	case "rename":
+		return key == "replace"
+	case "sort":
+		return key == "by"
+	case "tag":
+		return key == "add" || key == "remove"
+	}
+	return false
+}
+
+// 批量管理菜单，序号加 1 对应 interactiveBulkProxies 中的选项
+var bulkMenuItems = []string{
+	"设置筛选条件",
+	"查看选中的节点",
+	"删除选中的节点",
+	"按正则重命名选中的节点",
+	"排序所有节点 (名称/延迟/地区)",
+	"为选中的节点添加标签",
+	"移除选中节点的标签",
+	"测速选中的节点",
+	"保存并应用修改",
+}
+
+// 交互式批量管理节点，所有修改在最后一次保存，只需要重启一次
+func interactiveBulkProxies() {
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+	settings, err := loadManagerSettings()
+	if err != nil {
+		fmt.Printf(T("读取设置失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+
+	reader := stdinReader
+	selector := &proxySelector{}
+	configChanged, settingsChanged := false, false
+	for {
+		names := selectProxies(config, settings, selector)
+		header := []string{fmt.Sprintf(T("筛选条件: %s，选中 %d 个节点"), selector, len(names))}
+		if configChanged || settingsChanged {
+			header = append(header, T("有尚未保存的修改"))
+		}
+		choice := selectMenu(T("批量管理节点"), header, translateAll(bulkMenuItems), T("放弃未保存的修改并返回")) + 1
+
+		err = nil
+		switch choice {
+		case 1:
+			selector = &proxySelector{}
+			fmt.Println(T("\n直接回车表示不限制"))
+			for _, key := range []string{"match", "type", "tag"} {
+				if value := promptLine(reader, fmt.Sprintf("%s: ", key)); value != "" && err == nil {
+					err = selector.set(key, value)
+				}
+			}
+			if strings.ToLower(promptLine(reader, T("只选择测速失败的节点? [y/N]: "))) == "y" {
+				selector.Failed = true
+			}
+			if err != nil {
+				selector = &proxySelector{}
+			}
+		case 2:
+			fmt.Println()
+			printSelectedProxies(config, settings, names)
+		case 3:
+			if selector.empty() {
+				err = errors.New(T("删除需要先设置筛选条件"))
+			} else if err = bulkDeleteProxies(config, settings, names); err == nil {
+				fmt.Printf(T("已删除 %d 个节点\n"), len(names))
+				configChanged, settingsChanged = true, true
+			}
+		case 4:
+			pattern, compileErr := regexp.Compile(promptLine(reader, T("要替换的正则表达式: ")))
+			if compileErr != nil {
+				err = fmt.Errorf(T("无效的正则表达式: %w"), compileErr)
+				break
+			}
+			replace := promptLine(reader, T("替换为 (可以使用 $1 引用分组): "))
+			var count int
+			if count, err = bulkRenameProxies(config, settings, names, pattern, replace); err == nil {
+				fmt.Printf(T("已重命名 %d 个节点\n"), count)
+				configChanged, settingsChanged = true, true
+			}
+		case 5:
+			by := promptLine(reader, T("排序方式 name/latency/region [name]: "))
+			if by == "" {
+				by = "name"
+			}
+			if err = sortProxies(config, settings, by); err == nil {
+				fmt.Println(T("已排序"))
+				configChanged = true
+			}
+		case 6, 7:
+			tags := promptList(reader, T("标签 (多个用逗号分隔): "))
+			tagProxies(settings, names, tags, choice == 7)
+			fmt.Printf(T("已更新 %d 个节点的标签\n"), len(names))
+			settingsChanged = true
+		case 8:
+			if err = testProxiesDelay(settings, names); err == nil {
+				settingsChanged = true
+			}
+		case 9:
+			if configChanged {
+				saved, saveErr := saveClashConfigWithConfirm(config)
+				if saveErr != nil || !saved {
+					if saveErr != nil {
+						fmt.Printf(T("保存配置失败: %v\n"), saveErr)
+					}
+					waitForKeyPress()
+					continue
+				}
+			}
+			if settingsChanged {
+				if err := saveManagerSettings(settings); err != nil {
+					fmt.Printf(T("保存设置失败: %v\n"), err)
+				}
+			}
+			if configChanged {
+				promptRestartClash()
+			}
+			configChanged, settingsChanged = false, false
+		case 0:
+			if !configChanged && !settingsChanged {
+				return
+			}
+			if confirmDialog(T("批量管理节点"), T("放弃未保存的修改？"), []string{T("配置和节点标签的修改都不会保存")}, false) {
+				return
+			}
+			continue
+		}
+
+		if err != nil {
+			fmt.Printf(T("操作失败: %v\n"), err)
+		}
+		waitForKeyPress()
+	}
+}
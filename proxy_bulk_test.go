@@ -0,0 +1,151 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+const bulkTestConfig = `
+proxies:
+  - {name: HK 01, type: http, server: 1.1.1.1, port: 80}
+  - {name: HK 02, type: http, server: 1.1.1.2, port: 80}
+  - {name: US 01, type: http, server: 1.1.1.3, port: 80}
+  - {name: via-hk, type: http, server: 1.1.1.4, port: 80, dialer-proxy: HK 02}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [HK 01, HK 02, US 01], selected: HK 01}
+rules:
+  - DOMAIN-SUFFIX,hk,HK 01
+  - MATCH,Proxy
+`
+
+func TestBulkRenameProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		pattern string
+		replace string
+		// 期望的错误，为空表示重命名成功
+		wantErr     error
+		wantCount   int
+		wantProxies []string
+		wantGroup   []string
+		wantRules   []string
+	}{
+		{
+			name:        "按分组替换并同步所有引用",
+			names:       []string{"HK 01", "HK 02", "US 01", "via-hk"},
+			pattern:     `^HK (\d+)$`,
+			replace:     "香港 $1",
+			wantCount:   2,
+			wantProxies: []string{"香港 01", "香港 02", "US 01", "via-hk"},
+			wantGroup:   []string{"香港 01", "香港 02", "US 01"},
+			wantRules:   []string{"DOMAIN-SUFFIX,hk,香港 01", "MATCH,Proxy"},
+		},
+		{
+			name:        "只处理选中的节点",
+			names:       []string{"HK 02"},
+			pattern:     `HK`,
+			replace:     "Hong Kong",
+			wantCount:   1,
+			wantProxies: []string{"HK 01", "Hong Kong 02", "US 01", "via-hk"},
+			wantGroup:   []string{"HK 01", "Hong Kong 02", "US 01"},
+			wantRules:   []string{"DOMAIN-SUFFIX,hk,HK 01", "MATCH,Proxy"},
+		},
+		{
+			name:        "没有匹配时不做修改",
+			names:       []string{"HK 01", "HK 02", "US 01"},
+			pattern:     `^JP`,
+			replace:     "日本",
+			wantProxies: []string{"HK 01", "HK 02", "US 01", "via-hk"},
+			wantGroup:   []string{"HK 01", "HK 02", "US 01"},
+			wantRules:   []string{"DOMAIN-SUFFIX,hk,HK 01", "MATCH,Proxy"},
+		},
+		{
+			name:    "替换后相互重名",
+			names:   []string{"HK 01", "US 01"},
+			pattern: `^\w+ 01$`,
+			replace: "X",
+			wantErr: errAny,
+		},
+		{
+			name:    "替换后与已有节点重名",
+			names:   []string{"HK 01"},
+			pattern: `HK`,
+			replace: "US",
+			wantErr: ErrNameConflict,
+		},
+		{
+			name:    "替换后与代理组重名",
+			names:   []string{"US 01"},
+			pattern: `.*`,
+			replace: "Proxy",
+			wantErr: ErrNameConflict,
+		},
+		{
+			name:    "替换后名称为空",
+			names:   []string{"HK 01"},
+			pattern: `.*`,
+			replace: " ",
+			wantErr: errAny,
+		},
+		{
+			name:    "替换后名称包含逗号",
+			names:   []string{"HK 01"},
+			pattern: ` `,
+			replace: ",",
+			wantErr: errAny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := mustParseConfig(t, bulkTestConfig)
+			settings := &ManagerSettings{
+				ProxyMeta:     map[string]*ProxyMeta{"HK 01": {Tags: []string{"hk"}}},
+				Subscriptions: []SubscriptionRecord{{Name: "sub", Nodes: []string{"HK 02", "US 01"}}},
+			}
+
+			var count int
+			// 任意一个名称无效时不做任何修改
+			if !expectConfigOp(t, config, tt.wantErr, func() error {
+				var err error
+				count, err = bulkRenameProxies(config, settings, tt.names, regexp.MustCompile(tt.pattern), tt.replace)
+				return err
+			}) {
+				return
+			}
+
+			var names []string
+			for _, proxy := range config.Proxies {
+				names = append(names, proxy.Name)
+			}
+			if count != tt.wantCount {
+				t.Errorf("重命名 %d 个节点，期望 %d 个", count, tt.wantCount)
+			}
+			if !reflect.DeepEqual(names, tt.wantProxies) {
+				t.Errorf("节点 = %v，期望 %v", names, tt.wantProxies)
+			}
+			group := config.FindGroup("Proxy")
+			if !reflect.DeepEqual(group.Proxies, tt.wantGroup) {
+				t.Errorf("代理组成员 = %v，期望 %v", group.Proxies, tt.wantGroup)
+			}
+			if group.Selected != tt.wantGroup[0] {
+				t.Errorf("选中的节点 = %s，期望 %s", group.Selected, tt.wantGroup[0])
+			}
+			if !reflect.DeepEqual(config.Rules, tt.wantRules) {
+				t.Errorf("规则 = %v，期望 %v", config.Rules, tt.wantRules)
+			}
+			// dialer-proxy、附加信息和订阅记录跟随节点名称
+			if got, want := config.FindProxy("via-hk").GetString("dialer-proxy"), tt.wantProxies[1]; got != want {
+				t.Errorf("dialer-proxy = %q，期望 %q", got, want)
+			}
+			if meta := settings.ProxyMeta[tt.wantProxies[0]]; meta == nil || meta.Tags[0] != "hk" {
+				t.Errorf("%s 的附加信息没有跟随重命名: %v", tt.wantProxies[0], settings.ProxyMeta)
+			}
+			if got, want := settings.Subscriptions[0].Nodes, tt.wantProxies[1:3]; !reflect.DeepEqual(got, want) {
+				t.Errorf("订阅节点 = %v，期望 %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 停用的节点，保存完整的节点配置和停用前所在的代理组
+type DisabledProxy struct {
+	Proxy      Proxy                `yaml:"proxy"`
+	Groups     []DisabledMembership `yaml:"groups,omitempty"`
+	DisabledAt time.Time            `yaml:"disabled-at"`
+}
+
+// 停用前节点在代理组中的位置
+type DisabledMembership struct {
+	Group    string `yaml:"group"`
+	Index    int    `yaml:"index"`
+	Selected bool   `yaml:"selected,omitempty"`
+}
+
+// 停用节点的存储文件
+type disabledProxyStore struct {
+	Proxies []DisabledProxy `yaml:"proxies"`
+}
+
+// 停用节点保存在配置文件旁的隐藏文件中，配置是 profile 的符号链接时跟随 profile
+func disabledProxiesPath() string {
+	path := clashConfigPath()
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return filepath.Join(filepath.Dir(path), "."+base+".disabled.yaml")
+}
+
+// 读取停用的节点，文件不存在时返回空列表
+func loadDisabledProxies() (*disabledProxyStore, error) {
+	store := &disabledProxyStore{}
+	content, err := os.ReadFile(disabledProxiesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, store); err != nil {
+		return nil, fmt.Errorf(T("解析 %s 失败: %w"), disabledProxiesPath(), err)
+	}
+	return store, nil
+}
+
+// 保存停用的节点，没有停用的节点时删除文件
+func saveDisabledProxies(store *disabledProxyStore) error {
+	if len(store.Proxies) == 0 {
+		if err := os.Remove(disabledProxiesPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	content, err := yaml.Marshal(store)
+	if err != nil {
+		return err
+	}
+	// 节点配置中包含密码，仅允许 root 读取
+	return writeFileAtomic(disabledProxiesPath(), content, 0600)
+}
+
+// 查找停用的节点
+func (s *disabledProxyStore) find(name string) int {
+	for i, disabled := range s.Proxies {
+		if disabled.Proxy.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// 停用的节点名称，用于刷新订阅时跳过这些节点
+func disabledProxyNames() map[string]bool {
+	names := make(map[string]bool)
+	store, err := loadDisabledProxies()
+	if err != nil {
+		return names
+	}
+	for _, disabled := range store.Proxies {
+		names[disabled.Proxy.Name] = true
+	}
+	return names
+}
+
+// 检查节点能否从配置中移除，被规则、relay 代理链或 dialer-proxy 直接引用的节点移除后配置无法加载
+func checkProxyRemovable(config *ClashConfig, name string) error {
+	for _, text := range config.Rules {
+		if target, err := ruleTarget(text); err == nil && target == name {
+			return errorOf(ErrReferencedByRules, T("节点 %s 仍被规则 %s 使用"), name, text)
+		}
+	}
+	for _, other := range config.Proxies {
+		if other.GetString("dialer-proxy") == name {
+			return errorOf(ErrReferencedByChain, T("节点 %s 是代理链 %s 的前一跳"), name, other.Name)
+		}
+	}
+	for _, group := range config.ProxyGroups {
+		if group.Type == "relay" && containsString(group.Proxies, name) {
+			return errorOf(ErrReferencedByChain, T("节点 %s 是代理链 %s 的一跳"), name, group.Name)
+		}
+	}
+	return nil
+}
+
+// 将节点从配置移入停用列表，并记录它所在的代理组
+func disableProxy(config *ClashConfig, store *disabledProxyStore, name string) error {
+	proxy := config.FindProxy(name)
+	if proxy == nil {
+		return errorOf(ErrProxyNotFound, T("节点 %s 不存在"), name)
+	}
+	if store.find(name) >= 0 {
+		return errorOf(ErrNameConflict, T("停用列表中已有同名节点 %s"), name)
+	}
+
+	if err := checkProxyRemovable(config, name); err != nil {
+		return err
+	}
+
+	disabled := DisabledProxy{Proxy: *proxy, DisabledAt: time.Now()}
+	for _, group := range config.ProxyGroups {
+		for i, member := range group.Proxies {
+			if member != name {
+				continue
+			}
+			disabled.Groups = append(disabled.Groups, DisabledMembership{
+				Group:    group.Name,
+				Index:    i,
+				Selected: group.Selected == name,
+			})
+		}
+	}
+
+	config.RemoveProxy(name)
+	removeFromProxyGroups(config, name)
+	store.Proxies = append(store.Proxies, disabled)
+	return nil
+}
+
+// 将停用的节点放回配置，恢复它在原代理组中的位置
+// 已删除的代理组会被跳过，返回这些代理组的名称
+func enableProxy(config *ClashConfig, store *disabledProxyStore, name string) ([]string, error) {
+	index := store.find(name)
+	if index < 0 {
+		return nil, errorOf(ErrProxyNotDisabled, T("节点 %s 不在停用列表中"), name)
+	}
+	if policyNameExists(config, name) {
+		return nil, errorOf(ErrNameConflict, T("配置中已存在名为 %s 的节点或代理组"), name)
+	}
+
+	var skipped []string
+	disabled := store.Proxies[index]
+	config.Proxies = append(config.Proxies, disabled.Proxy)
+	for _, membership := range disabled.Groups {
+		group := config.FindGroup(membership.Group)
+		if group == nil {
+			skipped = append(skipped, membership.Group)
+			continue
+		}
+		if containsString(group.Proxies, name) {
+			continue
+		}
+
+		position := membership.Index
+		if position > len(group.Proxies) {
+			position = len(group.Proxies)
+		}
+		group.Proxies = append(group.Proxies[:position], append([]string{name}, group.Proxies[position:]...)...)
+		if membership.Selected {
+			group.Selected = name
+		}
+	}
+
+	store.Proxies = append(store.Proxies[:index], store.Proxies[index+1:]...)
+	return skipped, nil
+}
+
+// 提示启用节点时跳过的代理组
+func printSkippedGroups(groups []string) {
+	for _, group := range groups {
+		fmt.Printf(T("代理组 %s 已不存在，跳过\n"), group)
+	}
+}
+
+// 停用节点列表的文字
+func formatDisabledProxies(store *disabledProxyStore) string {
+	var b strings.Builder
+	if len(store.Proxies) == 0 {
+		fmt.Fprintln(&b, T("没有停用的节点"))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, T("共有 %d 个停用的节点:\n\n"), len(store.Proxies))
+	for i, disabled := range store.Proxies {
+		var groups []string
+		for _, membership := range disabled.Groups {
+			groups = append(groups, membership.Group)
+		}
+		fmt.Fprintf(&b, "%d. %s (%s %s:%s)\n", i+1, disabled.Proxy.Name, disabled.Proxy.Type, disabled.Proxy.Server, disabled.Proxy.Port)
+		fmt.Fprintf(&b, T("   停用于 %s"), disabled.DisabledAt.Format("2006-01-02 15:04:05"))
+		if len(groups) > 0 {
+			fmt.Fprintf(&b, T("，原代理组: %s"), strings.Join(groups, ", "))
+		}
+		fmt.Fprintln(&b)
+	}
+	return b.String()
+}
+
+// 打印停用的节点
+func printDisabledProxies(store *disabledProxyStore) {
+	fmt.Print(formatDisabledProxies(store))
+}
+
+// 保存配置和停用列表
+// 停用时先保存停用列表，确保节点配置不会丢失；启用时先保存配置，避免节点在两处都不存在
+func saveDisabledChange(config *ClashConfig, store *disabledProxyStore, previous *disabledProxyStore, disabling bool, save func(*ClashConfig) (bool, error)) (bool, error) {
+	if disabling {
+		if err := saveDisabledProxies(store); err != nil {
+			return false, fmt.Errorf(T("保存停用列表失败: %w"), err)
+		}
+		saved, err := save(config)
+		if err != nil || !saved {
+			// 配置未保存，恢复停用列表
+			if restoreErr := saveDisabledProxies(previous); restoreErr != nil {
+				fmt.Printf(T("恢复停用列表失败: %v\n"), restoreErr)
+			}
+		}
+		return saved, err
+	}
+
+	saved, err := save(config)
+	if err != nil || !saved {
+		return saved, err
+	}
+	if err := saveDisabledProxies(store); err != nil {
+		return true, fmt.Errorf(T("配置已保存，但更新停用列表失败: %w"), err)
+	}
+	return true, nil
+}
+
+// 处理 proxy disable|enable|disabled 子命令
+func manageDisabledProxies(args []string) {
+	store, err := loadDisabledProxies()
+	if err != nil {
+		fmt.Printf(T("读取停用列表失败: %v\n"), err)
+		os.Exit(1)
+	}
+	if args[0] == "disabled" {
+		printDisabledProxies(store)
+		return
+	}
+	if len(args) < 2 {
+		fmt.Printf(T("用法: %s proxy %s <节点名称>...\n"), os.Args[0], args[0])
+		os.Exit(1)
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		os.Exit(1)
+	}
+	current, _ := readClashConfig()
+	previous, _ := loadDisabledProxies()
+
+	disabling := args[0] == "disable"
+	for _, name := range args[1:] {
+		if disabling {
+			err = disableProxy(config, store, name)
+		} else {
+			var skipped []string
+			skipped, err = enableProxy(config, store, name)
+			printSkippedGroups(skipped)
+		}
+		if err != nil {
+			fmt.Printf(T("proxy %s 失败: %v\n"), args[0], err)
+			os.Exit(1)
+		}
+	}
+
+	printConfigChanges(diffClashConfigs(current, config))
+	_, err = saveDisabledChange(config, store, previous, disabling, func(config *ClashConfig) (bool, error) {
+		return true, saveClashConfig(config)
+	})
+	if err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+		os.Exit(1)
+	}
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+}
+
+// 交互式停用或启用节点
+func interactiveToggleProxies() {
+	clearScreen()
+	fmt.Println(T("===== 停用/启用节点 ====="))
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+	store, err := loadDisabledProxies()
+	if err != nil {
+		fmt.Printf(T("读取停用列表失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+	previous, _ := loadDisabledProxies()
+
+	items := []string{T("停用节点"), T("启用节点")}
+	choice := selectMenu(T("停用/启用节点"), textLines(formatDisabledProxies(store)), items, T("返回")) + 1
+
+	reader := stdinReader
+	disabling := choice == 1
+	switch choice {
+	case 1:
+		fmt.Println(T("\n当前节点:"))
+		for i, name := range config.ProxyNames() {
+			fmt.Printf("%d. %s\n", i+1, name)
+		}
+		for _, name := range promptList(reader, T("\n要停用的节点名称 (多个用逗号分隔): ")) {
+			if err = disableProxy(config, store, name); err != nil {
+				break
+			}
+		}
+	case 2:
+		printDisabledProxies(store)
+		for _, name := range promptList(reader, T("\n要启用的节点名称 (多个用逗号分隔): ")) {
+			var skipped []string
+			if skipped, err = enableProxy(config, store, name); err != nil {
+				break
+			}
+			printSkippedGroups(skipped)
+		}
+	default:
+		return
+	}
+	if err != nil {
+		fmt.Printf(T("操作失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+
+	saved, err := saveDisabledChange(config, store, previous, disabling, saveClashConfigWithConfirm)
+	if err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+	} else if saved {
+		promptRestartClash()
+	}
+	waitForKeyPress()
+}
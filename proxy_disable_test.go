@@ -0,0 +1,154 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const disableTestConfig = `
+proxies:
+  - {name: a, type: http, server: 1.1.1.1, port: 80}
+  - {name: b, type: http, server: 1.1.1.2, port: 80}
+  - {name: c, type: http, server: 1.1.1.3, port: 80}
+  - {name: via-c, type: http, server: 1.1.1.4, port: 80, dialer-proxy: c}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [a, b, c], selected: b}
+  - {name: Auto, type: url-test, proxies: [b, a], url: 'http://example.com', interval: 300}
+  - {name: Relay, type: relay, proxies: [a, c]}
+rules:
+  - DOMAIN,a.com,a
+  - MATCH,Proxy
+`
+
+func TestDisableAndEnableProxy(t *testing.T) {
+	config := mustParseConfig(t, disableTestConfig)
+	// 规则和代理链不引用 b，可以停用
+	store := &disabledProxyStore{}
+	if err := disableProxy(config, store, "b"); err != nil {
+		t.Fatalf("disableProxy: %v", err)
+	}
+	if config.FindProxy("b") != nil {
+		t.Errorf("停用后 b 仍在配置中")
+	}
+	if got := config.FindGroup("Proxy").Proxies; !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("停用后 Proxy 的成员 = %q", got)
+	}
+
+	// 停用期间组内成员发生变化，启用时按原下标插回
+	group := config.FindGroup("Auto")
+	group.Proxies = append(group.Proxies, "c")
+	skipped, err := enableProxy(config, store, "b")
+	if err != nil {
+		t.Fatalf("enableProxy: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("启用时跳过了代理组 %q", skipped)
+	}
+
+	if config.FindProxy("b") == nil {
+		t.Fatalf("启用后 b 不在配置中")
+	}
+	proxyGroup := config.FindGroup("Proxy")
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(proxyGroup.Proxies, want) {
+		t.Errorf("Proxy 的成员 = %q，期望 %q", proxyGroup.Proxies, want)
+	}
+	if proxyGroup.Selected != "b" {
+		t.Errorf("Proxy 选中的节点 = %q，期望 b", proxyGroup.Selected)
+	}
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(config.FindGroup("Auto").Proxies, want) {
+		t.Errorf("Auto 的成员 = %q，期望 %q", config.FindGroup("Auto").Proxies, want)
+	}
+	if len(store.Proxies) != 0 {
+		t.Errorf("启用后停用列表仍有 %d 个节点", len(store.Proxies))
+	}
+	if err := validateConfig(config); err != nil {
+		t.Errorf("启用后的配置无效: %v", err)
+	}
+}
+
+func TestEnableProxySkipsDeletedGroup(t *testing.T) {
+	config := mustParseConfig(t, disableTestConfig)
+	store := &disabledProxyStore{}
+	if err := disableProxy(config, store, "b"); err != nil {
+		t.Fatalf("disableProxy: %v", err)
+	}
+	if err := deleteProxyGroup(config, "Auto"); err != nil {
+		t.Fatalf("deleteProxyGroup: %v", err)
+	}
+
+	skipped, err := enableProxy(config, store, "b")
+	if err != nil {
+		t.Fatalf("enableProxy: %v", err)
+	}
+	if want := []string{"Auto"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("跳过的代理组 = %q，期望 %q", skipped, want)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(config.FindGroup("Proxy").Proxies, want) {
+		t.Errorf("Proxy 的成员 = %q，期望 %q", config.FindGroup("Proxy").Proxies, want)
+	}
+}
+
+func TestDisableProxyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxy   string
+		wantErr error
+	}{
+		{name: "节点不存在", proxy: "missing", wantErr: ErrProxyNotFound},
+		{name: "被规则使用", proxy: "a", wantErr: ErrReferencedByRules},
+		{name: "是 dialer-proxy 的前一跳", proxy: "c", wantErr: ErrReferencedByChain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := mustParseConfig(t, disableTestConfig)
+			store := &disabledProxyStore{}
+			expectConfigOp(t, config, tt.wantErr, func() error {
+				return disableProxy(config, store, tt.proxy)
+			})
+			if len(store.Proxies) != 0 {
+				t.Errorf("失败后停用列表被修改")
+			}
+		})
+	}
+
+	t.Run("是 relay 代理链的一跳", func(t *testing.T) {
+		config := mustParseConfig(t, disableTestConfig)
+		config.Rules = nil
+		config.FindProxy("via-c").Set("dialer-proxy", nil)
+		store := &disabledProxyStore{}
+		expectConfigOp(t, config, ErrReferencedByChain, func() error {
+			return disableProxy(config, store, "c")
+		})
+	})
+
+	t.Run("停用列表中已有同名节点", func(t *testing.T) {
+		config := mustParseConfig(t, disableTestConfig)
+		store := &disabledProxyStore{Proxies: []DisabledProxy{{Proxy: Proxy{Name: "b", Type: "http"}}}}
+		expectConfigOp(t, config, ErrNameConflict, func() error {
+			return disableProxy(config, store, "b")
+		})
+	})
+}
+
+func TestEnableProxyErrors(t *testing.T) {
+	t.Run("不在停用列表中", func(t *testing.T) {
+		config := mustParseConfig(t, disableTestConfig)
+		expectConfigOp(t, config, ErrProxyNotDisabled, func() error {
+			_, err := enableProxy(config, &disabledProxyStore{}, "x")
+			return err
+		})
+	})
+
+	t.Run("配置中已有同名节点", func(t *testing.T) {
+		config := mustParseConfig(t, disableTestConfig)
+		store := &disabledProxyStore{Proxies: []DisabledProxy{{Proxy: Proxy{Name: "a", Type: "http"}}}}
+		expectConfigOp(t, config, ErrNameConflict, func() error {
+			_, err := enableProxy(config, store, "a")
+			return err
+		})
+		if len(store.Proxies) != 1 {
+			t.Errorf("失败后停用列表被修改")
+		}
+	})
+}
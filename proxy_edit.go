@@ -0,0 +1,287 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 解析 --set 的值：原字段是字符串时保持字符串，避免纯数字的密码变成整数；其它按 YAML 解析
+func parseProxyFieldValue(proxy *Proxy, key, value string) interface{} {
+	if current, ok := proxy.Extra[key]; ok {
+		if _, isString := current.(string); isString {
+			return value
+		}
+	}
+
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil || parsed == nil {
+		return value
+	}
+	return parsed
+}
+
+// 设置节点字段，名称需要通过 renameProxy 修改
+func setProxyField(proxy *Proxy, key, value string) error {
+	switch key {
+	case "":
+		return errors.New(T("字段名不能为空"))
+	case "name":
+		return errors.New(T("请通过重命名修改节点名称"))
+	case "type", "server":
+		if value == "" {
+			return fmt.Errorf(T("%s 不能为空"), key)
+		}
+		proxy.Set(key, value)
+	case "port":
+		port, err := parsePortValue(value)
+		if err != nil {
+			return fmt.Errorf(T("无效的端口: %s"), value)
+		}
+		proxy.Port = port
+	default:
+		proxy.Set(key, parseProxyFieldValue(proxy, key, value))
+	}
+	return nil
+}
+
+// 删除节点的协议字段
+func unsetProxyField(proxy *Proxy, key string) error {
+	switch key {
+	case "name", "type", "server", "port":
+		return fmt.Errorf(T("不能删除字段 %s"), key)
+	}
+	if !proxy.Has(key) {
+		return fmt.Errorf(T("节点 %s 没有字段 %s"), proxy.Name, key)
+	}
+	delete(proxy.Extra, key)
+	return nil
+}
+
+// 重命名节点，同时更新代理组成员、选中的节点、规则目标和 dialer-proxy 引用
+func renameProxy(config *ClashConfig, old, new string) error {
+	proxy := config.FindProxy(old)
+	if proxy == nil {
+		return errorOf(ErrProxyNotFound, T("节点 %s 不存在"), old)
+	}
+	if new == old {
+		return nil
+	}
+	if new == "" || strings.Contains(new, ",") {
+		return fmt.Errorf(T("无效的节点名称: %q"), new)
+	}
+	if policyNameExists(config, new) {
+		return errorOf(ErrNameConflict, T("名称 %s 已被节点、代理组或内置策略使用"), new)
+	}
+
+	proxy.Name = new
+	renamePolicyReferences(config, old, new)
+	return nil
+}
+
+// 修改节点字段，sets 中的 name 表示重命名；修改后的节点需要通过与添加节点相同的检查
+func editProxy(config *ClashConfig, name string, sets [][2]string, unsets []string) error {
+	proxy := config.FindProxy(name)
+	if proxy == nil {
+		return errorOf(ErrProxyNotFound, T("节点 %s 不存在"), name)
+	}
+
+	// 在副本上修改，检查通过后再替换，避免留下改了一半的节点
+	edited, err := cloneProxy(proxy)
+	if err != nil {
+		return err
+	}
+	newName := name
+	for _, set := range sets {
+		if set[0] == "name" {
+			newName = set[1]
+			continue
+		}
+		if err := setProxyField(&edited, set[0], set[1]); err != nil {
+			return err
+		}
+	}
+	for _, key := range unsets {
+		if err := unsetProxyField(&edited, key); err != nil {
+			return err
+		}
+	}
+	if problems := validateProxy(&edited); len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+
+	*proxy = edited
+	return renameProxy(config, name, newName)
+}
+
+// 拆分 proxy edit 的参数: --set key=value 和 --unset key
+func parseProxyEditArgs(args []string) ([][2]string, []string, error) {
+	_, options, err := parseGroupArgs(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sets [][2]string
+	var unsets []string
+	for _, option := range options {
+		switch option[0] {
+		case "set":
+			key, value, ok := strings.Cut(option[1], "=")
+			if !ok {
+				return nil, nil, fmt.Errorf(T("--set 的格式为 key=value: %s"), option[1])
+			}
+			sets = append(sets, [2]string{strings.TrimSpace(key), value})
+		case "unset":
+			unsets = append(unsets, option[1])
+		default:
+			return nil, nil, fmt.Errorf(T("未知的参数: --%s"), option[0])
+		}
+	}
+	if len(sets) == 0 && len(unsets) == 0 {
+		return nil, nil, errors.New(T("没有指定要修改的字段"))
+	}
+	return sets, unsets, nil
+}
+
+// 处理 proxy edit 子命令
+func manageProxyEdit(args []string) {
+	if len(args) < 2 {
+		fmt.Printf(T("用法: %s proxy edit <节点名称> --set key=value... [--unset key]...\n"), os.Args[0])
+		fmt.Println(T("\n--set name=<新名称> 重命名节点，并更新代理组和规则中的引用"))
+		fmt.Println(T("字段值按 YAML 解析，原字段是字符串时保持为字符串"))
+		os.Exit(1)
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		os.Exit(1)
+	}
+	current, _ := readClashConfig()
+
+	sets, unsets, err := parseProxyEditArgs(args[1:])
+	if err == nil {
+		err = editProxy(config, args[0], sets, unsets)
+	}
+	if err != nil {
+		fmt.Printf(T("修改节点失败: %v\n"), err)
+		os.Exit(1)
+	}
+
+	printConfigChanges(diffClashConfigs(current, config))
+	if err := saveClashConfig(config); err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+		os.Exit(1)
+	}
+	moveRenamedProxyMeta(args[0], sets)
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+}
+
+// 交互式编辑节点，显示当前值，直接回车保持不变
+func interactiveEditProxy() {
+	clearScreen()
+	fmt.Println(T("===== 编辑代理节点 ====="))
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+	proxyNames := config.ProxyNames()
+	if len(proxyNames) == 0 {
+		fmt.Println(T("没有配置任何代理节点"))
+		waitForKeyPress()
+		return
+	}
+	choice := selectMenu(T("编辑代理节点"), nil, proxyNames, T("取消"))
+	if choice < 0 {
+		return
+	}
+	proxy := config.FindProxy(proxyNames[choice])
+	fmt.Printf(T("===== 编辑代理节点 %s =====\n"), proxy.Name)
+
+	reader := stdinReader
+	var sets [][2]string
+	prompt := func(key, current string) {
+		if value := promptLine(reader, fmt.Sprintf("%s [%s]: ", key, current)); value != "" && value != current {
+			sets = append(sets, [2]string{key, value})
+		}
+	}
+
+	fmt.Println(T("\n直接回车保持当前值"))
+	prompt("name", proxy.Name)
+	prompt("type", proxy.Type)
+	prompt("server", proxy.Server)
+	prompt("port", proxy.Port.String())
+	for _, key := range sortedKeys(proxy.Extra) {
+		switch proxy.Extra[key].(type) {
+		case map[string]interface{}, []interface{}:
+			fmt.Printf(T("%s: (嵌套字段，请使用 proxy edit --set 修改)\n"), key)
+		default:
+			prompt(key, proxy.GetString(key))
+		}
+	}
+	for {
+		field := promptLine(reader, T("添加其它字段 (key=value，直接回车结束): "))
+		if field == "" {
+			break
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			fmt.Println(T("格式应为 key=value"))
+			continue
+		}
+		sets = append(sets, [2]string{strings.TrimSpace(key), value})
+	}
+
+	if len(sets) == 0 {
+		fmt.Println(T("没有修改"))
+		waitForKeyPress()
+		return
+	}
+	if err := editProxy(config, proxy.Name, sets, nil); err != nil {
+		fmt.Printf(T("修改节点失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+
+	saved, err := saveClashConfigWithConfirm(config)
+	if err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+	} else if saved {
+		moveRenamedProxyMeta(proxyNames[choice], sets)
+		promptRestartClash()
+	}
+	waitForKeyPress()
+}
+
+// 配置保存后，将重命名节点的标签、测速结果和订阅记录转到新名称下
+func moveRenamedProxyMeta(name string, sets [][2]string) {
+	newName := name
+	for _, set := range sets {
+		if set[0] == "name" {
+			newName = set[1]
+		}
+	}
+	if newName == name {
+		return
+	}
+
+	settings, err := loadManagerSettings()
+	if err != nil {
+		fmt.Printf(T("读取设置失败，节点标签未更新: %v\n"), err)
+		return
+	}
+	_, hasMeta := settings.ProxyMeta[name]
+	renameProxyMeta(settings, name, newName)
+	if !renameSubscriptionNode(settings, name, newName) && !hasMeta {
+		return
+	}
+	if err := saveManagerSettings(settings); err != nil {
+		fmt.Printf(T("保存节点标签失败: %v\n"), err)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 代理地址设置为该值时，通过本机已运行的 Clash 下载订阅
+const localClashProxyKeyword = "clash"
+
+// 下载订阅时使用的 HTTP 选项
+type SubscriptionFetchOptions struct {
+	// 请求使用的 User-Agent，部分面板会根据它返回不同格式（如 clash.meta 返回 YAML）
+	UserAgent string `yaml:"user-agent,omitempty"`
+	// 额外的请求头，例如认证信息
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// 上游代理地址，支持 http/https/socks5，填写 "clash" 表示使用本机 Clash
+	Proxy string `yaml:"proxy,omitempty"`
+	// 单次请求超时时间，单位秒
+	Timeout int `yaml:"timeout,omitempty"`
+	// 失败后的重试次数
+	Retries int `yaml:"retries,omitempty"`
+	// 首次重试前的等待时间，单位秒，之后每次翻倍
+	RetryBackoff int `yaml:"retry-backoff,omitempty"`
+	// 自定义 CA 证书文件 (PEM)，会追加到系统证书之后
+	CAFile string `yaml:"ca-file,omitempty"`
+}
+
+// 默认的订阅下载选项
+func defaultSubscriptionFetchOptions() SubscriptionFetchOptions {
+	return SubscriptionFetchOptions{
+		Timeout:      30,
+		Retries:      2,
+		RetryBackoff: 2,
+	}
+}
+
+// 根据下载选项创建 HTTP 客户端
+func newSubscriptionHTTPClient(opts SubscriptionFetchOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// 配置上游代理
+	if opts.Proxy != "" {
+		proxyStr := opts.Proxy
+		if strings.EqualFold(proxyStr, localClashProxyKeyword) {
+			proxyStr = localClashProxyURL()
+		}
+
+		proxyURL, err := url.Parse(proxyStr)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf(T("无效的代理地址: %s"), opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	// 配置自定义 CA 证书
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf(T("读取 CA 证书失败: %w"), err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf(T("CA 证书文件 %s 中没有有效的证书"), opts.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultSubscriptionFetchOptions().Timeout
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(timeout) * time.Second,
+	}, nil
+}
+
+// 一次订阅请求的结果
+type subscriptionResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	// 服务端返回 304，内容与缓存一致
+	NotModified bool
+}
+
+// 按照下载选项获取订阅内容，失败时按指数退避重试
+func fetchSubscription(subURL string, opts SubscriptionFetchOptions) ([]byte, error) {
+	resp, err := fetchSubscriptionConditional(subURL, opts, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// 带条件请求头获取订阅内容，etag 和 lastModified 为空时发送普通请求
+func fetchSubscriptionConditional(subURL string, opts SubscriptionFetchOptions, etag, lastModified string) (*subscriptionResponse, error) {
+	client, err := newSubscriptionHTTPClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := time.Duration(opts.RetryBackoff) * time.Second
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			fmt.Printf(T("第 %d 次重试，等待 %v...\n"), attempt, backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		resp, retry, err := doSubscriptionRequest(client, subURL, opts, etag, lastModified)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// 发送一次订阅请求，返回内容以及失败时是否值得重试
+func doSubscriptionRequest(client *http.Client, subURL string, opts SubscriptionFetchOptions, etag, lastModified string) (*subscriptionResponse, bool, error) {
+	req, err := http.NewRequest("GET", subURL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	for key, value := range opts.Headers {
+		// net/http 忽略 Header 中的 Host，需要设置 req.Host
+		if strings.EqualFold(key, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &subscriptionResponse{NotModified: true}, false, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// 服务端错误和限流可以重试，其他状态码重试也不会成功
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retry, fmt.Errorf(T("订阅服务器返回状态码 %d"), resp.StatusCode)
+	}
+
+	return &subscriptionResponse{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, false, nil
+}
+
+// 获取本机 Clash 的 HTTP 代理地址
+func localClashProxyURL() string {
+	port := "7890"
+
+	if config, err := readClashConfig(); err == nil {
+		// 优先使用 mixed-port，其次使用 HTTP 代理端口
+		if config.MixedPort.Number != 0 {
+			port = config.MixedPort.String()
+		} else if config.Port.Number != 0 {
+			port = config.Port.String()
+		}
+	}
+
+	return "http://127.0.0.1:" + port
+}
+
+// 读取保存的下载选项，并询问用户是否需要调整
+func promptSubscriptionFetchOptions() SubscriptionFetchOptions {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		fmt.Printf(T("读取设置失败，将使用默认下载选项: %v\n"), err)
+		settings = &ManagerSettings{SubscriptionFetch: defaultSubscriptionFetchOptions()}
+	}
+	opts := settings.SubscriptionFetch
+
+	fmt.Print(T("是否自定义下载选项(User-Agent/请求头/代理/超时/CA证书)？[y/n]: "))
+	custom, _ := readLine()
+	if strings.ToLower(custom) != "y" {
+		return opts
+	}
+
+	opts = collectSubscriptionFetchOptions(opts)
+
+	fmt.Print(T("是否将这些选项保存为默认设置？[y/n]: "))
+	save, _ := readLine()
+	if strings.ToLower(save) == "y" {
+		settings.SubscriptionFetch = opts
+		if err := saveManagerSettings(settings); err != nil {
+			fmt.Printf(T("保存设置失败: %v\n"), err)
+		} else {
+			fmt.Printf(T("下载选项已保存到 %s\n"), managerSettingsPath())
+		}
+	}
+
+	return opts
+}
+
+// 交互式收集下载选项，直接回车保留当前值
+func collectSubscriptionFetchOptions(current SubscriptionFetchOptions) SubscriptionFetchOptions {
+	reader := stdinReader
+	opts := current
+
+	// 复制请求头，避免修改调用方持有的设置
+	opts.Headers = make(map[string]string, len(current.Headers))
+	for key, value := range current.Headers {
+		opts.Headers[key] = value
+	}
+
+	readValue := func(prompt, value string) string {
+		fmt.Printf(T("%s(当前为%q): "), prompt, value)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return value
+		}
+		if input == "-" {
+			return ""
+		}
+		return input
+	}
+
+	// 数字选项不能清空，输入无效时重新询问
+	readNumber := func(prompt string, value, min int) int {
+		for {
+			fmt.Printf(T("%s(当前为%d): "), prompt, value)
+			input, err := reader.ReadString('\n')
+			input = strings.TrimSpace(input)
+			if input == "" {
+				return value
+			}
+			if n, convErr := strconv.Atoi(input); convErr == nil && n >= min {
+				return n
+			}
+			fmt.Printf(T("请输入不小于 %d 的整数\n"), min)
+			if err != nil {
+				return value
+			}
+		}
+	}
+
+	fmt.Println(T("\n直接回车保留当前值，输入 - 清空"))
+	opts.UserAgent = readValue(T("User-Agent (例如 clash.meta)"), opts.UserAgent)
+	opts.Proxy = readValue(T("上游代理 (例如 socks5://127.0.0.1:1080，输入 clash 使用本机 Clash)"), opts.Proxy)
+	opts.CAFile = readValue(T("CA 证书文件路径"), opts.CAFile)
+
+	opts.Timeout = readNumber(T("超时时间，单位秒"), opts.Timeout, 1)
+	opts.Retries = readNumber(T("重试次数"), opts.Retries, 0)
+	opts.RetryBackoff = readNumber(T("首次重试前的等待时间，单位秒，之后每次翻倍"), opts.RetryBackoff, 1)
+
+	// 请求头逐行输入，格式为 Key: Value
+	fmt.Println(T("请输入额外的请求头，格式为 Key: Value，每行一个，空行结束:"))
+	for {
+		fmt.Print("> ")
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" || err != nil {
+			break
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			fmt.Println(T("格式无效，已忽略"))
+			continue
+		}
+
+		opts.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return opts
+}
@@ -0,0 +1,753 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// url-test、fallback、load-balance 代理组默认的健康检查间隔 (秒)
+const defaultGroupInterval = 300
+
+// url-test 代理组默认的切换容差 (毫秒)
+const defaultGroupTolerance = 50
+
+// 代理组类型，按菜单中显示的顺序排列
+var proxyGroupTypeList = []string{"select", "url-test", "fallback", "load-balance", "relay"}
+
+// load-balance 代理组支持的负载均衡策略
+var loadBalanceStrategies = map[string]bool{
+	"consistent-hashing": true,
+	"round-robin":        true,
+	"sticky-sessions":    true,
+}
+
+// 是否为需要健康检查的代理组类型
+func isHealthCheckGroup(groupType string) bool {
+	return groupType == "url-test" || groupType == "fallback" || groupType == "load-balance"
+}
+
+// 补全代理组类型所需的参数，缺少 url 或 interval 的 url-test 等代理组无法被 Clash 加载
+func applyGroupDefaults(group *ProxyGroup) {
+	if isHealthCheckGroup(group.Type) {
+		if group.URL == "" {
+			group.URL = defaultHealthCheckURL
+		}
+		if group.Interval <= 0 {
+			group.Interval = defaultGroupInterval
+		}
+	}
+	if group.Type == "url-test" && group.Tolerance <= 0 {
+		group.Tolerance = defaultGroupTolerance
+	}
+	if group.Type == "load-balance" && group.Strategy == "" {
+		group.Strategy = "consistent-hashing"
+	}
+}
+
+// 修改代理组类型时清除新类型不使用的参数
+func clearGroupTypeFields(group *ProxyGroup) {
+	if !isHealthCheckGroup(group.Type) {
+		group.URL = ""
+		group.Interval = 0
+		group.Lazy = nil
+	}
+	if group.Type != "url-test" {
+		group.Tolerance = 0
+	}
+	if group.Type != "load-balance" {
+		group.Strategy = ""
+	}
+	if group.Type != "select" {
+		group.Selected = ""
+	}
+}
+
+// 名称是否已被节点或代理组使用
+func policyNameExists(config *ClashConfig, name string) bool {
+	if builtinPolicies[name] || config.FindGroup(name) != nil {
+		return true
+	}
+	for _, proxy := range config.Proxies {
+		if proxy.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// 主代理组：第一个不在 skip 中的 select 组，用于放置地区选择、代理链等入口
+func primarySelectGroup(config *ClashConfig, skip map[string]bool) *ProxyGroup {
+	for i := range config.ProxyGroups {
+		group := &config.ProxyGroups[i]
+		if group.Type == "select" && !skip[group.Name] {
+			return group
+		}
+	}
+	return nil
+}
+
+// 检查成员是否可以加入代理组
+func checkGroupMember(config *ClashConfig, group *ProxyGroup, member string) error {
+	if member == group.Name {
+		return errors.New(T("代理组不能包含自身"))
+	}
+	if !policyNameExists(config, member) {
+		return fmt.Errorf(T("成员 %s 不是已有的节点、代理组或内置策略"), member)
+	}
+	if group.Type == "relay" && builtinPolicies[member] {
+		return fmt.Errorf(T("relay 代理组不能包含内置策略 %s"), member)
+	}
+	if containsString(group.Proxies, member) {
+		return fmt.Errorf(T("%s 已经是代理组 %s 的成员"), member, group.Name)
+	}
+	return nil
+}
+
+// 设置代理组参数，key 为 url、interval、tolerance、lazy、strategy 或 use
+func setGroupOption(group *ProxyGroup, key, value string) error {
+	switch key {
+	case "url":
+		if !isHealthCheckGroup(group.Type) {
+			return fmt.Errorf(T("%s 代理组不使用 url"), group.Type)
+		}
+		if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			return fmt.Errorf(T("无效的测试地址: %s"), value)
+		}
+		group.URL = value
+	case "interval", "tolerance":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf(T("%s 必须是正整数: %s"), key, value)
+		}
+		if key == "interval" {
+			if !isHealthCheckGroup(group.Type) {
+				return fmt.Errorf(T("%s 代理组不使用 interval"), group.Type)
+			}
+			group.Interval = n
+		} else {
+			if group.Type != "url-test" {
+				return errors.New(T("只有 url-test 代理组使用 tolerance"))
+			}
+			group.Tolerance = n
+		}
+	case "lazy":
+		if !isHealthCheckGroup(group.Type) {
+			return fmt.Errorf(T("%s 代理组不使用 lazy"), group.Type)
+		}
+		lazy, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf(T("lazy 只能是 true 或 false: %s"), value)
+		}
+		group.Lazy = &lazy
+	case "strategy":
+		if group.Type != "load-balance" {
+			return errors.New(T("只有 load-balance 代理组使用 strategy"))
+		}
+		if !loadBalanceStrategies[value] {
+			return fmt.Errorf(T("不支持的负载均衡策略: %s (可选 consistent-hashing、round-robin、sticky-sessions)"), value)
+		}
+		group.Strategy = value
+	case "use":
+		group.Use = splitList(value)
+	default:
+		return fmt.Errorf(T("未知的代理组参数: %s"), key)
+	}
+	return nil
+}
+
+// 创建代理组，参数未设置时使用默认值
+func createProxyGroup(config *ClashConfig, group ProxyGroup, members []string) error {
+	if group.Name == "" {
+		return errors.New(T("代理组名称不能为空"))
+	}
+	if strings.Contains(group.Name, ",") {
+		return errors.New(T("代理组名称不能包含逗号，否则无法在规则中引用"))
+	}
+	if policyNameExists(config, group.Name) {
+		return errorOf(ErrNameConflict, T("名称 %s 已被节点、代理组或内置策略使用"), group.Name)
+	}
+	if !proxyGroupTypes[group.Type] {
+		return fmt.Errorf(T("不支持的代理组类型: %s (可选 %s)"), group.Type, strings.Join(proxyGroupTypeList, "、"))
+	}
+	for _, member := range members {
+		if err := checkGroupMember(config, &group, member); err != nil {
+			return err
+		}
+		group.Proxies = append(group.Proxies, member)
+	}
+	if len(group.Proxies) == 0 && len(group.Use) == 0 {
+		return errors.New(T("代理组至少需要一个成员或 proxy-provider"))
+	}
+
+	applyGroupDefaults(&group)
+	config.ProxyGroups = append(config.ProxyGroups, group)
+	return nil
+}
+
+// 删除代理组，并从其它代理组中移除对它的引用；仍被规则使用的代理组不能删除
+func deleteProxyGroup(config *ClashConfig, name string) error {
+	if config.FindGroup(name) == nil {
+		return errorOf(ErrGroupNotFound, T("代理组 %s 不存在"), name)
+	}
+
+	var refs []string
+	for _, text := range config.Rules {
+		if target, err := ruleTarget(text); err == nil && target == name {
+			refs = append(refs, text)
+		}
+	}
+	if len(refs) > 0 {
+		return errorOf(ErrReferencedByRules, T("代理组 %s 仍被 %d 条规则使用: %s"), name, len(refs), strings.Join(refs, "; "))
+	}
+	// 代理组可以作为 dialer-proxy 链的第一跳
+	for _, proxy := range config.Proxies {
+		if proxy.GetString("dialer-proxy") == name {
+			return errorOf(ErrReferencedByChain, T("代理组 %s 是代理链 %s 的前一跳"), name, proxy.Name)
+		}
+	}
+
+	var groups []ProxyGroup
+	for _, group := range config.ProxyGroups {
+		if group.Name != name {
+			groups = append(groups, group)
+		}
+	}
+	config.ProxyGroups = groups
+	removeFromProxyGroups(config, name)
+	return nil
+}
+
+// 向代理组添加成员
+func addGroupMembers(config *ClashConfig, name string, members []string) error {
+	group := config.FindGroup(name)
+	if group == nil {
+		return errorOf(ErrGroupNotFound, T("代理组 %s 不存在"), name)
+	}
+	for _, member := range members {
+		if err := checkGroupMember(config, group, member); err != nil {
+			return err
+		}
+		group.Proxies = append(group.Proxies, member)
+	}
+	return nil
+}
+
+// 从代理组移除成员，移除选中的成员时改为选中第一个成员
+func removeGroupMembers(config *ClashConfig, name string, members []string) error {
+	group := config.FindGroup(name)
+	if group == nil {
+		return errorOf(ErrGroupNotFound, T("代理组 %s 不存在"), name)
+	}
+
+	remove := make(map[string]bool)
+	for _, member := range members {
+		if !containsString(group.Proxies, member) {
+			return fmt.Errorf(T("%s 不是代理组 %s 的成员"), member, name)
+		}
+		remove[member] = true
+	}
+
+	var kept []string
+	for _, member := range group.Proxies {
+		if !remove[member] {
+			kept = append(kept, member)
+		}
+	}
+	if len(kept) == 0 && len(group.Use) == 0 {
+		return fmt.Errorf(T("代理组 %s 至少需要保留一个成员"), name)
+	}
+	group.Proxies = kept
+	if remove[group.Selected] {
+		group.Selected = ""
+		if group.Type == "select" && len(kept) > 0 {
+			group.Selected = kept[0]
+		}
+	}
+	return nil
+}
+
+// 将代理组或节点的所有引用从 old 改为 new，包括代理组成员、选中的节点、规则目标和 dialer-proxy
+func renamePolicyReferences(config *ClashConfig, old, new string) {
+	for i := range config.ProxyGroups {
+		group := &config.ProxyGroups[i]
+		for j, member := range group.Proxies {
+			if member == old {
+				group.Proxies[j] = new
+			}
+		}
+		if group.Selected == old {
+			group.Selected = new
+		}
+	}
+	for i, text := range config.Rules {
+		if rule, err := parseRule(text); err == nil && rule.Target == old {
+			rule.Target = new
+			config.Rules[i] = rule.String()
+		}
+	}
+	for i := range config.Proxies {
+		if config.Proxies[i].GetString("dialer-proxy") == old {
+			config.Proxies[i].Set("dialer-proxy", new)
+		}
+	}
+}
+
+// 重命名代理组
+func renameProxyGroup(config *ClashConfig, old, new string) error {
+	group := config.FindGroup(old)
+	if group == nil {
+		return errorOf(ErrGroupNotFound, T("代理组 %s 不存在"), old)
+	}
+	if new == old {
+		return nil
+	}
+	if new == "" || strings.Contains(new, ",") {
+		return fmt.Errorf(T("无效的代理组名称: %q"), new)
+	}
+	if policyNameExists(config, new) {
+		return errorOf(ErrNameConflict, T("名称 %s 已被节点、代理组或内置策略使用"), new)
+	}
+	group.Name = new
+	renamePolicyReferences(config, old, new)
+	return nil
+}
+
+// 修改代理组类型，清除新类型不使用的参数并补全默认值
+func changeGroupType(config *ClashConfig, group *ProxyGroup, groupType string) error {
+	if !proxyGroupTypes[groupType] {
+		return fmt.Errorf(T("不支持的代理组类型: %s (可选 %s)"), groupType, strings.Join(proxyGroupTypeList, "、"))
+	}
+	if groupType == "relay" {
+		for _, member := range group.Proxies {
+			if builtinPolicies[member] {
+				return fmt.Errorf(T("relay 代理组不能包含内置策略 %s，请先移除该成员"), member)
+			}
+		}
+	}
+	group.Type = groupType
+	clearGroupTypeFields(group)
+	if groupType == "select" && len(group.Proxies) > 0 {
+		group.Selected = group.Proxies[0]
+	}
+	applyGroupDefaults(group)
+	return nil
+}
+
+// 处理 group 子命令
+func manageProxyGroups(args []string) {
+	if len(args) == 0 {
+		printGroupUsage()
+		os.Exit(1)
+	}
+
+	// regions 自行读取和保存配置
+	if args[0] == "regions" {
+		if err := manageRegionGroups(args[1:]); err != nil {
+			fmt.Printf(T("group regions 失败: %v\n"), err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		os.Exit(1)
+	}
+	// 修改前的配置，用于显示差异
+	current, _ := readClashConfig()
+
+	positional, options, err := parseGroupArgs(args[1:])
+	if err != nil {
+		fmt.Printf(T("group %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		printProxyGroups(config)
+		return
+	case "create":
+		if len(positional) < 2 {
+			printGroupUsage()
+			os.Exit(1)
+		}
+		group := ProxyGroup{Name: positional[0], Type: positional[1]}
+		for _, option := range options {
+			if err = setGroupOption(&group, option[0], option[1]); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = createProxyGroup(config, group, positional[2:])
+		}
+	case "edit":
+		if len(positional) != 1 || len(options) == 0 {
+			printGroupUsage()
+			os.Exit(1)
+		}
+		err = editProxyGroup(config, positional[0], options)
+	case "delete":
+		if len(positional) != 1 {
+			printGroupUsage()
+			os.Exit(1)
+		}
+		err = deleteProxyGroup(config, positional[0])
+	case "add-member":
+		if len(positional) < 2 {
+			printGroupUsage()
+			os.Exit(1)
+		}
+		err = addGroupMembers(config, positional[0], positional[1:])
+	case "remove-member":
+		if len(positional) < 2 {
+			printGroupUsage()
+			os.Exit(1)
+		}
+		err = removeGroupMembers(config, positional[0], positional[1:])
+	default:
+		fmt.Printf(T("未知的 group 子命令: %s\n"), args[0])
+		printGroupUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf(T("group %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+
+	// 命令行模式通常用于脚本，只打印修改内容，不要求确认
+	printConfigChanges(diffClashConfigs(current, config))
+	if err := saveClashConfig(config); err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+		os.Exit(1)
+	}
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+}
+
+// 拆分位置参数和 --key value 形式的选项，选项保持输入顺序
+func parseGroupArgs(args []string) ([]string, [][2]string, error) {
+	var positional []string
+	var options [][2]string
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "--") {
+			positional = append(positional, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, nil, fmt.Errorf(T("%s 需要指定参数值"), args[i])
+		}
+		options = append(options, [2]string{strings.TrimPrefix(args[i], "--"), args[i+1]})
+		i++
+	}
+	return positional, options, nil
+}
+
+// 按选项修改代理组，先处理名称和类型，再设置其它参数
+func editProxyGroup(config *ClashConfig, name string, options [][2]string) error {
+	group := config.FindGroup(name)
+	if group == nil {
+		return errorOf(ErrGroupNotFound, T("代理组 %s 不存在"), name)
+	}
+
+	for _, option := range options {
+		switch option[0] {
+		case "name":
+			if err := renameProxyGroup(config, group.Name, option[1]); err != nil {
+				return err
+			}
+		case "type":
+			if err := changeGroupType(config, group, option[1]); err != nil {
+				return err
+			}
+		}
+	}
+	for _, option := range options {
+		if option[0] == "name" || option[0] == "type" {
+			continue
+		}
+		if err := setGroupOption(group, option[0], option[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 显示 group 子命令的用法
+func printGroupUsage() {
+	fmt.Printf(T("用法: %s group <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  list                                  列出所有代理组"))
+	fmt.Println(T("  create <名称> <类型> [成员...] [选项]  创建代理组，成员可以是节点、其它代理组或 DIRECT/REJECT"))
+	fmt.Println(T("  edit <名称> [--name 新名称] [--type 类型] [选项]"))
+	fmt.Println(T("                                        修改代理组，重命名时同步更新规则和其它代理组"))
+	fmt.Println(T("  delete <名称>                         删除代理组，仍被规则使用时拒绝删除"))
+	fmt.Println(T("  add-member <名称> <成员...>           添加成员"))
+	fmt.Println(T("  remove-member <名称> <成员...>        移除成员"))
+	fmt.Println(T("  regions [show|enable|update|disable]  按节点名称和 GeoIP 自动生成各地区的 url-test 代理组"))
+	fmt.Println(T("\n选项:"))
+	fmt.Println(T("  --url URL         健康检查地址 (url-test/fallback/load-balance)"))
+	fmt.Println(T("  --interval 秒     健康检查间隔"))
+	fmt.Println(T("  --tolerance 毫秒  url-test 切换节点的延迟容差"))
+	fmt.Println(T("  --lazy true|false 未使用时是否跳过健康检查"))
+	fmt.Println(T("  --strategy 策略   load-balance 策略: consistent-hashing、round-robin、sticky-sessions"))
+	fmt.Println(T("  --use a,b         使用的 proxy-providers"))
+	fmt.Printf(T("\n支持的类型: %s，relay 按成员顺序依次转发\n"), strings.Join(proxyGroupTypeList, "、"))
+}
+
+// 代理组列表的文字
+func formatProxyGroups(config *ClashConfig) string {
+	var b strings.Builder
+	if len(config.ProxyGroups) == 0 {
+		fmt.Fprintln(&b, T("配置文件中没有代理组"))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, T("共有 %d 个代理组:\n\n"), len(config.ProxyGroups))
+	for i, group := range config.ProxyGroups {
+		fmt.Fprintf(&b, "%d. %s (%s)\n", i+1, group.Name, group.Type)
+
+		var members []string
+		for _, member := range group.Proxies {
+			switch {
+			case config.FindGroup(member) != nil:
+				members = append(members, "["+member+"]")
+			case member == group.Selected:
+				members = append(members, member+" *")
+			default:
+				members = append(members, member)
+			}
+		}
+		if group.Type == "relay" {
+			fmt.Fprintf(&b, T("   转发链: %s\n"), strings.Join(members, " -> "))
+		} else if len(members) > 0 {
+			fmt.Fprintf(&b, T("   成员: %s\n"), strings.Join(members, ", "))
+		}
+		if len(group.Use) > 0 {
+			fmt.Fprintf(&b, "   proxy-providers: %s\n", strings.Join(group.Use, ", "))
+		}
+
+		var params []string
+		if group.URL != "" {
+			params = append(params, "url="+group.URL)
+		}
+		if group.Interval > 0 {
+			params = append(params, fmt.Sprintf("interval=%d", group.Interval))
+		}
+		if group.Tolerance > 0 {
+			params = append(params, fmt.Sprintf("tolerance=%d", group.Tolerance))
+		}
+		if group.Lazy != nil {
+			params = append(params, fmt.Sprintf("lazy=%t", *group.Lazy))
+		}
+		if group.Strategy != "" {
+			params = append(params, "strategy="+group.Strategy)
+		}
+		if len(params) > 0 {
+			fmt.Fprintf(&b, T("   参数: %s\n"), strings.Join(params, ", "))
+		}
+	}
+	fmt.Fprintln(&b, T("\n[名称] 表示嵌套的代理组，* 表示当前选中的成员"))
+	return b.String()
+}
+
+// 打印代理组列表
+func printProxyGroups(config *ClashConfig) {
+	fmt.Print(formatProxyGroups(config))
+}
+
+// 代理组管理菜单，序号加 1 对应 interactiveManageGroups 中的选项
+var groupMenuItems = []string{
+	"创建代理组",
+	"修改代理组参数",
+	"删除代理组",
+	"添加成员",
+	"移除成员",
+	"启用/停用按地区自动分组",
+	"创建代理链",
+	"删除代理链",
+}
+
+// 交互式管理代理组
+func interactiveManageGroups() {
+	reader := stdinReader
+	for {
+		clearScreen()
+		fmt.Println(T("===== 代理组管理 ====="))
+
+		config, err := readClashConfig()
+		if err != nil {
+			fmt.Printf(T("读取配置文件失败: %v\n"), err)
+			waitForKeyPress()
+			return
+		}
+		choice := selectMenu(T("代理组管理"), textLines(formatProxyGroups(config)), translateAll(groupMenuItems), T("返回")) + 1
+
+		switch choice {
+		case 1:
+			err = interactiveCreateGroup(config, reader)
+		case 2:
+			err = interactiveEditGroup(config, reader)
+		case 3:
+			err = deleteProxyGroup(config, promptLine(reader, T("请输入要删除的代理组名称: ")))
+		case 4:
+			name := promptLine(reader, T("代理组名称: "))
+			err = addGroupMembers(config, name, promptList(reader, T("要添加的成员 (多个用逗号分隔): ")))
+		case 5:
+			name := promptLine(reader, T("代理组名称: "))
+			err = removeGroupMembers(config, name, promptList(reader, T("要移除的成员 (多个用逗号分隔): ")))
+		case 6:
+			// 需要在配置保存后再保存设置，单独处理
+			interactiveToggleRegionGroups(config)
+			continue
+		case 7:
+			err = interactiveCreateChain(config, reader)
+		case 8:
+			printChains(config)
+			err = deleteChain(config, promptLine(reader, T("请输入要删除的代理链名称: ")))
+		case 0:
+			return
+		}
+
+		if err != nil {
+			fmt.Printf(T("操作失败: %v\n"), err)
+			waitForKeyPress()
+			continue
+		}
+
+		saved, err := saveClashConfigWithConfirm(config)
+		if err != nil {
+			fmt.Printf(T("保存配置失败: %v\n"), err)
+		} else if saved {
+			promptRestartClash()
+		}
+		waitForKeyPress()
+	}
+}
+
+// 读取一行输入
+func promptLine(reader *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	line, _ := reader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// 读取逗号分隔的列表
+func promptList(reader *bufio.Reader, prompt string) []string {
+	return splitList(promptLine(reader, prompt))
+}
+
+// 拆分逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// 交互式选择代理组类型，修改时 current 为当前类型，选择返回保持不变
+func promptGroupType(current string) string {
+	var header []string
+	back := T("取消")
+	if current != "" {
+		header = []string{T("当前类型: ") + current}
+		back = T("保持不变")
+	}
+	index := selectMenu(T("代理组类型"), header, proxyGroupTypeList, back)
+	if index < 0 {
+		return ""
+	}
+	fmt.Printf(T("代理组类型: %s\n"), proxyGroupTypeList[index])
+	return proxyGroupTypeList[index]
+}
+
+// 按类型询问健康检查和负载均衡参数，直接回车使用当前值
+func promptGroupParams(group *ProxyGroup, reader *bufio.Reader) error {
+	var keys []string
+	if isHealthCheckGroup(group.Type) {
+		keys = append(keys, "url", "interval", "lazy")
+	}
+	if group.Type == "url-test" {
+		keys = append(keys, "tolerance")
+	}
+	if group.Type == "load-balance" {
+		keys = append(keys, "strategy")
+	}
+
+	current := map[string]string{
+		"url":       group.URL,
+		"interval":  strconv.Itoa(group.Interval),
+		"tolerance": strconv.Itoa(group.Tolerance),
+		"strategy":  group.Strategy,
+		"lazy":      "true",
+	}
+	if group.Lazy != nil {
+		current["lazy"] = strconv.FormatBool(*group.Lazy)
+	}
+
+	for _, key := range keys {
+		value := promptLine(reader, fmt.Sprintf("%s [%s]: ", key, current[key]))
+		if value == "" {
+			continue
+		}
+		if err := setGroupOption(group, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 交互式创建代理组
+func interactiveCreateGroup(config *ClashConfig, reader *bufio.Reader) error {
+	group := ProxyGroup{Name: promptLine(reader, T("\n代理组名称: "))}
+	group.Type = promptGroupType("")
+	if group.Type == "" {
+		return errors.New(T("无效的代理组类型"))
+	}
+
+	fmt.Println(T("\n可选的成员:"))
+	for _, proxy := range config.Proxies {
+		fmt.Printf("   %s\n", proxy.Name)
+	}
+	for _, g := range config.ProxyGroups {
+		fmt.Printf("   [%s]\n", g.Name)
+	}
+	prompt := T("成员 (多个用逗号分隔，可以包含 DIRECT): ")
+	if group.Type == "relay" {
+		prompt = T("转发链上的节点，按转发顺序用逗号分隔: ")
+	}
+	members := promptList(reader, prompt)
+
+	applyGroupDefaults(&group)
+	if err := promptGroupParams(&group, reader); err != nil {
+		return err
+	}
+	return createProxyGroup(config, group, members)
+}
+
+// 交互式修改代理组的名称、类型和参数
+func interactiveEditGroup(config *ClashConfig, reader *bufio.Reader) error {
+	group := config.FindGroup(promptLine(reader, T("代理组名称: ")))
+	if group == nil {
+		return errors.New(T("代理组不存在"))
+	}
+
+	if name := promptLine(reader, fmt.Sprintf(T("新名称 [%s]: "), group.Name)); name != "" {
+		if err := renameProxyGroup(config, group.Name, name); err != nil {
+			return err
+		}
+	}
+	if groupType := promptGroupType(group.Type); groupType != "" && groupType != group.Type {
+		if err := changeGroupType(config, group, groupType); err != nil {
+			return err
+		}
+	}
+	return promptGroupParams(group, reader)
+}
@@ -1,4 +1,4 @@
 package main
 
 // 此文件功能已移至 proxy_core.go 和其他功能文件
-// 保留此空文件，避免与其他文件的函数冲突 
\ No newline at end of file
+// 保留此空文件，避免与其他文件的函数冲突
@@ -1,138 +1,121 @@
 package main
 
 import (
-	"bufio"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"gopkg.in/yaml.v3"
 	"io"
-	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"strings"
-	"time"
-	"gopkg.in/yaml.v3"
 )
 
 // 交互式导入节点
 func interactiveImportProxies() {
-	clearScreen()
-	fmt.Println("===== 导入代理节点 =====")
-	fmt.Println("1. 从订阅链接导入")
-	fmt.Println("2. 从Base64编码字符串导入")
-	fmt.Println("3. 从节点链接(URI)导入")
-	fmt.Println("4. 从YAML文件导入")
-	fmt.Println("0. 返回")
-	
-	var choice int
-	fmt.Print("\n请选择导入方式 [0-4]: ")
-	fmt.Scanln(&choice)
-	
-	switch choice {
-	case 1:
+	items := []string{T("从订阅链接导入"), T("从Base64编码字符串导入"), T("从节点链接(URI)导入"), T("从YAML文件导入")}
+
+	switch selectMenu(T("导入代理节点"), nil, items, T("返回")) {
+	case 0:
 		importFromSubscription()
-	case 2:
+	case 1:
 		importFromBase64()
-	case 3:
+	case 2:
 		importFromNodeURIs()
-	case 4:
+	case 3:
 		importFromYAML()
-	case 0:
-		return
-	default:
-		fmt.Println("无效的选择")
-		time.Sleep(1 * time.Second)
 	}
 }
 
 // 从订阅链接导入
 func importFromSubscription() {
 	clearScreen()
-	fmt.Println("===== 从订阅链接导入 =====")
-	
+	fmt.Println(T("===== 从订阅链接导入 ====="))
+
 	// 获取订阅链接
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("请输入订阅链接: ")
+	reader := stdinReader
+	fmt.Print(T("请输入订阅链接: "))
 	subURL, _ := reader.ReadString('\n')
 	subURL = strings.TrimSpace(subURL)
-	
+
 	if subURL == "" {
-		fmt.Println("订阅链接不能为空")
+		fmt.Println(T("订阅链接不能为空"))
 		waitForKeyPress()
 		return
 	}
-	
-	// 发送HTTP请求获取订阅内容
-	fmt.Println("正在获取订阅内容...")
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(subURL)
+
+	// 选择导入方式
+	modes := []string{T("将订阅节点直接写入配置文件"), T("创建 proxy-provider，由 Clash 自动更新订阅")}
+	mode := selectMenu(T("选择导入方式"), []string{T("订阅链接: ") + subURL}, modes, T("取消"))
+	if mode < 0 {
+		return
+	}
+	if mode == 1 {
+		importSubscriptionAsProvider(subURL)
+		return
+	}
+
+	// 按照下载选项获取订阅内容
+	fetchOpts := promptSubscriptionFetchOptions()
+	fmt.Println(T("正在获取订阅内容..."))
+	body, err := fetchSubscriptionCached(subURL, fetchOpts)
 	if err != nil {
-		fmt.Printf("获取订阅内容失败: %v\n", err)
+		fmt.Printf(T("获取订阅内容失败: %v\n"), err)
 		waitForKeyPress()
 		return
 	}
-	defer resp.Body.Close()
-	
-	// 读取响应内容
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Printf("读取订阅内容失败: %v\n", err)
+
+	// 解析订阅内容，支持 Clash YAML 和 Base64/纯文本节点列表
+	proxies := parseSubscriptionProxies(body)
+	if len(proxies) == 0 {
+		fmt.Println(T("订阅内容中未找到有效的节点链接"))
 		waitForKeyPress()
 		return
 	}
-	
-	// 解码Base64内容
-	decodedBody, err := base64.StdEncoding.DecodeString(string(body))
+	fmt.Printf(T("订阅中共解析出 %d 个节点\n"), len(proxies))
+
+	// 导入节点
+	imported, err := importProxiesFromMaps(proxies)
 	if err != nil {
-		fmt.Printf("解码订阅内容失败，尝试作为普通文本处理: %v\n", err)
-		decodedBody = body
-	}
-	
-	// 处理URI列表
-	uriList := strings.Split(string(decodedBody), "\n")
-	var validURIs []string
-	for _, uri := range uriList {
-		uri = strings.TrimSpace(uri)
-		if uri != "" {
-			validURIs = append(validURIs, uri)
-		}
-	}
-	
-	if len(validURIs) == 0 {
-		fmt.Println("订阅内容中未找到有效的节点链接")
+		fmt.Println(err)
 		waitForKeyPress()
 		return
 	}
-	
-	// 导入节点
-	importNodesFromURIs(validURIs)
+
+	// 记录订阅，便于之后刷新
+	if err := recordSubscription(reader, subURL, imported); err != nil {
+		fmt.Printf(T("保存订阅记录失败: %v\n"), err)
+	}
+
+	promptRestartClash()
+	waitForKeyPress()
 }
 
 // 从Base64编码字符串导入
 func importFromBase64() {
 	clearScreen()
-	fmt.Println("===== 从Base64编码字符串导入 =====")
-	
+	fmt.Println(T("===== 从Base64编码字符串导入 ====="))
+
 	// 获取Base64编码字符串
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("请输入Base64编码字符串: ")
+	reader := stdinReader
+	fmt.Print(T("请输入Base64编码字符串: "))
 	base64Str, _ := reader.ReadString('\n')
 	base64Str = strings.TrimSpace(base64Str)
-	
+
 	if base64Str == "" {
-		fmt.Println("Base64编码字符串不能为空")
+		fmt.Println(T("Base64编码字符串不能为空"))
 		waitForKeyPress()
 		return
 	}
-	
+
 	// 解码Base64内容
 	decodedBytes, err := base64.StdEncoding.DecodeString(base64Str)
 	if err != nil {
-		fmt.Printf("解码Base64内容失败: %v\n", err)
+		fmt.Printf(T("解码Base64内容失败: %v\n"), err)
 		waitForKeyPress()
 		return
 	}
-	
+
 	// 处理URI列表
 	uriList := strings.Split(string(decodedBytes), "\n")
 	var validURIs []string
@@ -142,13 +125,13 @@ func importFromBase64() {
 			validURIs = append(validURIs, uri)
 		}
 	}
-	
+
 	if len(validURIs) == 0 {
-		fmt.Println("解码内容中未找到有效的节点链接")
+		fmt.Println(T("解码内容中未找到有效的节点链接"))
 		waitForKeyPress()
 		return
 	}
-	
+
 	// 导入节点
 	importNodesFromURIs(validURIs)
 }
@@ -156,29 +139,29 @@ func importFromBase64() {
 // 从节点链接(URI)导入
 func importFromNodeURIs() {
 	clearScreen()
-	fmt.Println("===== 从节点链接(URI)导入 =====")
-	fmt.Println("支持的链接格式: ss://, vmess://, trojan://")
-	fmt.Println("可以一次输入多个链接，每行一个")
-	fmt.Println("输入完成后，按Ctrl+D(Linux/Mac)或Ctrl+Z(Windows)或Ctrl+C结束输入")
+	fmt.Println(T("===== 从节点链接(URI)导入 ====="))
+	fmt.Println(T("支持的链接格式: ss://, vmess://, trojan://"))
+	fmt.Println(T("可以一次输入多个链接，每行一个"))
+	fmt.Println(T("输入完成后，按Ctrl+D(Linux/Mac)或Ctrl+Z(Windows)或Ctrl+C结束输入"))
 	fmt.Println("--------------------------------------")
-	
+
 	// 创建一个通道来处理Ctrl+C信号
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
-	
+
 	// 读取用户输入的多行URI
-	reader := bufio.NewReader(os.Stdin)
+	reader := stdinReader
 	var uris []string
-	
+
 	// 启动一个goroutine来处理信号
 	done := make(chan bool, 1)
 	go func() {
 		<-c
-		fmt.Println("\n结束输入")
+		fmt.Println(T("\n结束输入"))
 		done <- true
 	}()
-	
-	inputLoop:
+
+inputLoop:
 	for {
 		select {
 		case <-done:
@@ -187,7 +170,7 @@ func importFromNodeURIs() {
 			fmt.Print("> ")
 			inputCh := make(chan string, 1)
 			errCh := make(chan error, 1)
-			
+
 			go func() {
 				input, err := reader.ReadString('\n')
 				if err != nil {
@@ -196,7 +179,7 @@ func importFromNodeURIs() {
 				}
 				inputCh <- input
 			}()
-			
+
 			select {
 			case <-done:
 				break inputLoop
@@ -209,24 +192,24 @@ func importFromNodeURIs() {
 				if err == io.EOF {
 					break inputLoop
 				}
-				fmt.Printf("读取输入失败: %v\n", err)
+				fmt.Printf(T("读取输入失败: %v\n"), err)
 				waitForKeyPress()
 				return
 			}
 		}
 	}
-	
+
 	// 取消信号监听
 	signal.Stop(c)
-	
+
 	if len(uris) == 0 {
-		fmt.Println("\n未输入任何链接")
+		fmt.Println(T("\n未输入任何链接"))
 		waitForKeyPress()
 		return
 	}
-	
-	fmt.Printf("\n共读取到 %d 个链接\n", len(uris))
-	
+
+	fmt.Printf(T("\n共读取到 %d 个链接\n"), len(uris))
+
 	// 导入节点
 	importNodesFromURIs(uris)
 }
@@ -234,133 +217,129 @@ func importFromNodeURIs() {
 // 从YAML文件导入
 func importFromYAML() {
 	clearScreen()
-	fmt.Println("===== 从YAML文件导入 =====")
-	
+	fmt.Println(T("===== 从YAML文件导入 ====="))
+
 	// 获取YAML文件路径
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("请输入YAML文件路径: ")
+	reader := stdinReader
+	fmt.Print(T("请输入YAML文件路径: "))
 	filePath, _ := reader.ReadString('\n')
 	filePath = strings.TrimSpace(filePath)
-	
+
 	if filePath == "" {
-		fmt.Println("文件路径不能为空")
+		fmt.Println(T("文件路径不能为空"))
 		waitForKeyPress()
 		return
 	}
-	
+
 	// 读取YAML文件
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		fmt.Printf("读取文件失败: %v\n", err)
+		fmt.Printf(T("读取文件失败: %v\n"), err)
 		waitForKeyPress()
 		return
 	}
-	
+
 	// 解析YAML
 	var yamlConfig map[string]interface{}
 	if err := yaml.Unmarshal(content, &yamlConfig); err != nil {
-		fmt.Printf("解析YAML失败: %v\n", err)
+		fmt.Printf(T("解析YAML失败: %v\n"), err)
 		waitForKeyPress()
 		return
 	}
-	
+
 	// 提取代理配置
 	proxies, ok := yamlConfig["proxies"].([]interface{})
 	if !ok || len(proxies) == 0 {
-		fmt.Println("YAML中未找到有效的代理配置")
+		fmt.Println(T("YAML中未找到有效的代理配置"))
 		waitForKeyPress()
 		return
 	}
-	
-	// 读取当前配置
-	config, err := readClashConfig()
-	if err != nil {
-		fmt.Printf("读取当前配置失败: %v\n", err)
+
+	if _, err := importProxiesFromMaps(proxies); err != nil {
+		fmt.Println(err)
 		waitForKeyPress()
 		return
 	}
-	
-	// 获取当前代理列表
-	var currentProxies []interface{}
-	if existingProxies, ok := config["proxies"].([]interface{}); ok {
-		currentProxies = existingProxies
+
+	promptRestartClash()
+	waitForKeyPress()
+}
+
+// 解析 Clash 格式的 YAML 内容，返回其中的代理列表
+func parseClashYAMLProxies(content []byte) ([]interface{}, bool) {
+	var yamlConfig map[string]interface{}
+	if err := yaml.Unmarshal(content, &yamlConfig); err != nil {
+		return nil, false
+	}
+
+	proxies, ok := yamlConfig["proxies"].([]interface{})
+	if !ok || len(proxies) == 0 {
+		return nil, false
 	}
-	
-	// 当前代理名称集合，用于检查重复
-	currentProxyNames := make(map[string]bool)
-	for _, p := range currentProxies {
-		if proxy, ok := p.(map[string]interface{}); ok {
-			if name, ok := proxy["name"].(string); ok {
-				currentProxyNames[name] = true
-			}
-		}
+
+	return proxies, true
+}
+
+// 将 Clash 格式的代理配置导入当前配置，返回成功导入的节点名称
+func importProxiesFromMaps(proxies []interface{}) ([]string, error) {
+	// 读取当前配置
+	config, err := readClashConfig()
+	if err != nil {
+		return nil, fmt.Errorf(T("读取当前配置失败: %w"), err)
 	}
-	
+
 	// 添加新代理
-	importedCount := 0
+	var imported []string
 	skippedCount := 0
-	
+
 	for _, p := range proxies {
-		proxy, ok := p.(map[string]interface{})
+		proxyMap, ok := p.(map[string]interface{})
 		if !ok {
 			continue
 		}
-		
-		// 获取代理名称
-		name, ok := proxy["name"].(string)
-		if !ok || name == "" {
+
+		// 转换为类型化的代理，缺少名称或端口无效时跳过
+		proxy, err := proxyFromMap(proxyMap)
+		if err != nil {
+			fmt.Printf(T("跳过无效的代理: %v\n"), err)
 			skippedCount++
 			continue
 		}
-		
+
 		// 检查是否已存在
-		if currentProxyNames[name] {
-			fmt.Printf("跳过已存在的代理: %s\n", name)
+		if config.FindProxy(proxy.Name) != nil {
+			fmt.Printf(T("跳过已存在的代理: %s\n"), proxy.Name)
 			skippedCount++
 			continue
 		}
-		
+
 		// 确保必要的字段都存在
-		ensureRequiredFields(proxy)
-		
+		ensureRequiredFields(&proxy)
+
 		// 添加到当前代理列表
-		currentProxies = append(currentProxies, proxy)
-		currentProxyNames[name] = true
-		
+		config.Proxies = append(config.Proxies, proxy)
+
 		// 更新代理组
-		updateProxyGroup(config, name)
-		
-		importedCount++
-		fmt.Printf("已导入代理: %s\n", name)
+		updateProxyGroup(config, proxy.Name)
+
+		imported = append(imported, proxy.Name)
+		fmt.Printf(T("已导入代理: %s\n"), proxy.Name)
 	}
-	
-	// 更新配置
-	config["proxies"] = currentProxies
-	
-	// 保存配置
-	if err := saveClashConfig(config); err != nil {
-		fmt.Printf("保存配置失败: %v\n", err)
-		waitForKeyPress()
-		return
+
+	// 启用了按地区分组时同步更新地区代理组
+	applyRegionGroups(config)
+
+	// 显示修改并确认后保存配置
+	saved, err := saveClashConfigWithConfirm(config)
+	if err != nil {
+		return nil, fmt.Errorf(T("保存配置失败: %w"), err)
 	}
-	
-	fmt.Printf("\n导入完成: 成功导入 %d 个代理，跳过 %d 个代理\n", importedCount, skippedCount)
-	
-	// 询问是否重启Clash服务
-	fmt.Println("是否需要重启Clash服务来应用更改? [y/n]")
-	var restart string
-	fmt.Scanln(&restart)
-	
-	if strings.ToLower(restart) == "y" {
-		cmd := exec.Command("systemctl", "restart", "clash")
-		if err := cmd.Run(); err != nil {
-			fmt.Printf("重启Clash服务失败: %v\n", err)
-		} else {
-			fmt.Println("Clash服务已重启")
-		}
+	if !saved {
+		return nil, errors.New(T("导入已取消"))
 	}
-	
-	waitForKeyPress()
+
+	fmt.Printf(T("\n导入完成: 成功导入 %d 个代理，跳过 %d 个代理\n"), len(imported), skippedCount)
+	return imported, nil
 }
 
 // 从URI列表导入节点
@@ -368,38 +347,22 @@ func importNodesFromURIs(uris []string) {
 	// 读取当前配置
 	config, err := readClashConfig()
 	if err != nil {
-		fmt.Printf("读取配置失败: %v\n", err)
+		fmt.Printf(T("读取配置失败: %v\n"), err)
 		waitForKeyPress()
 		return
 	}
-	
-	// 获取当前代理列表
-	var currentProxies []interface{}
-	if existingProxies, ok := config["proxies"].([]interface{}); ok {
-		currentProxies = existingProxies
-	}
-	
-	// 当前代理名称集合，用于检查重复
-	currentProxyNames := make(map[string]bool)
-	for _, p := range currentProxies {
-		if proxy, ok := p.(map[string]interface{}); ok {
-			if name, ok := proxy["name"].(string); ok {
-				currentProxyNames[name] = true
-			}
-		}
-	}
-	
+
 	// 处理每个URI
 	importedCount := 0
 	skippedCount := 0
 	errorCount := 0
-	
+
 	for i, uri := range uris {
-		fmt.Printf("处理节点 %d/%d: ", i+1, len(uris))
-		
+		fmt.Printf(T("处理节点 %d/%d: "), i+1, len(uris))
+
 		var proxyConfig map[string]interface{}
 		var err error
-		
+
 		// 根据URI类型解析
 		if strings.HasPrefix(uri, "ss://") {
 			proxyConfig, err = parseShadowsocksURI(uri)
@@ -408,114 +371,104 @@ func importNodesFromURIs(uris []string) {
 		} else if strings.HasPrefix(uri, "trojan://") {
 			proxyConfig, err = parseTrojanURI(uri)
 		} else {
-			fmt.Printf("跳过不支持的协议: %s\n", uri[:10]+"...")
+			fmt.Printf(T("跳过不支持的协议: %s\n"), uri[:10]+"...")
 			skippedCount++
 			continue
 		}
-		
+
 		if err != nil {
-			fmt.Printf("解析失败: %v\n", err)
+			fmt.Printf(T("解析失败: %v\n"), err)
 			errorCount++
 			continue
 		}
-		
-		// 获取代理名称
-		name, ok := proxyConfig["name"].(string)
-		if !ok || name == "" {
-			fmt.Println("跳过没有名称的代理")
+
+		// 转换为类型化的代理
+		proxy, err := proxyFromMap(proxyConfig)
+		if err != nil {
+			fmt.Printf(T("跳过无效的代理: %v\n"), err)
 			skippedCount++
 			continue
 		}
-		
+
 		// 检查是否已存在
-		if currentProxyNames[name] {
-			fmt.Printf("跳过已存在的代理: %s\n", name)
+		if config.FindProxy(proxy.Name) != nil {
+			fmt.Printf(T("跳过已存在的代理: %s\n"), proxy.Name)
 			skippedCount++
 			continue
 		}
-		
+
 		// 确保必要的字段都存在
-		ensureRequiredFields(proxyConfig)
-		
+		ensureRequiredFields(&proxy)
+
 		// 添加到当前代理列表
-		currentProxies = append(currentProxies, proxyConfig)
-		currentProxyNames[name] = true
-		
+		config.Proxies = append(config.Proxies, proxy)
+
 		// 更新代理组
-		updateProxyGroup(config, name)
-		
+		updateProxyGroup(config, proxy.Name)
+
 		importedCount++
-		fmt.Printf("已导入: %s\n", name)
+		fmt.Printf(T("已导入: %s\n"), proxy.Name)
 	}
-	
-	// 更新配置
-	config["proxies"] = currentProxies
-	
-	// 保存配置
-	if err := saveClashConfig(config); err != nil {
-		fmt.Printf("保存配置失败: %v\n", err)
+
+	// 启用了按地区分组时同步更新地区代理组
+	applyRegionGroups(config)
+
+	// 显示修改并确认后保存配置
+	saved, err := saveClashConfigWithConfirm(config)
+	if err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+	if !saved {
 		waitForKeyPress()
 		return
 	}
-	
-	fmt.Printf("\n导入完成: 成功导入 %d 个代理，跳过 %d 个代理，错误 %d 个\n", 
+
+	fmt.Printf(T("\n导入完成: 成功导入 %d 个代理，跳过 %d 个代理，错误 %d 个\n"),
 		importedCount, skippedCount, errorCount)
-	
+
 	// 询问是否重启Clash服务
-	fmt.Println("是否需要重启Clash服务来应用更改? [y/n]")
-	var restart string
-	fmt.Scanln(&restart)
-	
-	if strings.ToLower(restart) == "y" {
-		cmd := exec.Command("systemctl", "restart", "clash")
-		if err := cmd.Run(); err != nil {
-			fmt.Printf("重启Clash服务失败: %v\n", err)
-		} else {
-			fmt.Println("Clash服务已重启")
-		}
-	}
-	
+	promptRestartClash()
+
 	waitForKeyPress()
 }
 
 // 确保代理配置中包含所有必要的字段
-func ensureRequiredFields(proxyConfig map[string]interface{}) {
-	// 检查代理类型
-	proxyType, _ := proxyConfig["type"].(string)
-	
+func ensureRequiredFields(proxy *Proxy) {
 	// 添加 udp 字段
-	if _, exists := proxyConfig["udp"]; !exists {
-		proxyConfig["udp"] = true
+	if !proxy.Has("udp") {
+		proxy.Set("udp", true)
 	}
-	
+
 	// 根据不同代理类型添加必要字段
-	switch proxyType {
+	switch proxy.Type {
 	case "vmess":
 		// 确保 cipher 字段存在
-		if _, exists := proxyConfig["cipher"]; !exists {
-			proxyConfig["cipher"] = "auto"
+		if !proxy.Has("cipher") {
+			proxy.Set("cipher", "auto")
 		}
-		
+
 		// 确保 alterId 字段存在
-		if _, exists := proxyConfig["alterId"]; !exists {
-			proxyConfig["alterId"] = "0"
+		if !proxy.Has("alterId") {
+			proxy.Set("alterId", 0)
 		}
-		
+
 		// 确保 network 字段存在
-		if _, exists := proxyConfig["network"]; !exists {
-			proxyConfig["network"] = "tcp"
+		if !proxy.Has("network") {
+			proxy.Set("network", "tcp")
 		}
-		
+
 	case "ss", "shadowsocks":
 		// 确保 cipher 字段存在
-		if _, exists := proxyConfig["cipher"]; !exists {
-			proxyConfig["cipher"] = "aes-256-gcm" // 默认加密方式
+		if !proxy.Has("cipher") {
+			proxy.Set("cipher", "aes-256-gcm") // 默认加密方式
 		}
-		
+
 	case "trojan":
 		// 确保 skip-cert-verify 字段存在
-		if _, exists := proxyConfig["skip-cert-verify"]; !exists {
-			proxyConfig["skip-cert-verify"] = false
+		if !proxy.Has("skip-cert-verify") {
+			proxy.Set("skip-cert-verify", false)
 		}
 	}
-} 
\ No newline at end of file
+}
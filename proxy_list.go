@@ -0,0 +1,495 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// 节点列表中的一行
+type proxyListRow struct {
+	Proxy  *Proxy
+	Region string
+	// 导入该节点的订阅，手动添加的节点为空
+	Source string
+	Groups []string
+	// 最近一次测速的延迟，0 表示未测速，-1 表示失败
+	Delay    int
+	Selected bool
+	// 正在后台测速
+	Testing bool
+}
+
+// 节点列表的一列
+type proxyListColumn struct {
+	Title string
+	// 最大宽度，0 表示使用剩余的宽度
+	MaxWidth int
+	Value    func(row *proxyListRow) string
+	Less     func(a, b *proxyListRow) bool
+}
+
+// 延迟的排序值，未测速和测速失败的排在最后
+func delaySortKey(delay int) int {
+	if delay > 0 {
+		return delay
+	}
+	return int(^uint(0) >> 1)
+}
+
+// 节点列表的所有列，Tab 键按此顺序切换排序列
+var proxyListColumns = []proxyListColumn{
+	{"名称", 0, func(r *proxyListRow) string { return r.Proxy.Name },
+		func(a, b *proxyListRow) bool { return naturalLess(a.Proxy.Name, b.Proxy.Name) }},
+	{"类型", 10, func(r *proxyListRow) string { return r.Proxy.Type },
+		func(a, b *proxyListRow) bool { return a.Proxy.Type < b.Proxy.Type }},
+	{"服务器", 24, func(r *proxyListRow) string { return r.Proxy.Server },
+		func(a, b *proxyListRow) bool { return a.Proxy.Server < b.Proxy.Server }},
+	{"端口", 5, func(r *proxyListRow) string { return r.Proxy.Port.String() },
+		func(a, b *proxyListRow) bool { return a.Proxy.Port.Number < b.Proxy.Port.Number }},
+	{"延迟", 8, func(r *proxyListRow) string {
+		switch {
+		case r.Testing:
+			return T("测速中")
+		case r.Delay > 0:
+			return fmt.Sprintf("%d ms", r.Delay)
+		case r.Delay < 0:
+			return T("失败")
+		}
+		return "-"
+	}, func(a, b *proxyListRow) bool { return delaySortKey(a.Delay) < delaySortKey(b.Delay) }},
+	{"地区", 10, func(r *proxyListRow) string { return r.Region },
+		func(a, b *proxyListRow) bool { return a.Region < b.Region }},
+	{"订阅", 12, func(r *proxyListRow) string { return r.Source },
+		func(a, b *proxyListRow) bool { return a.Source < b.Source }},
+	{"代理组", 24, func(r *proxyListRow) string { return strings.Join(r.Groups, ",") },
+		func(a, b *proxyListRow) bool { return len(a.Groups) < len(b.Groups) }},
+}
+
+// 收集节点列表需要的信息：地区、来源订阅、所在代理组、测速结果和当前选中的节点
+func buildProxyListRows(config *ClashConfig) []*proxyListRow {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		settings = &ManagerSettings{}
+	}
+
+	sources := make(map[string]string)
+	for _, sub := range settings.Subscriptions {
+		for _, name := range sub.Nodes {
+			sources[name] = sub.Name
+		}
+	}
+
+	// Clash 未运行时使用配置中主代理组选中的节点
+	selected := ""
+	if info, err := getSelectedProxy(); err == nil {
+		selected = info.SelectedProxy
+	} else if group := primarySelectGroup(config, nil); group != nil {
+		selected = group.Selected
+	}
+
+	assignments := classifyProxyRegions(config.Proxies)
+	var rows []*proxyListRow
+	for i := range config.Proxies {
+		proxy := &config.Proxies[i]
+		row := &proxyListRow{
+			Proxy:    proxy,
+			Source:   sources[proxy.Name],
+			Selected: proxy.Name == selected,
+		}
+		if code := assignments[proxy.Name].Code; code != "" {
+			row.Region = regionGroupName(code)
+		}
+		if meta := settings.ProxyMeta[proxy.Name]; meta != nil {
+			row.Delay = meta.Delay
+		}
+		for _, group := range config.ProxyGroups {
+			if containsString(group.Proxies, proxy.Name) {
+				row.Groups = append(row.Groups, group.Name)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// 后台测速的并发数
+const proxyListTestConcurrency = 16
+
+// 一个节点的测速结果
+type proxyDelayResult struct {
+	Name  string
+	Delay int
+}
+
+// 节点列表的显示状态
+type proxyListView struct {
+	config   *ClashConfig
+	rows     []*proxyListRow
+	filtered []*proxyListRow
+	query    []rune
+	sortBy   int
+	reverse  bool
+	cursor   int
+	// 正在查看详情的节点
+	detail *proxyListRow
+
+	// 后台测速：results 接收结果，pending 为尚未返回的节点数
+	results chan proxyDelayResult
+	pending int
+	delays  map[string]int
+	message string
+}
+
+// 按搜索内容筛选并排序，搜索不区分大小写，匹配任意一列
+// 测速结果改变排序时光标跟随原来的节点
+func (v *proxyListView) refresh() {
+	var current *proxyListRow
+	if v.cursor >= 0 && v.cursor < len(v.filtered) {
+		current = v.filtered[v.cursor]
+	}
+
+	query := strings.ToLower(string(v.query))
+	v.filtered = v.filtered[:0]
+	for _, row := range v.rows {
+		if query == "" || strings.Contains(strings.ToLower(proxyListRowText(row)), query) {
+			v.filtered = append(v.filtered, row)
+		}
+	}
+
+	less := proxyListColumns[v.sortBy].Less
+	sort.SliceStable(v.filtered, func(i, j int) bool {
+		if v.reverse {
+			return less(v.filtered[j], v.filtered[i])
+		}
+		return less(v.filtered[i], v.filtered[j])
+	})
+
+	for i, row := range v.filtered {
+		if row == current {
+			v.cursor = i
+		}
+	}
+	if v.cursor >= len(v.filtered) {
+		v.cursor = len(v.filtered) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+// 一行中所有列的文字，用于搜索
+func proxyListRowText(row *proxyListRow) string {
+	var parts []string
+	for _, column := range proxyListColumns {
+		parts = append(parts, column.Value(row))
+	}
+	return strings.Join(parts, "\t")
+}
+
+// 各列的显示宽度，名称列使用剩余的宽度
+func proxyListWidths(rows []*proxyListRow, cols int) []int {
+	widths := make([]int, len(proxyListColumns))
+	used := 2 // 选中标记
+	for i, column := range proxyListColumns {
+		if column.MaxWidth == 0 {
+			continue
+		}
+		width := displayWidth(T(column.Title)) + 2
+		for _, row := range rows {
+			if w := displayWidth(column.Value(row)); w > width {
+				width = w
+			}
+		}
+		if width > column.MaxWidth {
+			width = column.MaxWidth
+		}
+		widths[i] = width
+		used += width + 1
+	}
+	widths[0] = cols - used - 1
+	if widths[0] < 12 {
+		widths[0] = 12
+	}
+	return widths
+}
+
+// 每页显示的行数
+func proxyListPageSize(rows int) int {
+	// 标题、搜索栏、表头、页码和帮助各占一行
+	if size := rows - 5; size > 1 {
+		return size
+	}
+	return 1
+}
+
+// 绘制节点列表，rows 不含状态栏
+func (v *proxyListView) render(rows, cols int) []string {
+	if v.detail != nil {
+		return proxyDetailLines(v.detail, cols)
+	}
+
+	pageSize := proxyListPageSize(rows)
+	widths := proxyListWidths(v.rows, cols)
+	page := v.cursor / pageSize
+	pages := (len(v.filtered) + pageSize - 1) / pageSize
+	if pages == 0 {
+		pages = 1
+	}
+
+	lines := []string{tuiTitle(T("所有代理节点")), T("搜索: ") + string(v.query) + "_"}
+
+	header := "  "
+	for i, column := range proxyListColumns {
+		title := T(column.Title)
+		if i == v.sortBy {
+			if v.reverse {
+				title += " ↓"
+			} else {
+				title += " ↑"
+			}
+		}
+		header += "\033[1m" + fitWidth(title, widths[i]) + "\033[0m "
+	}
+	lines = append(lines, header)
+
+	start := page * pageSize
+	for i := start; i < start+pageSize; i++ {
+		if i >= len(v.filtered) {
+			lines = append(lines, "")
+			continue
+		}
+		row := v.filtered[i]
+		marker := "  "
+		if row.Selected {
+			marker = "* "
+		}
+		line := marker
+		for j, column := range proxyListColumns {
+			line += fitWidth(column.Value(row), widths[j]) + " "
+		}
+		if i == v.cursor {
+			line = "\033[7m" + line + "\033[0m"
+		}
+		lines = append(lines, line)
+	}
+
+	footer := fmt.Sprintf(T("第 %d/%d 页，显示 %d/%d 个节点，* 表示当前使用的节点"), page+1, pages, len(v.filtered), len(v.rows))
+	if v.pending > 0 {
+		footer += fmt.Sprintf(T("，正在测速 %d/%d"), len(v.rows)-v.pending, len(v.rows))
+	} else if v.message != "" {
+		footer += "，" + v.message
+	}
+	lines = append(lines, fitWidth(footer, cols-1))
+	// 帮助超出终端宽度时会换行，导致整个画面错位
+	lines = append(lines, fitWidth(T("输入文字搜索  ↑↓ 移动  ←→/PgUp/PgDn 翻页  Tab 切换排序列  Ctrl-R 反向排序  Ctrl-T 测速  回车查看详情  Esc 返回"), cols-1))
+	return lines
+}
+
+// 节点的详细信息，协议字段中的密码等内容也会显示
+func proxyDetailLines(row *proxyListRow, cols int) []string {
+	proxy := row.Proxy
+	lines := []string{tuiTitle(proxy.Name), ""}
+	lines = append(lines, T("类型: ")+proxy.Type, T("服务器: ")+proxy.Server, T("端口: ")+proxy.Port.String())
+	for _, key := range sortedKeys(proxy.Extra) {
+		lines = append(lines, fitWidth(key+": "+proxy.GetString(key), cols-1))
+	}
+	lines = append(lines, "")
+	for i := 4; i < len(proxyListColumns); i++ {
+		lines = append(lines, fitWidth(T(proxyListColumns[i].Title)+": "+proxyListColumns[i].Value(row), cols-1))
+	}
+	return append(lines, "", T("按任意键返回列表"))
+}
+
+// 处理按键，返回 false 表示退出列表
+func (v *proxyListView) handleKey(key keyEvent, rows int) bool {
+	if v.detail != nil {
+		v.detail = nil
+		return true
+	}
+	pageSize := proxyListPageSize(rows)
+
+	switch key.Kind {
+	case keyEscape, keyCtrlC:
+		return false
+	case keyUp:
+		v.cursor--
+	case keyDown:
+		v.cursor++
+	case keyLeft, keyPageUp:
+		v.cursor -= pageSize
+	case keyRight, keyPageDown:
+		v.cursor += pageSize
+	case keyHome:
+		v.cursor = 0
+	case keyEnd:
+		v.cursor = len(v.filtered) - 1
+	case keyTab:
+		v.sortBy = (v.sortBy + 1) % len(proxyListColumns)
+		v.reverse = false
+	case keyBackTab:
+		v.sortBy = (v.sortBy + len(proxyListColumns) - 1) % len(proxyListColumns)
+		v.reverse = false
+	case keyCtrlR:
+		v.reverse = !v.reverse
+	case keyCtrlT:
+		v.startDelayTest()
+	case keyBackspace:
+		if len(v.query) > 0 {
+			v.query = v.query[:len(v.query)-1]
+		}
+	case keyCtrlU:
+		v.query = nil
+	case keyRune:
+		v.query = append(v.query, key.Rune)
+		v.cursor = 0
+	case keyEnter:
+		if v.cursor < len(v.filtered) {
+			v.detail = v.filtered[v.cursor]
+		}
+	}
+
+	if v.cursor >= len(v.filtered) {
+		v.cursor = len(v.filtered) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	v.refresh()
+	return true
+}
+
+// 在后台测试所有节点的延迟，结果逐个返回，列表随之更新
+// 节点直接连接服务器测试，代理链需要经过各跳，通过 Clash API 测试
+func (v *proxyListView) startDelayTest() {
+	if v.pending > 0 {
+		return
+	}
+	results := make(chan proxyDelayResult, len(v.rows))
+	v.results = results
+	v.pending = len(v.rows)
+	v.delays = make(map[string]int)
+	v.message = ""
+
+	limit := make(chan struct{}, proxyListTestConcurrency)
+	for _, row := range v.rows {
+		row.Testing = true
+		name := row.Proxy.Name
+		chain := isProxyChain(v.config, name)
+		address := net.JoinHostPort(row.Proxy.Server, row.Proxy.Port.String())
+		go func() {
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			var delay int
+			if chain {
+				delay = testChainDelay(name)
+			} else {
+				delay = tcpDialDelay(address, 1)
+			}
+			results <- proxyDelayResult{Name: name, Delay: delay}
+		}()
+	}
+}
+
+// 取出已经返回的测速结果，全部返回后保存
+func (v *proxyListView) update() {
+	changed := false
+drain:
+	for v.pending > 0 {
+		select {
+		case result := <-v.results:
+			v.pending--
+			v.delays[result.Name] = result.Delay
+			for _, row := range v.rows {
+				if row.Proxy.Name == result.Name {
+					row.Delay = result.Delay
+					row.Testing = false
+				}
+			}
+			changed = true
+		default:
+			break drain
+		}
+	}
+	if !changed {
+		return
+	}
+	v.refresh()
+	if v.pending == 0 {
+		v.message = v.saveDelays()
+	}
+}
+
+// 保存测速结果，供按延迟排序和筛选测速失败的节点使用；返回显示在页码后的提示
+func (v *proxyListView) saveDelays() string {
+	available := 0
+	for _, delay := range v.delays {
+		if delay > 0 {
+			available++
+		}
+	}
+
+	settings, err := loadManagerSettings()
+	if err == nil {
+		applyProxyDelays(settings, v.delays)
+		err = saveManagerSettings(settings)
+	}
+	if err != nil {
+		return fmt.Sprintf(T("测速完成，%d 个节点可用，保存结果失败: %v"), available, err)
+	}
+	return fmt.Sprintf(T("测速完成，%d/%d 个节点可用"), available, len(v.delays))
+}
+
+// 不是终端时一次输出全部节点
+func printProxyListTable(rows []*proxyListRow) {
+	_, cols := terminalSize()
+	widths := proxyListWidths(rows, cols)
+	line := "  "
+	for i, column := range proxyListColumns {
+		line += fitWidth(T(column.Title), widths[i]) + " "
+	}
+	fmt.Println(strings.TrimRight(line, " "))
+	for _, row := range rows {
+		line = "  "
+		if row.Selected {
+			line = "* "
+		}
+		for i, column := range proxyListColumns {
+			line += fitWidth(column.Value(row), widths[i]) + " "
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+}
+
+// 交互式查看节点列表，支持搜索、翻页、按列排序和后台测速
+func interactiveListProxies() {
+	clearScreen()
+	fmt.Println(T("===== 所有代理节点 ====="))
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+	if len(config.Proxies) == 0 {
+		fmt.Println(T("没有配置任何代理节点"))
+		waitForKeyPress()
+		return
+	}
+
+	fmt.Println(T("正在读取节点信息..."))
+	view := &proxyListView{config: config, rows: buildProxyListRows(config)}
+	view.refresh()
+
+	if err := runTUI(view); err != nil {
+		printProxyListTable(view.filtered)
+		waitForKeyPress()
+		return
+	}
+	// 测速未完成就退出时保存已有的结果
+	if view.pending > 0 && len(view.delays) > 0 {
+		fmt.Println(view.saveDelays())
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 健康检查默认使用的测试地址
+const defaultHealthCheckURL = "http://www.gstatic.com/generate_204"
+
+// proxy-providers 缓存文件所在目录
+func clashProvidersDir() string {
+	return clashWorkPath("providers")
+}
+
+// 处理 providers 子命令
+func manageProxyProviders(args []string) {
+	if len(args) == 0 {
+		printProvidersUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		if err := listProxyProviders(); err != nil {
+			fmt.Printf(T("列出 proxy-providers 失败: %v\n"), err)
+			os.Exit(1)
+		}
+	case "refresh":
+		var names []string
+		if len(args) > 1 {
+			names = args[1:]
+		}
+		if err := refreshProxyProviders(names); err != nil {
+			fmt.Printf(T("刷新 proxy-providers 失败: %v\n"), err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf(T("未知的 providers 子命令: %s\n"), args[0])
+		printProvidersUsage()
+		os.Exit(1)
+	}
+}
+
+// 显示 providers 子命令的用法
+func printProvidersUsage() {
+	fmt.Printf(T("用法: %s providers <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  list               列出配置中的 proxy-providers"))
+	fmt.Println(T("  refresh [名称...]  通过 Clash API 刷新指定的 proxy-provider，不指定名称时刷新全部"))
+}
+
+// 列出配置文件中的 proxy-providers
+func listProxyProviders() error {
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+
+	providers := config.ProxyProviders
+	if len(providers) == 0 {
+		fmt.Println(T("配置文件中没有 proxy-providers"))
+		return nil
+	}
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf(T("共找到 %d 个 proxy-provider:\n\n"), len(names))
+	for _, name := range names {
+		provider := providers[name]
+		fmt.Printf(T("名称: %s\n"), name)
+		fmt.Printf(T("   类型: %s\n"), provider.Type)
+		if provider.URL != "" {
+			fmt.Printf(T("   订阅地址: %s\n"), provider.URL)
+		}
+		fmt.Printf(T("   更新间隔: %d 秒\n"), provider.Interval)
+		fmt.Printf(T("   缓存路径: %s\n"), provider.Path)
+		fmt.Println("   ------------------------")
+	}
+
+	return nil
+}
+
+// 通过 Clash API 刷新 proxy-providers
+func refreshProxyProviders(names []string) error {
+	// 未指定名称时，刷新配置中的全部 provider
+	if len(names) == 0 {
+		config, err := readClashConfig()
+		if err != nil {
+			return fmt.Errorf(T("读取配置文件失败: %w"), err)
+		}
+
+		for name := range config.ProxyProviders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		fmt.Println(T("配置文件中没有 proxy-providers"))
+		return nil
+	}
+
+	failed := 0
+	for _, name := range names {
+		fmt.Printf(T("正在刷新 %s..."), name)
+		if err := refreshProxyProvider(name); err != nil {
+			fmt.Printf(T("失败: %v\n"), err)
+			failed++
+			continue
+		}
+		fmt.Println(T("成功"))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf(T("%d 个 proxy-provider 刷新失败"), failed)
+	}
+	return nil
+}
+
+// 调用 Clash API 刷新单个 proxy-provider
+func refreshProxyProvider(name string) error {
+	return defaultClashAPIClient().withTimeout(30 * time.Second).refreshProxyProvider(name)
+}
+
+// 交互式地将订阅链接添加为 proxy-provider
+func importSubscriptionAsProvider(subURL string) {
+	reader := stdinReader
+
+	// 默认使用订阅域名作为 provider 名称
+	defaultName := "subscription"
+	if u, err := url.Parse(subURL); err == nil && u.Hostname() != "" {
+		defaultName = u.Hostname()
+	}
+
+	fmt.Printf(T("请输入 proxy-provider 名称(默认为%s): "), defaultName)
+	name, _ := reader.ReadString('\n')
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultName
+	}
+
+	fmt.Print(T("请输入更新间隔，单位秒(默认为3600): "))
+	intervalStr, _ := reader.ReadString('\n')
+	interval := 3600
+	if v, err := strconv.Atoi(strings.TrimSpace(intervalStr)); err == nil && v > 0 {
+		interval = v
+	}
+
+	// 读取当前配置
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+
+	if err := addProxyProvider(config, name, subURL, interval); err != nil {
+		fmt.Printf(T("添加 proxy-provider 失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+
+	// 显示修改并确认后保存配置
+	saved, err := saveClashConfigWithConfirm(config)
+	if err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+	if !saved {
+		waitForKeyPress()
+		return
+	}
+
+	fmt.Printf(T("\nproxy-provider '%s' 已添加，节点将由 Clash 从订阅地址自动拉取\n"), name)
+	fmt.Println(T("提示: Clash Premium 内核要求订阅返回 Clash 格式的 YAML，Base64 节点列表需要 Meta 内核"))
+
+	// 询问是否重启Clash服务
+	promptRestartClash()
+
+	waitForKeyPress()
+}
+
+// 在配置中添加 http 类型的 proxy-provider，并在所有代理组中通过 use 引用
+func addProxyProvider(config *ClashConfig, name, subURL string, interval int) error {
+	if name == "" {
+		return errors.New(T("proxy-provider 名称不能为空"))
+	}
+
+	if config.ProxyProviders == nil {
+		config.ProxyProviders = make(map[string]ProxyProvider)
+	}
+
+	if _, exists := config.ProxyProviders[name]; exists {
+		return errorOf(ErrNameConflict, T("proxy-provider '%s' 已存在"), name)
+	}
+
+	// 确保缓存目录存在
+	if err := os.MkdirAll(clashProvidersDir(), 0755); err != nil {
+		return fmt.Errorf(T("创建 providers 目录失败: %w"), err)
+	}
+
+	config.ProxyProviders[name] = ProxyProvider{
+		Type:     "http",
+		URL:      subURL,
+		Interval: interval,
+		Path:     filepath.Join(clashProvidersDir(), name+".yaml"),
+		HealthCheck: &HealthCheck{
+			Enable:   true,
+			URL:      defaultHealthCheckURL,
+			Interval: 300,
+		},
+	}
+
+	// 在代理组中引用该 provider
+	updateProxyGroupProviders(config, name)
+
+	return nil
+}
+
+// 将 proxy-provider 添加到所有代理组的 use 列表中
+func updateProxyGroupProviders(config *ClashConfig, providerName string) {
+	for i := range config.ProxyGroups {
+		group := &config.ProxyGroups[i]
+
+		found := false
+		for _, name := range group.Use {
+			if name == providerName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			group.Use = append(group.Use, providerName)
+		}
+	}
+}
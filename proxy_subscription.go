@@ -0,0 +1,529 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 订阅内容的本地缓存目录
+func clashSubscriptionCacheDir() string {
+	return clashWorkPath("cache", "subscriptions")
+}
+
+// 一条已导入的订阅记录
+type SubscriptionRecord struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// 由该订阅导入的节点名称，刷新时只会替换这些节点
+	Nodes []string `yaml:"nodes,omitempty"`
+	// 用户重命名过的节点，键为订阅中的名称，值为配置中的名称
+	Renamed   map[string]string `yaml:"renamed,omitempty"`
+	UpdatedAt time.Time         `yaml:"updated-at,omitempty"`
+}
+
+// 订阅缓存的元数据，与缓存内容分开保存
+type subscriptionCacheMeta struct {
+	URL          string    `yaml:"url"`
+	ETag         string    `yaml:"etag,omitempty"`
+	LastModified string    `yaml:"last-modified,omitempty"`
+	FetchedAt    time.Time `yaml:"fetched-at"`
+	NodeCount    int       `yaml:"node-count"`
+}
+
+// 处理 subscription 子命令
+func manageSubscriptions(args []string) {
+	if len(args) == 0 {
+		printSubscriptionUsage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		if err := listSubscriptions(); err != nil {
+			fmt.Printf(T("列出订阅失败: %v\n"), err)
+			os.Exit(1)
+		}
+	case "refresh":
+		if err := refreshSubscriptions(args[1:]); err != nil {
+			fmt.Printf(T("刷新订阅失败: %v\n"), err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf(T("未知的 subscription 子命令: %s\n"), args[0])
+		printSubscriptionUsage()
+		os.Exit(1)
+	}
+}
+
+// 显示 subscription 子命令的用法
+func printSubscriptionUsage() {
+	fmt.Printf(T("用法: %s subscription <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  list               列出已导入的订阅及缓存状态"))
+	fmt.Println(T("  refresh [名称...]  重新下载订阅并替换对应节点，不指定名称时刷新全部"))
+}
+
+// 列出已导入的订阅
+func listSubscriptions() error {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+
+	if len(settings.Subscriptions) == 0 {
+		fmt.Println(T("还没有导入任何订阅"))
+		return nil
+	}
+
+	for _, sub := range settings.Subscriptions {
+		fmt.Printf(T("名称: %s\n"), sub.Name)
+		fmt.Printf(T("   订阅地址: %s\n"), sub.URL)
+		fmt.Printf(T("   节点数量: %d\n"), len(sub.Nodes))
+		if !sub.UpdatedAt.IsZero() {
+			fmt.Printf(T("   上次更新: %s\n"), sub.UpdatedAt.Format("2006-01-02 15:04:05"))
+		}
+		if _, meta, err := loadSubscriptionCache(sub.URL); err == nil {
+			fmt.Printf(T("   本地缓存: %s 下载，%d 个节点\n"), meta.FetchedAt.Format("2006-01-02 15:04:05"), meta.NodeCount)
+		} else {
+			fmt.Println(T("   本地缓存: 无"))
+		}
+		fmt.Println("   ------------------------")
+	}
+
+	return nil
+}
+
+// 刷新指定名称的订阅，不指定名称时刷新全部
+func refreshSubscriptions(names []string) error {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+
+	if len(settings.Subscriptions) == 0 {
+		fmt.Println(T("还没有导入任何订阅"))
+		return nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+
+	changed := false
+	refreshed := false
+	failed := 0
+	for i := range settings.Subscriptions {
+		sub := &settings.Subscriptions[i]
+		if len(wanted) > 0 && !wanted[sub.Name] {
+			continue
+		}
+		delete(wanted, sub.Name)
+
+		fmt.Printf(T("\n正在刷新订阅 %s...\n"), sub.Name)
+		updated, err := refreshSubscription(config, sub, settings.SubscriptionFetch)
+		if err != nil {
+			fmt.Printf(T("刷新失败: %v\n"), err)
+			failed++
+			continue
+		}
+		changed = changed || updated
+		refreshed = true
+	}
+
+	for name := range wanted {
+		fmt.Printf(T("未找到订阅: %s\n"), name)
+		failed++
+	}
+
+	if changed {
+		applyRegionGroups(config)
+
+		// 刷新通常由定时任务执行，只打印修改内容，不要求确认
+		if current, err := readClashConfig(); err == nil {
+			fmt.Println()
+			printConfigChanges(diffClashConfigs(current, config))
+		}
+		if err := saveClashConfig(config); err != nil {
+			return fmt.Errorf(T("保存配置失败: %w"), err)
+		}
+		if err := saveManagerSettings(settings); err != nil {
+			return fmt.Errorf(T("保存订阅记录失败: %w"), err)
+		}
+		fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+	} else if refreshed {
+		// 节点没有变化时不写配置，只记录订阅的更新时间
+		if err := saveManagerSettings(settings); err != nil {
+			return fmt.Errorf(T("保存订阅记录失败: %w"), err)
+		}
+		fmt.Println(T("\n节点没有变化，配置未修改"))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf(T("%d 个订阅刷新失败"), failed)
+	}
+	return nil
+}
+
+// 重新下载单个订阅并替换其导入的节点，返回配置是否发生变化
+func refreshSubscription(config *ClashConfig, sub *SubscriptionRecord, opts SubscriptionFetchOptions) (bool, error) {
+	body, err := fetchSubscriptionCached(sub.URL, opts)
+	if err != nil {
+		return false, err
+	}
+
+	// 没有有效节点时保留现有节点，绝不清空
+	proxies := parseSubscriptionProxies(body)
+	if len(proxies) == 0 {
+		return false, fmt.Errorf(T("订阅中没有有效节点，保留现有的 %d 个节点"), len(sub.Nodes))
+	}
+
+	owned := make(map[string]bool)
+	for _, name := range sub.Nodes {
+		owned[name] = true
+	}
+
+	// 新订阅中的节点，按名称索引
+	fresh := make(map[string]Proxy)
+	var freshNames []string
+	renamed := make(map[string]string)
+	for _, p := range proxies {
+		m, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		proxy, err := proxyFromMap(m)
+		if err != nil {
+			continue
+		}
+		// 用户重命名过的节点继续使用新名称
+		if alias, ok := sub.Renamed[proxy.Name]; ok {
+			renamed[proxy.Name] = alias
+			proxy.Name = alias
+		}
+		if _, dup := fresh[proxy.Name]; dup {
+			continue
+		}
+		ensureRequiredFields(&proxy)
+		fresh[proxy.Name] = proxy
+		freshNames = append(freshNames, proxy.Name)
+	}
+
+	// 替换或删除该订阅之前导入的节点，其他来源的节点保持不变
+	var newProxies []Proxy
+	existing := make(map[string]bool)
+	removed := 0
+	changed := false
+	for _, proxy := range config.Proxies {
+		name := proxy.Name
+		existing[name] = true
+
+		if !owned[name] {
+			newProxies = append(newProxies, proxy)
+			continue
+		}
+
+		if replacement, ok := fresh[name]; ok {
+			if len(diffProxyFields(&proxy, &replacement)) > 0 {
+				changed = true
+			}
+			newProxies = append(newProxies, replacement)
+		} else {
+			removeFromProxyGroups(config, name)
+			removed++
+		}
+	}
+
+	// 追加新增的节点，停用的节点仍属于该订阅，但不放回配置
+	disabled := disabledProxyNames()
+	var nodes []string
+	added := 0
+	for _, name := range freshNames {
+		if disabled[name] && !existing[name] {
+			nodes = append(nodes, name)
+			continue
+		}
+		if existing[name] && !owned[name] {
+			fmt.Printf(T("跳过与其他节点重名的节点: %s\n"), name)
+			continue
+		}
+		if !existing[name] {
+			newProxies = append(newProxies, fresh[name])
+			updateProxyGroup(config, name)
+			added++
+		}
+		nodes = append(nodes, name)
+	}
+
+	config.Proxies = newProxies
+	sub.Nodes = nodes
+	// 只保留订阅中仍然存在的节点的重命名记录
+	sub.Renamed = nil
+	if len(renamed) > 0 {
+		sub.Renamed = renamed
+	}
+	sub.UpdatedAt = time.Now()
+
+	fmt.Printf(T("订阅 %s: 共 %d 个节点，新增 %d 个，移除 %d 个\n"), sub.Name, len(nodes), added, removed)
+	return changed || added > 0 || removed > 0, nil
+}
+
+// 保存订阅记录，同一地址的订阅会被覆盖
+func recordSubscription(reader *bufio.Reader, subURL string, nodes []string) error {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+
+	for i := range settings.Subscriptions {
+		if settings.Subscriptions[i].URL == subURL {
+			settings.Subscriptions[i].Nodes = mergeNodeNames(settings.Subscriptions[i].Nodes, nodes)
+			settings.Subscriptions[i].UpdatedAt = time.Now()
+			return saveManagerSettings(settings)
+		}
+	}
+
+	// 默认使用订阅域名作为名称
+	defaultName := "subscription"
+	if u, err := url.Parse(subURL); err == nil && u.Hostname() != "" {
+		defaultName = u.Hostname()
+	}
+
+	fmt.Printf(T("请输入订阅名称，用于之后刷新(默认为%s): "), defaultName)
+	name, _ := reader.ReadString('\n')
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = defaultName
+	}
+
+	settings.Subscriptions = append(settings.Subscriptions, SubscriptionRecord{
+		Name:      name,
+		URL:       subURL,
+		Nodes:     nodes,
+		UpdatedAt: time.Now(),
+	})
+	return saveManagerSettings(settings)
+}
+
+// 合并两组节点名称并去重
+func mergeNodeNames(a, b []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// 获取订阅内容，成功时写入缓存；下载失败或内容无效时回退到上次成功的缓存
+func fetchSubscriptionCached(subURL string, opts SubscriptionFetchOptions) ([]byte, error) {
+	cachedBody, meta, cacheErr := loadSubscriptionCache(subURL)
+
+	// 有缓存时发送条件请求
+	var etag, lastModified string
+	if cacheErr == nil {
+		etag, lastModified = meta.ETag, meta.LastModified
+	}
+
+	resp, err := fetchSubscriptionConditional(subURL, opts, etag, lastModified)
+	if err != nil {
+		if cacheErr != nil {
+			return nil, err
+		}
+		fmt.Printf(T("下载订阅失败 (%v)，使用 %s 的本地缓存\n"), err, meta.FetchedAt.Format("2006-01-02 15:04:05"))
+		return cachedBody, nil
+	}
+
+	if resp.NotModified {
+		if cacheErr != nil {
+			return nil, errors.New(T("服务器返回 304，但本地没有缓存"))
+		}
+		fmt.Println(T("订阅内容未变化，使用本地缓存"))
+		return cachedBody, nil
+	}
+
+	// 检查内容是否有效，避免错误页面覆盖缓存
+	nodeCount := len(parseSubscriptionProxies(resp.Body))
+	if nodeCount == 0 {
+		reason := T("订阅内容中没有有效节点")
+		if looksLikeHTML(resp.Body) {
+			reason = T("订阅返回了 HTML 页面，可能是错误页")
+		}
+
+		if cacheErr != nil {
+			return nil, fmt.Errorf("%s", reason)
+		}
+		fmt.Printf(T("%s，使用 %s 的本地缓存\n"), reason, meta.FetchedAt.Format("2006-01-02 15:04:05"))
+		return cachedBody, nil
+	}
+
+	if err := saveSubscriptionCache(subURL, resp, nodeCount); err != nil {
+		fmt.Printf(T("警告: 写入订阅缓存失败: %v\n"), err)
+	}
+
+	return resp.Body, nil
+}
+
+// 订阅缓存文件的路径，以订阅地址的哈希命名
+func subscriptionCachePaths(subURL string) (string, string) {
+	sum := sha256.Sum256([]byte(subURL))
+	key := hex.EncodeToString(sum[:8])
+	base := filepath.Join(clashSubscriptionCacheDir(), key)
+	return base + ".body", base + ".meta.yaml"
+}
+
+// 读取订阅缓存
+func loadSubscriptionCache(subURL string) ([]byte, *subscriptionCacheMeta, error) {
+	bodyPath, metaPath := subscriptionCachePaths(subURL)
+
+	metaContent, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var meta subscriptionCacheMeta
+	if err := yaml.Unmarshal(metaContent, &meta); err != nil {
+		return nil, nil, err
+	}
+
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, &meta, nil
+}
+
+// 写入订阅缓存
+func saveSubscriptionCache(subURL string, resp *subscriptionResponse, nodeCount int) error {
+	if err := os.MkdirAll(clashSubscriptionCacheDir(), 0700); err != nil {
+		return err
+	}
+
+	bodyPath, metaPath := subscriptionCachePaths(subURL)
+	if err := os.WriteFile(bodyPath, resp.Body, 0600); err != nil {
+		return err
+	}
+
+	meta := subscriptionCacheMeta{
+		URL:          subURL,
+		ETag:         resp.ETag,
+		LastModified: resp.LastModified,
+		FetchedAt:    time.Now(),
+		NodeCount:    nodeCount,
+	}
+	content, err := yaml.Marshal(&meta)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(metaPath, content, 0600)
+}
+
+// 判断内容是否为 HTML 页面
+func looksLikeHTML(body []byte) bool {
+	trimmed := bytes.ToLower(bytes.TrimSpace(body))
+	return bytes.HasPrefix(trimmed, []byte("<!doctype html")) || bytes.HasPrefix(trimmed, []byte("<html"))
+}
+
+// 解析订阅内容，返回 Clash 格式的代理配置列表
+func parseSubscriptionProxies(body []byte) []interface{} {
+	if looksLikeHTML(body) {
+		return nil
+	}
+
+	// Clash YAML 格式
+	if proxies, ok := parseClashYAMLProxies(body); ok {
+		return proxies
+	}
+
+	// Base64 编码的节点列表，解码失败时作为普通文本处理
+	content := strings.TrimSpace(string(body))
+	if decoded, err := base64.StdEncoding.DecodeString(content); err == nil {
+		content = string(decoded)
+	} else if decoded, err := decodeBase64UrlSafe(content); err == nil {
+		content = string(decoded)
+	}
+
+	var proxies []interface{}
+	for _, line := range strings.Split(content, "\n") {
+		uri := strings.TrimSpace(line)
+
+		var proxy map[string]interface{}
+		var err error
+		switch {
+		case strings.HasPrefix(uri, "ss://"):
+			proxy, err = parseShadowsocksURI(uri)
+		case strings.HasPrefix(uri, "vmess://"):
+			proxy, err = parseVmessURI(uri)
+		case strings.HasPrefix(uri, "trojan://"):
+			proxy, err = parseTrojanURI(uri)
+		default:
+			continue
+		}
+
+		if err == nil {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
+
+// 记录订阅节点的重命名，之后刷新时订阅中的同一节点继续使用新名称，返回设置是否有变化
+func renameSubscriptionNode(settings *ManagerSettings, old, new string) bool {
+	changed := false
+	for i := range settings.Subscriptions {
+		sub := &settings.Subscriptions[i]
+		index := -1
+		for j, name := range sub.Nodes {
+			if name == old {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			continue
+		}
+		sub.Nodes[index] = new
+
+		// 找到节点在订阅中的原始名称，改回原名时删除记录
+		upstream := old
+		for name, alias := range sub.Renamed {
+			if alias == old {
+				upstream = name
+				break
+			}
+		}
+		if upstream == new {
+			delete(sub.Renamed, upstream)
+		} else {
+			if sub.Renamed == nil {
+				sub.Renamed = make(map[string]string)
+			}
+			sub.Renamed[upstream] = new
+		}
+		changed = true
+	}
+	return changed
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 生成一行 ss:// 订阅内容，加密方式和密码为 aes-256-gcm:pass
+func ssLine(name, server string) string {
+	return "ss://YWVzLTI1Ni1nY206cGFzcw==@" + server + ":8388#" + name
+}
+
+// 配置中节点的名称和服务器地址
+func proxyServers(config *ClashConfig) ([]string, map[string]string) {
+	var names []string
+	servers := make(map[string]string)
+	for _, proxy := range config.Proxies {
+		names = append(names, proxy.Name)
+		servers[proxy.Name] = proxy.Server
+	}
+	return names, servers
+}
+
+func TestRefreshSubscription(t *testing.T) {
+	// 将订阅中的节点 s1 重命名为 S1x
+	renameS1 := func(config *ClashConfig, settings *ManagerSettings) error {
+		if err := renameProxy(config, "s1", "S1x"); err != nil {
+			return err
+		}
+		renameSubscriptionNode(settings, "s1", "S1x")
+		return nil
+	}
+	initial := strings.Join([]string{ssLine("s1", "5.6.7.8"), ssLine("s2", "5.6.7.9")}, "\n")
+
+	tests := []struct {
+		name string
+		// 第二次刷新时订阅返回的内容
+		after       string
+		wantProxies []string
+		wantServers map[string]string
+		wantGroup   []string
+		wantNodes   []string
+		// 第二次刷新是否修改了配置
+		wantChanged bool
+		// 两次刷新之间对配置和订阅记录的修改
+		between     func(config *ClashConfig, settings *ManagerSettings) error
+		wantRenamed map[string]string
+	}{
+		{
+			name:        "内容不变时保留节点",
+			after:       initial,
+			wantProxies: []string{"mine", "s1", "s2"},
+			wantGroup:   []string{"mine", "s1", "s2"},
+			wantNodes:   []string{"s1", "s2"},
+		},
+		{
+			name:        "追加新增的节点",
+			after:       initial + "\n" + ssLine("s3", "5.6.7.10"),
+			wantProxies: []string{"mine", "s1", "s2", "s3"},
+			wantGroup:   []string{"mine", "s1", "s2", "s3"},
+			wantNodes:   []string{"s1", "s2", "s3"},
+			wantChanged: true,
+		},
+		{
+			name:        "移除订阅中不再存在的节点",
+			after:       ssLine("s1", "5.6.7.8"),
+			wantProxies: []string{"mine", "s1"},
+			wantGroup:   []string{"mine", "s1"},
+			wantNodes:   []string{"s1"},
+			wantChanged: true,
+		},
+		{
+			name:        "原位置替换修改过的节点",
+			after:       ssLine("s1", "9.9.9.9") + "\n" + ssLine("s2", "5.6.7.9"),
+			wantProxies: []string{"mine", "s1", "s2"},
+			wantServers: map[string]string{"s1": "9.9.9.9"},
+			wantGroup:   []string{"mine", "s1", "s2"},
+			wantNodes:   []string{"s1", "s2"},
+			wantChanged: true,
+		},
+		{
+			name:        "跳过与其他来源的节点重名的节点",
+			after:       initial + "\n" + ssLine("mine", "5.6.7.11"),
+			wantProxies: []string{"mine", "s1", "s2"},
+			wantServers: map[string]string{"mine": "1.1.1.1"},
+			wantGroup:   []string{"mine", "s1", "s2"},
+			wantNodes:   []string{"s1", "s2"},
+		},
+		{
+			name:        "订阅返回错误页时使用缓存，保留现有节点",
+			after:       "<html>502 Bad Gateway</html>",
+			wantProxies: []string{"mine", "s1", "s2"},
+			wantGroup:   []string{"mine", "s1", "s2"},
+			wantNodes:   []string{"s1", "s2"},
+		},
+		{
+			name:        "重命名过的节点保留新名称，不会重复导入",
+			after:       initial,
+			between:     renameS1,
+			wantProxies: []string{"mine", "S1x", "s2"},
+			wantGroup:   []string{"mine", "S1x", "s2"},
+			wantNodes:   []string{"S1x", "s2"},
+			wantRenamed: map[string]string{"s1": "S1x"},
+		},
+		{
+			name:        "重命名的节点从订阅中消失时删除重命名记录",
+			after:       ssLine("s2", "5.6.7.9"),
+			between:     renameS1,
+			wantProxies: []string{"mine", "s2"},
+			wantGroup:   []string{"mine", "s2"},
+			wantNodes:   []string{"s2"},
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempWorkDir(t)
+
+			body := initial
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			config := &ClashConfig{
+				Proxies:     []Proxy{{Name: "mine", Type: "http", Server: "1.1.1.1", Port: PortValue{Number: 80}}},
+				ProxyGroups: []ProxyGroup{{Name: "Proxy", Type: "select", Proxies: []string{"mine"}}},
+			}
+			settings := &ManagerSettings{Subscriptions: []SubscriptionRecord{{Name: "test", URL: server.URL}}}
+			sub := &settings.Subscriptions[0]
+			opts := SubscriptionFetchOptions{Timeout: 5}
+
+			if _, err := refreshSubscription(config, sub, opts); err != nil {
+				t.Fatalf("首次刷新: %v", err)
+			}
+			if tt.between != nil {
+				if err := tt.between(config, settings); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			body = tt.after
+			changed, err := refreshSubscription(config, sub, opts)
+			if err != nil {
+				t.Fatalf("第二次刷新: %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v，期望 %v", changed, tt.wantChanged)
+			}
+
+			names, servers := proxyServers(config)
+			if !reflect.DeepEqual(names, tt.wantProxies) {
+				t.Errorf("节点 = %v，期望 %v", names, tt.wantProxies)
+			}
+			for name, server := range tt.wantServers {
+				if servers[name] != server {
+					t.Errorf("%s 的地址 = %s，期望 %s", name, servers[name], server)
+				}
+			}
+			if members := config.ProxyGroups[0].Proxies; !reflect.DeepEqual(members, tt.wantGroup) {
+				t.Errorf("代理组成员 = %v，期望 %v", members, tt.wantGroup)
+			}
+			if !reflect.DeepEqual(sub.Nodes, tt.wantNodes) {
+				t.Errorf("订阅节点 = %v，期望 %v", sub.Nodes, tt.wantNodes)
+			}
+			if !reflect.DeepEqual(sub.Renamed, tt.wantRenamed) {
+				t.Errorf("重命名记录 = %v，期望 %v", sub.Renamed, tt.wantRenamed)
+			}
+		})
+	}
+}
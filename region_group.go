@@ -0,0 +1,518 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 按地区自动生成的顶层选择组
+const regionSelectorName = "🌍 地区选择"
+
+// 无法识别地区的节点所在的代理组
+const otherRegionCode = "OTHER"
+
+// 解析节点服务器地址的超时时间和并发数
+const (
+	regionLookupTimeout     = 3 * time.Second
+	regionLookupConcurrency = 16
+)
+
+// 按地区自动分组的设置
+type RegionGroupSettings struct {
+	Enable bool `yaml:"enable"`
+	// 上次生成的代理组名称，重新生成时只替换这些代理组
+	Groups []string `yaml:"groups,omitempty"`
+}
+
+// 一个地区的识别规则
+type regionInfo struct {
+	Code string
+	Name string
+	// 节点名称中出现时即可识别的关键字，不区分大小写
+	Keywords []string
+	// 大写的地区代码，前后不能紧邻字母，例如 HK01、[JP]
+	Codes []string
+}
+
+// 内置的地区识别规则，按生成代理组的顺序排列
+var regionTable = []regionInfo{
+	{"HK", "香港", []string{"香港", "hong kong", "hongkong"}, []string{"HK", "HKG"}},
+	{"TW", "台湾", []string{"台湾", "台灣", "臺灣", "台北", "taiwan"}, []string{"TW", "TWN"}},
+	{"JP", "日本", []string{"日本", "东京", "東京", "大阪", "japan", "tokyo", "osaka"}, []string{"JP", "JPN"}},
+	{"SG", "新加坡", []string{"新加坡", "狮城", "獅城", "singapore"}, []string{"SG", "SGP"}},
+	{"US", "美国", []string{"美国", "美國", "洛杉矶", "圣何塞", "硅谷", "西雅图", "纽约", "芝加哥", "united states", "america"}, []string{"US", "USA"}},
+	{"KR", "韩国", []string{"韩国", "韓國", "首尔", "首爾", "korea", "seoul"}, []string{"KR", "KOR"}},
+	{"GB", "英国", []string{"英国", "英國", "伦敦", "united kingdom", "london"}, []string{"UK", "GB", "GBR"}},
+	{"DE", "德国", []string{"德国", "德國", "法兰克福", "germany", "frankfurt"}, []string{"DE", "DEU"}},
+	{"FR", "法国", []string{"法国", "法國", "巴黎", "france", "paris"}, []string{"FR", "FRA"}},
+	{"NL", "荷兰", []string{"荷兰", "荷蘭", "阿姆斯特丹", "netherlands", "amsterdam"}, []string{"NL", "NLD"}},
+	{"CA", "加拿大", []string{"加拿大", "多伦多", "canada", "toronto"}, []string{"CA", "CAN"}},
+	{"AU", "澳大利亚", []string{"澳大利亚", "澳洲", "悉尼", "australia", "sydney"}, []string{"AU", "AUS"}},
+	{"RU", "俄罗斯", []string{"俄罗斯", "俄羅斯", "莫斯科", "russia", "moscow"}, []string{"RU", "RUS"}},
+	{"IN", "印度", []string{"印度", "孟买", "india", "mumbai"}, []string{"IN", "IND"}},
+	{"TR", "土耳其", []string{"土耳其", "turkey", "türkiye"}, []string{"TR", "TUR"}},
+}
+
+// 编译后的地区识别规则
+type regionMatcher struct {
+	info    regionInfo
+	pattern *regexp.Regexp
+}
+
+var (
+	regionMatchersOnce sync.Once
+	regionMatchers     []regionMatcher
+)
+
+// 编译地区识别规则，关键字和国旗不区分大小写，地区代码只匹配大写
+func compiledRegionMatchers() []regionMatcher {
+	regionMatchersOnce.Do(func() {
+		for _, info := range regionTable {
+			var alts []string
+			for _, keyword := range append(info.Keywords, regionFlag(info.Code)) {
+				alts = append(alts, "(?i:"+regexp.QuoteMeta(keyword)+")")
+			}
+			for _, code := range info.Codes {
+				alts = append(alts, `(?:^|[^A-Za-z])`+code+`(?:[^A-Za-z]|$)`)
+			}
+			regionMatchers = append(regionMatchers, regionMatcher{
+				info:    info,
+				pattern: regexp.MustCompile(strings.Join(alts, "|")),
+			})
+		}
+	})
+	return regionMatchers
+}
+
+// 两位地区代码对应的国旗 emoji
+func regionFlag(code string) string {
+	if len(code) != 2 {
+		return "🌐"
+	}
+	var flag []rune
+	for _, c := range strings.ToUpper(code) {
+		if c < 'A' || c > 'Z' {
+			return "🌐"
+		}
+		flag = append(flag, 0x1F1E6+c-'A')
+	}
+	return string(flag)
+}
+
+// 根据节点名称识别地区，名称中最先出现的地区优先，例如 "香港-日本 中转" 识别为香港
+func regionFromName(name string) string {
+	best, bestIndex := "", -1
+	for _, matcher := range compiledRegionMatchers() {
+		loc := matcher.pattern.FindStringIndex(name)
+		if loc != nil && (bestIndex < 0 || loc[0] < bestIndex) {
+			best, bestIndex = matcher.info.Code, loc[0]
+		}
+	}
+	return best
+}
+
+// 地区代理组的名称，例如 🇭🇰 香港
+func regionGroupName(code string) string {
+	if code == otherRegionCode {
+		return "🌐 其他"
+	}
+	for _, info := range regionTable {
+		if info.Code == code {
+			return regionFlag(code) + " " + info.Name
+		}
+	}
+	return regionFlag(code) + " " + code
+}
+
+// 节点的分类结果
+type regionAssignment struct {
+	Code string
+	// 识别依据: 名称或 GeoIP
+	Source string
+}
+
+// 为所有节点识别地区，名称无法识别时查询服务器 IP 在 Country.mmdb 中的国家
+func classifyProxyRegions(proxies []Proxy) map[string]regionAssignment {
+	result := make(map[string]regionAssignment)
+	var unknown []Proxy
+	for _, proxy := range proxies {
+		if code := regionFromName(proxy.Name); code != "" {
+			result[proxy.Name] = regionAssignment{Code: code, Source: T("名称")}
+		} else {
+			unknown = append(unknown, proxy)
+		}
+	}
+	if len(unknown) == 0 {
+		return result
+	}
+
+	mmdb, _, err := openCountryMMDB()
+	if err != nil {
+		fmt.Printf(T("无法使用 GeoIP 识别地区: %v\n"), err)
+		for _, proxy := range unknown {
+			result[proxy.Name] = regionAssignment{Code: otherRegionCode}
+		}
+		return result
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, regionLookupConcurrency)
+	for _, proxy := range unknown {
+		wg.Add(1)
+		go func(name, server string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			assignment := regionAssignment{Code: otherRegionCode}
+			if code := lookupServerCountry(mmdb, server); code != "" {
+				assignment = regionAssignment{Code: code, Source: "GeoIP"}
+			}
+			mu.Lock()
+			result[name] = assignment
+			mu.Unlock()
+		}(proxy.Name, proxy.Server)
+	}
+	wg.Wait()
+	return result
+}
+
+// 查询服务器地址所属的国家，域名先通过系统 DNS 解析
+func lookupServerCountry(mmdb *mmdbReader, server string) string {
+	if server == "" {
+		return ""
+	}
+	addr, err := netip.ParseAddr(server)
+	if err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), regionLookupTimeout)
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", server)
+		if err != nil || len(addrs) == 0 {
+			return ""
+		}
+		addr = addrs[0]
+	}
+
+	code, err := mmdb.country(addr)
+	if err != nil {
+		return ""
+	}
+	return strings.ToUpper(code)
+}
+
+// 地区代码的排列顺序：内置地区按表中顺序，其它地区按代码排序，无法识别的放在最后
+func sortRegionCodes(codes []string) {
+	order := make(map[string]int)
+	for i, info := range regionTable {
+		order[info.Code] = i
+	}
+	rank := func(code string) (int, string) {
+		if code == otherRegionCode {
+			return len(regionTable) + 1, code
+		}
+		if i, ok := order[code]; ok {
+			return i, ""
+		}
+		return len(regionTable), code
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		ri, ci := rank(codes[i])
+		rj, cj := rank(codes[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return ci < cj
+	})
+}
+
+// 根据节点生成各地区的 url-test 代理组和顶层选择组，只替换上次生成的代理组
+func updateRegionGroups(config *ClashConfig, opts *RegionGroupSettings) {
+	assignments := classifyProxyRegions(config.Proxies)
+
+	members := make(map[string][]string)
+	for _, proxy := range config.Proxies {
+		code := assignments[proxy.Name].Code
+		members[code] = append(members[code], proxy.Name)
+	}
+	var codes []string
+	for code := range members {
+		codes = append(codes, code)
+	}
+	sortRegionCodes(codes)
+
+	managed := make(map[string]bool)
+	for _, name := range opts.Groups {
+		managed[name] = true
+	}
+	// 与用户自己的代理组或节点重名时跳过该地区
+	taken := func(name string) bool {
+		return !managed[name] && policyNameExists(config, name)
+	}
+
+	fresh := make(map[string]ProxyGroup)
+	var order []string
+	selector := ProxyGroup{Name: regionSelectorName, Type: "select"}
+	for _, code := range codes {
+		name := regionGroupName(code)
+		if taken(name) {
+			fmt.Printf(T("已存在名为 %s 的代理组或节点，跳过该地区\n"), name)
+			continue
+		}
+		group := ProxyGroup{Name: name, Type: "url-test", Proxies: members[code]}
+		applyGroupDefaults(&group)
+		fresh[name] = group
+		order = append(order, name)
+		selector.Proxies = append(selector.Proxies, name)
+	}
+
+	// 已没有节点但仍被规则使用的地区保留为只包含 DIRECT 的代理组
+	for _, name := range opts.Groups {
+		if _, ok := fresh[name]; ok || name == regionSelectorName || config.FindGroup(name) == nil {
+			continue
+		}
+		for _, text := range config.Rules {
+			if target, err := ruleTarget(text); err == nil && target == name {
+				fmt.Printf(T("%s 已没有节点，但仍被规则使用，暂时只包含 DIRECT\n"), name)
+				fresh[name] = ProxyGroup{Name: name, Type: "select", Proxies: []string{"DIRECT"}}
+				order = append(order, name)
+				break
+			}
+		}
+	}
+
+	if len(selector.Proxies) == 0 {
+		selector.Proxies = []string{"DIRECT"}
+	}
+	if taken(regionSelectorName) {
+		fmt.Printf(T("已存在名为 %s 的代理组或节点，跳过地区选择组\n"), regionSelectorName)
+	} else {
+		if old := config.FindGroup(regionSelectorName); old != nil && containsString(selector.Proxies, old.Selected) {
+			selector.Selected = old.Selected
+		}
+		fresh[regionSelectorName] = selector
+		order = append(order, regionSelectorName)
+	}
+
+	// 原位置替换仍然存在的代理组，删除已经没有节点的地区
+	placed := make(map[string]bool)
+	var dropped []string
+	var groups []ProxyGroup
+	for _, group := range config.ProxyGroups {
+		if !managed[group.Name] {
+			groups = append(groups, group)
+			continue
+		}
+		if replacement, ok := fresh[group.Name]; ok {
+			groups = append(groups, replacement)
+			placed[group.Name] = true
+		} else {
+			dropped = append(dropped, group.Name)
+		}
+	}
+	for _, name := range order {
+		if !placed[name] {
+			groups = append(groups, fresh[name])
+		}
+	}
+	config.ProxyGroups = groups
+	for _, name := range dropped {
+		removeFromProxyGroups(config, name)
+	}
+
+	opts.Groups = order
+}
+
+// 启用按地区分组时，导入节点或刷新订阅后重新生成地区代理组
+func applyRegionGroups(config *ClashConfig) {
+	settings, err := loadManagerSettings()
+	if err != nil || !settings.RegionGroups.Enable {
+		return
+	}
+
+	fmt.Println(T("\n正在按地区重新生成代理组..."))
+	updateRegionGroups(config, &settings.RegionGroups)
+	if err := saveManagerSettings(settings); err != nil {
+		fmt.Printf(T("保存地区分组设置失败: %v\n"), err)
+	}
+}
+
+// 处理 group regions 子命令
+func manageRegionGroups(args []string) error {
+	action := "show"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+	// 修改前的配置，用于显示差异
+	current, _ := readClashConfig()
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "show":
+		showRegionClassification(config)
+		return nil
+	case "enable":
+		settings.RegionGroups.Enable = true
+		updateRegionGroups(config, &settings.RegionGroups)
+		linkRegionSelector(config, &settings.RegionGroups)
+	case "update":
+		if !settings.RegionGroups.Enable {
+			return errors.New(T("按地区分组未启用，请先执行 group regions enable"))
+		}
+		updateRegionGroups(config, &settings.RegionGroups)
+	case "disable":
+		if err := removeRegionGroups(config, &settings.RegionGroups); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf(T("未知的 regions 操作: %s (可选 show、enable、update、disable)"), action)
+	}
+
+	// 命令行模式通常用于脚本，只打印修改内容，不要求确认
+	printConfigChanges(diffClashConfigs(current, config))
+	if err := saveClashConfig(config); err != nil {
+		return fmt.Errorf(T("保存配置失败: %w"), err)
+	}
+	if err := saveManagerSettings(settings); err != nil {
+		return fmt.Errorf(T("保存地区分组设置失败: %w"), err)
+	}
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+	return nil
+}
+
+// 首次启用时把地区选择组加入第一个 select 代理组，使其可以在面板中选择
+func linkRegionSelector(config *ClashConfig, opts *RegionGroupSettings) {
+	if config.FindGroup(regionSelectorName) == nil {
+		return
+	}
+	managed := make(map[string]bool)
+	for _, name := range opts.Groups {
+		managed[name] = true
+	}
+	for _, group := range config.ProxyGroups {
+		if containsString(group.Proxies, regionSelectorName) {
+			return
+		}
+	}
+	if group := primarySelectGroup(config, managed); group != nil {
+		group.Proxies = append([]string{regionSelectorName}, group.Proxies...)
+		fmt.Printf(T("已将 %s 加入代理组 %s\n"), regionSelectorName, group.Name)
+	}
+}
+
+// 交互式启用或停用按地区分组
+func interactiveToggleRegionGroups(config *ClashConfig) {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		fmt.Printf(T("读取设置失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+
+	if settings.RegionGroups.Enable {
+		fmt.Println(T("\n按地区分组已启用，将停用并删除生成的地区代理组"))
+		err = removeRegionGroups(config, &settings.RegionGroups)
+	} else {
+		fmt.Println(T("\n将按节点名称和 GeoIP 生成各地区的 url-test 代理组，之后导入节点或刷新订阅时自动更新"))
+		settings.RegionGroups.Enable = true
+		updateRegionGroups(config, &settings.RegionGroups)
+		linkRegionSelector(config, &settings.RegionGroups)
+	}
+	if err != nil {
+		fmt.Printf(T("操作失败: %v\n"), err)
+		waitForKeyPress()
+		return
+	}
+
+	saved, err := saveClashConfigWithConfirm(config)
+	if err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+	} else if saved {
+		if err := saveManagerSettings(settings); err != nil {
+			fmt.Printf(T("保存地区分组设置失败: %v\n"), err)
+		}
+		promptRestartClash()
+	}
+	waitForKeyPress()
+}
+
+// 停用按地区分组并删除生成的代理组，仍被规则使用时拒绝
+func removeRegionGroups(config *ClashConfig, opts *RegionGroupSettings) error {
+	managed := make(map[string]bool)
+	for _, name := range opts.Groups {
+		managed[name] = true
+	}
+	for _, text := range config.Rules {
+		if target, err := ruleTarget(text); err == nil && managed[target] {
+			return errorOf(ErrReferencedByRules, T("代理组 %s 仍被规则 %s 使用，请先修改规则"), target, text)
+		}
+	}
+
+	var groups []ProxyGroup
+	for _, group := range config.ProxyGroups {
+		if !managed[group.Name] {
+			groups = append(groups, group)
+		}
+	}
+	config.ProxyGroups = groups
+	for _, name := range opts.Groups {
+		removeFromProxyGroups(config, name)
+	}
+
+	opts.Enable = false
+	opts.Groups = nil
+	return nil
+}
+
+// 显示节点的地区识别结果
+func showRegionClassification(config *ClashConfig) {
+	if len(config.Proxies) == 0 {
+		fmt.Println(T("没有配置任何代理节点"))
+		return
+	}
+
+	assignments := classifyProxyRegions(config.Proxies)
+	members := make(map[string][]string)
+	for _, proxy := range config.Proxies {
+		assignment := assignments[proxy.Name]
+		label := proxy.Name
+		if assignment.Source == "GeoIP" {
+			label += " (GeoIP)"
+		}
+		members[assignment.Code] = append(members[assignment.Code], label)
+	}
+	var codes []string
+	for code := range members {
+		codes = append(codes, code)
+	}
+	sortRegionCodes(codes)
+
+	for _, code := range codes {
+		fmt.Printf(T("%s: %d 个节点\n"), regionGroupName(code), len(members[code]))
+		for _, label := range members[code] {
+			fmt.Printf("   %s\n", label)
+		}
+	}
+
+	if settings, err := loadManagerSettings(); err == nil && settings.RegionGroups.Enable {
+		fmt.Println(T("\n按地区分组: 已启用，导入节点或刷新订阅时自动更新"))
+	} else {
+		fmt.Println(T("\n按地区分组: 未启用，使用 group regions enable 启用"))
+	}
+}
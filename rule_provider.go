@@ -0,0 +1,1062 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 定时刷新规则集的 systemd 单元名称
+const ruleSetRefreshUnit = "clash-ruleset-refresh"
+
+// http 类型 rule-provider 默认的更新间隔 (秒)
+const defaultRuleSetInterval = 86400
+
+// 转换后的规则集文件所在目录
+func clashRulesetDir() string {
+	return clashWorkPath("ruleset")
+}
+
+// 规则集文件的路径
+func ruleSetPath(name string) string {
+	return filepath.Join(clashRulesetDir(), name+".yaml")
+}
+
+// 通过 ruleset add 导入的规则集，刷新时重新下载并转换
+type RuleSetRecord struct {
+	Name string `yaml:"name"`
+	// 来源文件路径或 URL
+	Source string `yaml:"source"`
+	// 来源格式: plain、adblock、gfwlist 或 clash
+	Format    string    `yaml:"format"`
+	Behavior  string    `yaml:"behavior"`
+	Type      string    `yaml:"type"`
+	Count     int       `yaml:"count"`
+	UpdatedAt time.Time `yaml:"updated-at,omitempty"`
+}
+
+// 可以导入的来源格式
+var ruleSetFormats = []string{"plain", "adblock", "gfwlist", "clash"}
+
+// rule-provider 支持的 behavior
+var ruleSetBehaviors = map[string]bool{
+	"domain":    true,
+	"ipcidr":    true,
+	"classical": true,
+}
+
+// 规则集中一条条目的种类
+const (
+	// 完整域名
+	ruleEntryDomain = "domain"
+	// 域名及其所有子域名
+	ruleEntrySuffix = "suffix"
+	// .example.com、*.example.com 等只有 domain 规则集支持的写法
+	ruleEntryPattern = "pattern"
+	ruleEntryCIDR    = "ipcidr"
+	// DOMAIN-SUFFIX,example.com 等完整规则
+	ruleEntryClassical = "classical"
+)
+
+// 从列表中解析出的一条条目
+type ruleSetEntry struct {
+	kind  string
+	value string
+}
+
+// 处理 ruleset 子命令
+func manageRuleSets(args []string) {
+	if len(args) == 0 {
+		printRuleSetUsage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch args[0] {
+	case "list":
+		err = listRuleSets()
+	case "add":
+		err = addRuleSetCommand(args[1:])
+	case "remove":
+		if len(args) != 2 {
+			printRuleSetUsage()
+			os.Exit(1)
+		}
+		err = removeRuleSet(args[1])
+	case "refresh":
+		err = refreshRuleSets(args[1:])
+	case "timer":
+		err = manageRuleSetTimer(args[1:])
+	default:
+		fmt.Printf(T("未知的 ruleset 子命令: %s\n"), args[0])
+		printRuleSetUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf(T("ruleset %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+}
+
+// 显示 ruleset 子命令的用法
+func printRuleSetUsage() {
+	fmt.Printf(T("用法: %s ruleset <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  list                          列出 rule-providers 及其来源"))
+	fmt.Println(T("  add <名称> <文件|URL> [选项]  将域名/IP 列表转换为 rule-provider"))
+	fmt.Println(T("      --format auto|plain|adblock|gfwlist|clash  来源格式，默认自动识别"))
+	fmt.Println(T("      --behavior domain|ipcidr|classical         规则集类型，默认根据内容推断"))
+	fmt.Println(T("      --type file|http          file 由本工具转换并刷新，http 由 Clash 直接下载 (仅限 clash 格式)"))
+	fmt.Println(T("      --interval 秒             http 类型的更新间隔，默认 86400"))
+	fmt.Println(T("      --policy 策略             同时添加 RULE-SET,<名称>,<策略> 规则"))
+	fmt.Println(T("      --position N              RULE-SET 规则的插入位置，默认在 MATCH 之前"))
+	fmt.Println(T("  remove <名称>                 删除 rule-provider、引用它的规则和规则集文件"))
+	fmt.Println(T("  refresh [名称...]             重新下载并转换规则集，通知 Clash 重新加载"))
+	fmt.Println(T("  timer enable [OnCalendar]     启用 systemd 定时刷新，默认 daily"))
+	fmt.Println(T("  timer disable|status          停用定时刷新或查看状态"))
+	fmt.Println(T("\n纯文本列表中的裸域名按后缀匹配处理，full: 前缀表示完整域名"))
+	fmt.Printf(T("规则集文件保存在 %s\n"), clashRulesetDir())
+}
+
+// 规则集名称会用作文件名并出现在规则中
+func validateRuleSetName(name string) error {
+	if name == "" {
+		return errors.New(T("名称不能为空"))
+	}
+	if strings.ContainsAny(name, ",/\\ \t") || name == "." || name == ".." {
+		return fmt.Errorf(T("名称 %q 不能包含逗号、空格或路径分隔符"), name)
+	}
+	return nil
+}
+
+// 列出 rule-providers，附带导入来源
+func listRuleSets() error {
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+
+	if len(config.RuleProviders) == 0 {
+		fmt.Println(T("配置文件中没有 rule-providers"))
+		return nil
+	}
+
+	fmt.Printf(T("共找到 %d 个 rule-provider:\n\n"), len(config.RuleProviders))
+	for _, name := range sortedKeys(config.RuleProviders) {
+		provider := config.RuleProviders[name]
+		fmt.Printf(T("名称: %s\n"), name)
+		fmt.Printf(T("   类型: %s, behavior: %s\n"), provider.Type, provider.Behavior)
+		if provider.URL != "" {
+			fmt.Printf(T("   下载地址: %s\n"), provider.URL)
+		}
+		if provider.Path != "" {
+			fmt.Printf(T("   文件路径: %s\n"), provider.Path)
+		}
+		if record := findRuleSetRecord(settings, name); record != nil {
+			fmt.Printf(T("   导入来源: %s (%s)\n"), record.Source, record.Format)
+			fmt.Printf(T("   条目数量: %d\n"), record.Count)
+			if !record.UpdatedAt.IsZero() {
+				fmt.Printf(T("   上次更新: %s\n"), record.UpdatedAt.Format("2006-01-02 15:04:05"))
+			}
+		} else {
+			fmt.Println(T("   导入来源: 配置文件中手动添加"))
+		}
+
+		var refs []string
+		for _, text := range config.Rules {
+			if rule, err := parseRule(text); err == nil && rule.Type == "RULE-SET" && rule.Payload == name {
+				refs = append(refs, text)
+			}
+		}
+		if len(refs) == 0 {
+			fmt.Println(T("   引用规则: 无"))
+		} else {
+			fmt.Printf(T("   引用规则: %s\n"), strings.Join(refs, "; "))
+		}
+		fmt.Println("   ------------------------")
+	}
+
+	return nil
+}
+
+// 查找规则集的导入记录
+func findRuleSetRecord(settings *ManagerSettings, name string) *RuleSetRecord {
+	for i := range settings.RuleSets {
+		if settings.RuleSets[i].Name == name {
+			return &settings.RuleSets[i]
+		}
+	}
+	return nil
+}
+
+// 处理 ruleset add 命令
+func addRuleSetCommand(args []string) error {
+	var positional []string
+	format, behavior, providerType, policy := "auto", "", "file", ""
+	interval, position := defaultRuleSetInterval, 0
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			positional = append(positional, arg)
+			continue
+		}
+		if i+1 >= len(args) {
+			return fmt.Errorf(T("%s 需要指定参数值"), arg)
+		}
+		i++
+		value := args[i]
+		switch arg {
+		case "--format":
+			format = strings.ToLower(value)
+		case "--behavior":
+			behavior = strings.ToLower(value)
+		case "--type":
+			providerType = strings.ToLower(value)
+		case "--policy":
+			policy = value
+		case "--interval", "--position":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return fmt.Errorf(T("%s 的值无效: %s"), arg, value)
+			}
+			if arg == "--interval" {
+				interval = n
+			} else {
+				position = n
+			}
+		default:
+			return fmt.Errorf(T("未知的参数: %s"), arg)
+		}
+	}
+
+	if len(positional) != 2 {
+		printRuleSetUsage()
+		os.Exit(1)
+	}
+	name, source := positional[0], positional[1]
+
+	if err := validateRuleSetName(name); err != nil {
+		return err
+	}
+	if format != "auto" && !containsString(ruleSetFormats, format) {
+		return fmt.Errorf(T("不支持的格式: %s (可选 auto、%s)"), format, strings.Join(ruleSetFormats, "、"))
+	}
+	if behavior != "" && !ruleSetBehaviors[behavior] {
+		return fmt.Errorf(T("不支持的 behavior: %s (可选 domain、ipcidr、classical)"), behavior)
+	}
+	if providerType != "file" && providerType != "http" {
+		return fmt.Errorf(T("不支持的类型: %s (可选 file、http)"), providerType)
+	}
+	if providerType == "http" && !isRemoteSource(source) {
+		return errors.New(T("http 类型的来源必须是 http(s) 地址"))
+	}
+	// 本地文件保存绝对路径，定时刷新时不依赖当前目录
+	if !isRemoteSource(source) {
+		if abs, err := filepath.Abs(source); err == nil {
+			source = abs
+		}
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+	// 修改前的配置，用于显示差异
+	current, _ := readClashConfig()
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+
+	if _, exists := config.RuleProviders[name]; exists {
+		return errorOf(ErrNameConflict, T("rule-provider %s 已存在"), name)
+	}
+
+	fmt.Printf(T("正在读取 %s...\n"), source)
+	content, err := readRuleSetSource(source, settings.SubscriptionFetch)
+	if err != nil {
+		return err
+	}
+	if format == "auto" {
+		format = detectRuleSetFormat(content)
+		fmt.Printf(T("识别到的格式: %s\n"), format)
+	}
+	if providerType == "http" && format != "clash" {
+		return fmt.Errorf(T("http 类型由 Clash 直接下载，只支持 clash 格式的来源，%s 格式请使用 --type file"), format)
+	}
+
+	entries, err := parseRuleSetEntries(content, format)
+	if err != nil {
+		return err
+	}
+	if behavior == "" {
+		behavior = inferRuleSetBehavior(entries)
+	}
+	payload, skipped := renderRuleSetPayload(entries, behavior)
+	if len(payload) == 0 {
+		return fmt.Errorf(T("来源中没有可用于 %s 规则集的条目"), behavior)
+	}
+
+	provider := RuleProvider{
+		Type:     providerType,
+		Behavior: behavior,
+		Path:     ruleSetPath(name),
+	}
+	if providerType == "http" {
+		provider.URL = source
+		provider.Interval = interval
+	}
+	if config.RuleProviders == nil {
+		config.RuleProviders = make(map[string]RuleProvider)
+	}
+	config.RuleProviders[name] = provider
+
+	if policy != "" {
+		if _, err := addRule(config, fmt.Sprintf("RULE-SET,%s,%s", name, policy), position); err != nil {
+			return err
+		}
+	}
+
+	if err := writeRuleSetFile(name, source, format, payload); err != nil {
+		return err
+	}
+
+	fmt.Printf(T("已转换 %d 条 %s 条目"), len(payload), behavior)
+	if skipped > 0 {
+		fmt.Printf(T("，跳过 %d 条不适用于该类型的条目"), skipped)
+	}
+	fmt.Println()
+
+	// 命令行模式通常用于脚本，只打印修改内容，不要求确认
+	printConfigChanges(diffClashConfigs(current, config))
+	if err := saveClashConfig(config); err != nil {
+		return fmt.Errorf(T("保存配置失败: %w"), err)
+	}
+
+	settings.RuleSets = append(settings.RuleSets, RuleSetRecord{
+		Name:      name,
+		Source:    source,
+		Format:    format,
+		Behavior:  behavior,
+		Type:      providerType,
+		Count:     len(payload),
+		UpdatedAt: time.Now(),
+	})
+	if err := saveManagerSettings(settings); err != nil {
+		return fmt.Errorf(T("保存规则集记录失败: %w"), err)
+	}
+
+	if policy == "" {
+		fmt.Printf(T("\n提示: 使用 rules add RULE-SET,%s,<策略> 引用该规则集\n"), name)
+	}
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+	return nil
+}
+
+// 删除 rule-provider、引用它的 RULE-SET 规则和生成的规则集文件
+func removeRuleSet(name string) error {
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+	// 修改前的配置，用于显示差异
+	current, _ := readClashConfig()
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+
+	provider, exists := config.RuleProviders[name]
+	record := findRuleSetRecord(settings, name)
+	if !exists && record == nil {
+		return errorOf(ErrRuleProviderNotFound, T("rule-provider %s 不存在"), name)
+	}
+
+	delete(config.RuleProviders, name)
+	var rules []string
+	for _, text := range config.Rules {
+		if rule, err := parseRule(text); err == nil && rule.Type == "RULE-SET" && rule.Payload == name {
+			continue
+		}
+		rules = append(rules, text)
+	}
+	config.Rules = rules
+
+	printConfigChanges(diffClashConfigs(current, config))
+	if err := saveClashConfig(config); err != nil {
+		return fmt.Errorf(T("保存配置失败: %w"), err)
+	}
+
+	// 只删除规则集目录中的文件，手动添加的 provider 可能指向其它位置
+	if exists && filepath.Dir(provider.Path) == clashRulesetDir() {
+		if err := os.Remove(provider.Path); err != nil && !os.IsNotExist(err) {
+			fmt.Printf(T("删除规则集文件失败: %v\n"), err)
+		}
+	}
+
+	if record != nil {
+		var kept []RuleSetRecord
+		for _, r := range settings.RuleSets {
+			if r.Name != name {
+				kept = append(kept, r)
+			}
+		}
+		settings.RuleSets = kept
+		if err := saveManagerSettings(settings); err != nil {
+			return fmt.Errorf(T("保存规则集记录失败: %w"), err)
+		}
+	}
+
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+	return nil
+}
+
+// 刷新指定名称的规则集，不指定名称时刷新全部导入的规则集
+func refreshRuleSets(names []string) error {
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+	if len(settings.RuleSets) == 0 {
+		fmt.Println(T("还没有通过 ruleset add 导入任何规则集"))
+		return nil
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	failed := 0
+	for i := range settings.RuleSets {
+		record := &settings.RuleSets[i]
+		if len(wanted) > 0 && !wanted[record.Name] {
+			continue
+		}
+		delete(wanted, record.Name)
+
+		fmt.Printf(T("\n正在刷新规则集 %s...\n"), record.Name)
+		provider, exists := config.RuleProviders[record.Name]
+		if !exists {
+			fmt.Println(T("配置文件中已没有该 rule-provider，跳过"))
+			continue
+		}
+
+		changed := true
+		if provider.Type != "http" {
+			changed, err = refreshRuleSetFile(record, provider, settings.SubscriptionFetch)
+			if err != nil {
+				fmt.Printf(T("刷新失败: %v\n"), err)
+				failed++
+				continue
+			}
+		}
+		if !changed {
+			fmt.Println(T("内容没有变化"))
+			continue
+		}
+
+		if err := reloadRuleProvider(record.Name); err != nil {
+			fmt.Printf(T("通知 Clash 重新加载失败: %v，将在下次启动时生效\n"), err)
+		} else {
+			fmt.Println(T("Clash 已重新加载该规则集"))
+		}
+	}
+
+	for name := range wanted {
+		fmt.Printf(T("未找到规则集: %s\n"), name)
+		failed++
+	}
+
+	if err := saveManagerSettings(settings); err != nil {
+		return fmt.Errorf(T("保存规则集记录失败: %w"), err)
+	}
+	if failed > 0 {
+		return fmt.Errorf(T("%d 个规则集刷新失败"), failed)
+	}
+	return nil
+}
+
+// 重新下载并转换 file 类型的规则集，返回文件内容是否发生变化
+func refreshRuleSetFile(record *RuleSetRecord, provider RuleProvider, opts SubscriptionFetchOptions) (bool, error) {
+	content, err := readRuleSetSource(record.Source, opts)
+	if err != nil {
+		return false, err
+	}
+	entries, err := parseRuleSetEntries(content, record.Format)
+	if err != nil {
+		return false, err
+	}
+
+	// behavior 以配置中的 provider 为准
+	payload, _ := renderRuleSetPayload(entries, provider.Behavior)
+	if len(payload) == 0 {
+		return false, fmt.Errorf(T("来源中没有可用的条目，保留现有的 %d 条"), record.Count)
+	}
+
+	path := provider.Path
+	if path == "" {
+		path = ruleSetPath(record.Name)
+	}
+	data, err := encodeRuleSetFile(record.Source, record.Format, payload)
+	if err != nil {
+		return false, err
+	}
+	old, _ := os.ReadFile(path)
+	record.UpdatedAt = time.Now()
+	if bytes.Equal(old, data) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf(T("创建规则集目录失败: %w"), err)
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return false, fmt.Errorf(T("写入规则集文件失败: %w"), err)
+	}
+	fmt.Printf(T("条目数量: %d -> %d\n"), record.Count, len(payload))
+	record.Count = len(payload)
+	return true, nil
+}
+
+// 调用 Clash API 重新加载 rule-provider
+func reloadRuleProvider(name string) error {
+	return defaultClashAPIClient().withTimeout(30 * time.Second).reloadRuleProvider(name)
+}
+
+// 来源是否为远程地址
+func isRemoteSource(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// 读取来源内容，远程地址使用订阅的下载选项
+func readRuleSetSource(source string, opts SubscriptionFetchOptions) ([]byte, error) {
+	if isRemoteSource(source) {
+		content, err := fetchSubscription(source, opts)
+		if err != nil {
+			return nil, fmt.Errorf(T("下载 %s 失败: %w"), source, err)
+		}
+		return content, nil
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf(T("读取 %s 失败: %w"), source, err)
+	}
+	return content, nil
+}
+
+// 写入转换后的规则集文件
+func writeRuleSetFile(name, source, format string, payload []string) error {
+	data, err := encodeRuleSetFile(source, format, payload)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(clashRulesetDir(), 0755); err != nil {
+		return fmt.Errorf(T("创建规则集目录失败: %w"), err)
+	}
+	if err := writeFileAtomic(ruleSetPath(name), data, 0644); err != nil {
+		return fmt.Errorf(T("写入规则集文件失败: %w"), err)
+	}
+	return nil
+}
+
+// 编码为 Clash 规则集文件，开头注明来源
+// 注释是固定的英文，不随界面语言变化，否则切换语言后刷新会把内容未变的文件当作已更新
+func encodeRuleSetFile(source, format string, payload []string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Generated by clash-setup from %s (%s); overwritten on refresh\n", source, format)
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(defaultConfigIndent)
+	doc := struct {
+		Payload []string `yaml:"payload"`
+	}{payload}
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// 识别来源格式
+func detectRuleSetFormat(content []byte) string {
+	if _, ok := decodeGFWList(content); ok {
+		return "gfwlist"
+	}
+
+	var doc struct {
+		Payload []string `yaml:"payload"`
+	}
+	if yaml.Unmarshal(content, &doc) == nil && len(doc.Payload) > 0 {
+		return "clash"
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "||") || strings.HasPrefix(line, "[Adblock") ||
+			(strings.HasSuffix(line, "^") && !strings.HasPrefix(line, "#")) {
+			return "adblock"
+		}
+	}
+	return "plain"
+}
+
+// 解码 base64 编码的 gfwlist
+func decodeGFWList(content []byte) (string, bool) {
+	compact := strings.Join(strings.Fields(string(content)), "")
+	if compact == "" {
+		return "", false
+	}
+	decoded, err := decodeBase64UrlSafe(compact)
+	if err != nil {
+		return "", false
+	}
+	text := string(decoded)
+	if !strings.Contains(text, "[AutoProxy") && !strings.Contains(text, "||") {
+		return "", false
+	}
+	return text, true
+}
+
+// 按格式解析来源中的条目，重复条目只保留第一条
+func parseRuleSetEntries(content []byte, format string) ([]ruleSetEntry, error) {
+	var lines []string
+	parse := func(line string) (ruleSetEntry, bool) { return parsePlainRuleSetLine(line, true) }
+
+	switch format {
+	case "plain":
+		lines = strings.Split(string(content), "\n")
+	case "adblock":
+		lines = strings.Split(string(content), "\n")
+		parse = func(line string) (ruleSetEntry, bool) { return parseAdblockRuleSetLine(line, false) }
+	case "gfwlist":
+		text, ok := decodeGFWList(content)
+		if !ok {
+			return nil, errors.New(T("无法解码 gfwlist，内容不是有效的 base64"))
+		}
+		lines = strings.Split(text, "\n")
+		parse = func(line string) (ruleSetEntry, bool) { return parseAdblockRuleSetLine(line, true) }
+	case "clash":
+		var doc struct {
+			Payload []string `yaml:"payload"`
+		}
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return nil, fmt.Errorf(T("解析 clash 规则集失败: %w"), err)
+		}
+		lines = doc.Payload
+		// clash 规则集中的裸域名表示完整域名
+		parse = func(line string) (ruleSetEntry, bool) { return parsePlainRuleSetLine(line, false) }
+	default:
+		return nil, fmt.Errorf(T("不支持的格式: %s"), format)
+	}
+
+	var entries []ruleSetEntry
+	seen := make(map[ruleSetEntry]bool)
+	for _, line := range lines {
+		entry, ok := parse(strings.TrimSpace(line))
+		if !ok || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// 解析纯文本列表中的一行，支持域名、IP/CIDR、hosts 文件格式和完整规则
+// bareAsSuffix 为 true 时裸域名按后缀匹配处理
+func parsePlainRuleSetLine(line string, bareAsSuffix bool) (ruleSetEntry, bool) {
+	if i := strings.Index(line, "#"); i >= 0 {
+		line = strings.TrimSpace(line[:i])
+	}
+	if line == "" || strings.HasPrefix(line, "//") || strings.HasPrefix(line, "!") {
+		return ruleSetEntry{}, false
+	}
+
+	// 完整规则，例如 DOMAIN-SUFFIX,example.com 或 IP-CIDR,10.0.0.0/8,no-resolve
+	if strings.Contains(line, ",") {
+		parts := strings.Split(line, ",")
+		ruleType := strings.ToUpper(strings.TrimSpace(parts[0]))
+		checker, known := rulePayloadCheckers[ruleType]
+		if !known || checker == nil || ruleType == "RULE-SET" {
+			return ruleSetEntry{}, false
+		}
+		payload := strings.TrimSpace(parts[1])
+		if checker(payload, nil) != nil {
+			return ruleSetEntry{}, false
+		}
+		parts[0], parts[1] = ruleType, payload
+		return ruleSetEntry{ruleEntryClassical, strings.Join(parts, ",")}, true
+	}
+
+	// hosts 文件格式: 0.0.0.0 example.com
+	if fields := strings.Fields(line); len(fields) == 2 {
+		if _, err := netip.ParseAddr(fields[0]); err == nil {
+			line = fields[1]
+		}
+	}
+
+	if entry, ok := parseCIDREntry(line); ok {
+		return entry, true
+	}
+
+	kind := ruleEntryDomain
+	if bareAsSuffix {
+		kind = ruleEntrySuffix
+	}
+	switch {
+	case strings.HasPrefix(line, "full:"):
+		kind, line = ruleEntryDomain, line[len("full:"):]
+	case strings.HasPrefix(line, "domain:"):
+		kind, line = ruleEntrySuffix, line[len("domain:"):]
+	case strings.HasPrefix(line, "+."):
+		kind, line = ruleEntrySuffix, line[2:]
+	case strings.HasPrefix(line, "."), strings.HasPrefix(line, "*."):
+		if !isRuleSetDomain(strings.TrimLeft(line, "*.")) {
+			return ruleSetEntry{}, false
+		}
+		return ruleSetEntry{ruleEntryPattern, strings.ToLower(line)}, true
+	}
+
+	line = strings.ToLower(line)
+	if !isRuleSetDomain(line) || line == "localhost" {
+		return ruleSetEntry{}, false
+	}
+	return ruleSetEntry{kind, line}, true
+}
+
+// 解析 Adblock 或 gfwlist 中的一行，只保留按域名拦截的条目
+// 例外规则 (@@)、正则表达式、元素隐藏规则和带限定选项的规则都会被跳过
+func parseAdblockRuleSetLine(line string, gfwlist bool) (ruleSetEntry, bool) {
+	if line == "" || strings.HasPrefix(line, "!") || strings.HasPrefix(line, "[") ||
+		strings.HasPrefix(line, "@@") || strings.Contains(line, "##") || strings.Contains(line, "#@#") ||
+		(strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/")) {
+		return ruleSetEntry{}, false
+	}
+
+	// 选项只接受不改变拦截范围的 important
+	if i := strings.Index(line, "$"); i >= 0 {
+		if opts := line[i+1:]; opts != "" && opts != "important" {
+			return ruleSetEntry{}, false
+		}
+		line = line[:i]
+	}
+
+	switch {
+	case strings.HasPrefix(line, "||"):
+		line = line[2:]
+	case strings.HasPrefix(line, "|"):
+		line = line[1:]
+	case strings.HasPrefix(line, "."):
+		line = line[1:]
+	case !gfwlist:
+		// 普通 Adblock 列表中也常混有纯域名或 hosts 格式的行
+		if strings.HasPrefix(line, "#") {
+			return ruleSetEntry{}, false
+		}
+		return parsePlainRuleSetLine(line, true)
+	}
+
+	// 只保留地址中的主机名部分
+	if i := strings.Index(line, "://"); i >= 0 {
+		line = line[i+3:]
+	}
+	if i := strings.IndexAny(line, "^/|"); i >= 0 {
+		line = line[:i]
+	}
+	if strings.Contains(line, "*") {
+		return ruleSetEntry{}, false
+	}
+	line = stripHostPort(line)
+
+	if entry, ok := parseCIDREntry(line); ok {
+		return entry, true
+	}
+	line = strings.ToLower(line)
+	if !isRuleSetDomain(line) {
+		return ruleSetEntry{}, false
+	}
+	return ruleSetEntry{ruleEntrySuffix, line}, true
+}
+
+// 去掉主机名后的端口，IPv6 地址和没有端口时原样返回
+func stripHostPort(s string) string {
+	i := strings.LastIndex(s, ":")
+	if i < 0 || strings.Contains(s[:i], ":") {
+		return s
+	}
+	if _, err := strconv.Atoi(s[i+1:]); err != nil {
+		return s
+	}
+	return s[:i]
+}
+
+// 将 IP 或 CIDR 解析为 ipcidr 条目，单个 IP 补全为 /32 或 /128
+func parseCIDREntry(s string) (ruleSetEntry, bool) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return ruleSetEntry{ruleEntryCIDR, prefix.Masked().String()}, true
+	}
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return ruleSetEntry{ruleEntryCIDR, netip.PrefixFrom(addr, addr.BitLen()).String()}, true
+	}
+	return ruleSetEntry{}, false
+}
+
+// 是否为有效的域名，至少包含一个点
+func isRuleSetDomain(s string) bool {
+	if s == "" || len(s) > 253 || !strings.Contains(s, ".") {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// 根据条目推断规则集类型：只有 IP 时为 ipcidr，混合或包含完整规则时为 classical
+func inferRuleSetBehavior(entries []ruleSetEntry) string {
+	hasDomain, hasCIDR := false, false
+	for _, entry := range entries {
+		switch entry.kind {
+		case ruleEntryClassical:
+			return "classical"
+		case ruleEntryCIDR:
+			hasCIDR = true
+		default:
+			hasDomain = true
+		}
+	}
+	switch {
+	case hasDomain && hasCIDR:
+		return "classical"
+	case hasCIDR:
+		return "ipcidr"
+	default:
+		return "domain"
+	}
+}
+
+// 按规则集类型生成 payload，返回无法表示的条目数量
+func renderRuleSetPayload(entries []ruleSetEntry, behavior string) ([]string, int) {
+	var payload []string
+	skipped := 0
+	for _, entry := range entries {
+		item := ""
+		switch behavior {
+		case "domain":
+			switch entry.kind {
+			case ruleEntryDomain, ruleEntryPattern:
+				item = entry.value
+			case ruleEntrySuffix:
+				item = "+." + entry.value
+			}
+		case "ipcidr":
+			if entry.kind == ruleEntryCIDR {
+				item = entry.value
+			}
+		case "classical":
+			switch entry.kind {
+			case ruleEntryDomain:
+				item = "DOMAIN," + entry.value
+			case ruleEntrySuffix:
+				item = "DOMAIN-SUFFIX," + entry.value
+			case ruleEntryCIDR:
+				ruleType := "IP-CIDR"
+				if strings.Contains(entry.value, ":") {
+					ruleType = "IP-CIDR6"
+				}
+				item = ruleType + "," + entry.value + ",no-resolve"
+			case ruleEntryClassical:
+				item = entry.value
+			}
+		}
+
+		if item == "" {
+			skipped++
+			continue
+		}
+		payload = append(payload, item)
+	}
+	return payload, skipped
+}
+
+// 处理 ruleset timer 子命令
+func manageRuleSetTimer(args []string) error {
+	action := "status"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "enable":
+		schedule := "daily"
+		if len(args) > 1 {
+			schedule = strings.Join(args[1:], " ")
+		}
+		return enableRuleSetTimer(schedule)
+	case "disable":
+		return disableRuleSetTimer()
+	case "status":
+		return showRuleSetTimer()
+	default:
+		return fmt.Errorf(T("未知的 timer 操作: %s (可选 enable、disable、status)"), action)
+	}
+}
+
+// systemd 单元文件路径
+func ruleSetUnitPath(suffix string) string {
+	return filepath.Join("/etc/systemd/system", ruleSetRefreshUnit+suffix)
+}
+
+// 按 systemd 单元文件的规则给 ExecStart 的参数加引号，路径中的空格、% 和 $ 不会被解释
+func systemdQuote(arg string) string {
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(arg)
+	return `"` + arg + `"`
+}
+
+// 用 systemd-analyze 检查 OnCalendar 表达式，无效时不写入单元文件
+func checkCalendarSpec(schedule string) error {
+	if strings.ContainsAny(schedule, "\r\n") {
+		return fmt.Errorf(T("无效的执行计划: %q"), schedule)
+	}
+	output, err := exec.Command("systemd-analyze", "calendar", schedule).CombinedOutput()
+	if err != nil {
+		detail := strings.TrimSpace(string(output))
+		if detail == "" {
+			detail = err.Error()
+		}
+		return fmt.Errorf(T("无效的执行计划 %s: %s"), schedule, detail)
+	}
+	return nil
+}
+
+// 写入 systemd service 和 timer 并启用定时刷新
+func enableRuleSetTimer(schedule string) error {
+	if err := checkCalendarSpec(schedule); err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf(T("获取程序路径失败: %w"), err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	// 使用当前的工作目录和配置文件，与手动执行 ruleset refresh 的效果一致
+	serviceContent := fmt.Sprintf(`[Unit]
+Description=Refresh Clash rule sets
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=%s --workdir %s --config %s ruleset refresh
+`, systemdQuote(executable), systemdQuote(clashWorkDir()), systemdQuote(clashConfigPath()))
+
+	timerContent := fmt.Sprintf(`[Unit]
+Description=Refresh Clash rule sets periodically
+
+[Timer]
+OnCalendar=%s
+RandomizedDelaySec=10min
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, schedule)
+
+	if err := os.WriteFile(ruleSetUnitPath(".service"), []byte(serviceContent), 0644); err != nil {
+		return fmt.Errorf(T("写入 systemd 服务文件失败: %w"), err)
+	}
+	if err := os.WriteFile(ruleSetUnitPath(".timer"), []byte(timerContent), 0644); err != nil {
+		return fmt.Errorf(T("写入 systemd 定时器文件失败: %w"), err)
+	}
+
+	if output, err := exec.Command("systemctl", "daemon-reload").CombinedOutput(); err != nil {
+		return fmt.Errorf(T("重新加载 systemd 配置失败: %w, %s"), err, strings.TrimSpace(string(output)))
+	}
+	if output, err := exec.Command("systemctl", "enable", "--now", ruleSetRefreshUnit+".timer").CombinedOutput(); err != nil {
+		return fmt.Errorf(T("启用定时器失败: %w, %s"), err, strings.TrimSpace(string(output)))
+	}
+
+	fmt.Printf(T("已启用定时刷新 (%s)，执行计划: %s\n"), ruleSetRefreshUnit+".timer", schedule)
+	return nil
+}
+
+// 停用定时刷新并删除单元文件
+func disableRuleSetTimer() error {
+	if _, err := os.Stat(ruleSetUnitPath(".timer")); os.IsNotExist(err) {
+		fmt.Println(T("定时刷新未启用"))
+		return nil
+	}
+
+	if output, err := exec.Command("systemctl", "disable", "--now", ruleSetRefreshUnit+".timer").CombinedOutput(); err != nil {
+		fmt.Printf(T("停用定时器失败: %v, %s\n"), err, strings.TrimSpace(string(output)))
+	}
+	for _, suffix := range []string{".timer", ".service"} {
+		if err := os.Remove(ruleSetUnitPath(suffix)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf(T("删除 %s 失败: %w"), ruleSetUnitPath(suffix), err)
+		}
+	}
+	if output, err := exec.Command("systemctl", "daemon-reload").CombinedOutput(); err != nil {
+		return fmt.Errorf(T("重新加载 systemd 配置失败: %w, %s"), err, strings.TrimSpace(string(output)))
+	}
+
+	fmt.Println(T("已停用定时刷新"))
+	return nil
+}
+
+// 显示定时刷新的状态和各规则集的更新时间
+func showRuleSetTimer() error {
+	if _, err := os.Stat(ruleSetUnitPath(".timer")); os.IsNotExist(err) {
+		fmt.Println(T("定时刷新: 未启用"))
+	} else {
+		output, _ := exec.Command("systemctl", "list-timers", ruleSetRefreshUnit+".timer", "--all", "--no-pager").CombinedOutput()
+		fmt.Println(T("定时刷新: 已启用"))
+		fmt.Println(strings.TrimSpace(string(output)))
+	}
+
+	settings, err := loadManagerSettings()
+	if err != nil {
+		return err
+	}
+	records := append([]RuleSetRecord(nil), settings.RuleSets...)
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	for _, record := range records {
+		fmt.Printf(T("   %s: %d 条，上次更新 %s\n"), record.Name, record.Count, record.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
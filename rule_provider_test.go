@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeRuleSetFileIndependentOfLanguage(t *testing.T) {
+	defer func(lang string) { activeLang = lang }(activeLang)
+
+	payload := []string{"DOMAIN-SUFFIX,example.com", "IP-CIDR,10.0.0.0/8"}
+	var outputs [][]byte
+	for _, lang := range []string{langZH, langEN} {
+		activeLang = lang
+		data, err := encodeRuleSetFile("https://example.com/list.txt", "domain", payload)
+		if err != nil {
+			t.Fatalf("encodeRuleSetFile(%s) 出错: %v", lang, err)
+		}
+		outputs = append(outputs, data)
+	}
+	if !bytes.Equal(outputs[0], outputs[1]) {
+		t.Errorf("规则集文件随界面语言变化:\n%s\n%s", outputs[0], outputs[1])
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"/usr/local/bin/clash-setup", `"/usr/local/bin/clash-setup"`},
+		{"/srv/my clash/config.yaml", `"/srv/my clash/config.yaml"`},
+		{"/srv/100%/$HOME", `"/srv/100%%/$$HOME"`},
+		{`/srv/a"b\c`, `"/srv/a\"b\\c"`},
+	}
+	for _, tt := range tests {
+		if got := systemdQuote(tt.arg); got != tt.want {
+			t.Errorf("systemdQuote(%q) = %s，期望 %s", tt.arg, got, tt.want)
+		}
+	}
+}
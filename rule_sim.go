@@ -0,0 +1,552 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 在本地按顺序模拟规则匹配的过程
+type ruleSimulator struct {
+	config *ClashConfig
+	// 请求的域名，直接测试 IP 时为空
+	host string
+	// 请求的 IP，测试域名时在第一次需要时解析
+	ip   netip.Addr
+	port int
+
+	resolver   *clashDNSResolver
+	mmdb       *mmdbReader
+	mmdbErr    error
+	resolved   bool
+	resolveErr error
+	// 解析结果的说明
+	resolveInfo string
+	// 无法在本地判断而跳过的规则
+	skipped []string
+	// 已读取的 rule-provider 内容
+	providers map[string]*ruleSetPayload
+}
+
+// rule-provider 的内容
+type ruleSetPayload struct {
+	behavior string
+	entries  []string
+}
+
+// 规则匹配结果
+type ruleMatch struct {
+	// 规则序号，从 1 开始
+	Index int
+	Text  string
+	Rule  Rule
+}
+
+// 创建模拟器，target 可以是域名、IP 或 URL
+func newRuleSimulator(config *ClashConfig, target string, port int) (*ruleSimulator, error) {
+	host, urlPort, err := parseRuleTestTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if port == 0 {
+		port = urlPort
+	}
+
+	sim := &ruleSimulator{
+		config:    config,
+		port:      port,
+		providers: make(map[string]*ruleSetPayload),
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		sim.ip = ip.Unmap()
+	} else {
+		sim.host = strings.ToLower(strings.TrimSuffix(host, "."))
+	}
+
+	sim.mmdb, _, sim.mmdbErr = openCountryMMDB()
+	sim.resolver = newClashDNSResolver(config, sim.mmdb)
+	return sim, nil
+}
+
+// 从命令行参数中取出主机名和端口，URL 的端口由协议推断
+func parseRuleTestTarget(target string) (string, int, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", 0, errors.New(T("请指定要测试的域名或 IP"))
+	}
+
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil || u.Hostname() == "" {
+			return "", 0, fmt.Errorf(T("无效的 URL: %s"), target)
+		}
+		port, _ := strconv.Atoi(u.Port())
+		if port == 0 {
+			switch u.Scheme {
+			case "http", "ws":
+				port = 80
+			case "https", "wss":
+				port = 443
+			}
+		}
+		return u.Hostname(), port, nil
+	}
+
+	if host, portStr, err := net.SplitHostPort(target); err == nil {
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return "", 0, fmt.Errorf(T("无效的端口: %s"), portStr)
+		}
+		return host, port, nil
+	}
+	return strings.Trim(target, "[]"), 0, nil
+}
+
+// 按顺序匹配规则，返回第一条命中的规则，没有命中时返回 nil
+func (s *ruleSimulator) run() *ruleMatch {
+	for i, text := range s.config.Rules {
+		rule, err := parseRule(text)
+		if err != nil {
+			continue
+		}
+		if s.matches(rule, text) {
+			return &ruleMatch{Index: i + 1, Text: text, Rule: rule}
+		}
+	}
+	return nil
+}
+
+// 判断单条规则是否命中
+func (s *ruleSimulator) matches(rule Rule, text string) bool {
+	noResolve := false
+	for _, option := range rule.Options {
+		if strings.EqualFold(option, "no-resolve") {
+			noResolve = true
+		}
+	}
+
+	switch rule.Type {
+	case "MATCH", "FINAL":
+		return true
+	case "DOMAIN":
+		return s.host != "" && s.host == strings.ToLower(rule.Payload)
+	case "DOMAIN-SUFFIX":
+		suffix := strings.ToLower(rule.Payload)
+		return s.host != "" && (s.host == suffix || strings.HasSuffix(s.host, "."+suffix))
+	case "DOMAIN-KEYWORD":
+		return s.host != "" && strings.Contains(s.host, strings.ToLower(rule.Payload))
+	case "IP-CIDR", "IP-CIDR6":
+		prefix, err := netip.ParsePrefix(rule.Payload)
+		if err != nil {
+			return false
+		}
+		ip, ok := s.targetIP(noResolve)
+		return ok && prefix.Contains(ip)
+	case "GEOIP":
+		ip, ok := s.targetIP(noResolve)
+		if !ok {
+			return false
+		}
+		if strings.EqualFold(rule.Payload, "LAN") {
+			return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast()
+		}
+		if s.mmdb == nil {
+			s.skip(text, fmt.Sprintf(T("无法读取 Country.mmdb: %v"), s.mmdbErr))
+			return false
+		}
+		country, err := s.mmdb.country(ip)
+		return err == nil && strings.EqualFold(country, rule.Payload)
+	case "DST-PORT":
+		if s.port == 0 {
+			s.skip(text, T("未指定端口，可使用 --port 指定"))
+			return false
+		}
+		return portInRange(s.port, rule.Payload)
+	case "DOMAIN-REGEX":
+		pattern, err := regexp.Compile(rule.Payload)
+		return err == nil && s.host != "" && pattern.MatchString(s.host)
+	case "RULE-SET":
+		return s.matchRuleSet(rule, text, noResolve)
+	case "AND", "OR", "NOT":
+		return s.matchLogical(rule, text)
+	case "GEOSITE":
+		s.skip(text, T("需要 GeoSite 数据库，无法在本地判断"))
+		return false
+	case "IP-ASN":
+		s.skip(text, T("需要 ASN 数据库，无法在本地判断"))
+		return false
+	case "NETWORK":
+		s.skip(text, T("取决于连接使用 TCP 还是 UDP，无法在本地判断"))
+		return false
+	default:
+		if connectionRuleTypes[rule.Type] {
+			s.skip(text, T("依赖连接来源，无法在本地判断"))
+		} else {
+			s.skip(text, fmt.Sprintf(T("不支持模拟 %s 规则"), rule.Type))
+		}
+		return false
+	}
+}
+
+// 依赖连接来源的规则类型，本地模拟时没有这些信息
+var connectionRuleTypes = map[string]bool{
+	"PROCESS-NAME": true,
+	"PROCESS-PATH": true,
+	"SRC-IP-CIDR":  true,
+	"SRC-GEOIP":    true,
+	"SRC-PORT":     true,
+	"IN-PORT":      true,
+	"IN-TYPE":      true,
+	"IN-USER":      true,
+	"IN-NAME":      true,
+	"UID":          true,
+}
+
+// 匹配 AND/OR/NOT 规则，递归判断子规则
+// 有子规则无法判断时，只有在结果已经确定的情况下才给出结论，否则跳过整条规则
+func (s *ruleSimulator) matchLogical(rule Rule, text string) bool {
+	subRules, err := splitLogicalPayload(rule.Payload)
+	if err != nil || len(subRules) == 0 {
+		return false
+	}
+
+	// 子规则的跳过记录只用于说明原因，不单独列出
+	var matched, unmatched, unknown []string
+	for _, sub := range subRules {
+		before := len(s.skipped)
+		ok := s.matches(parseSubRule(sub), sub)
+		switch {
+		case len(s.skipped) > before:
+			unknown = append(unknown, s.skipped[before:]...)
+		case ok:
+			matched = append(matched, sub)
+		default:
+			unmatched = append(unmatched, sub)
+		}
+		s.skipped = s.skipped[:before]
+	}
+
+	var result bool
+	switch rule.Type {
+	case "AND":
+		if len(unmatched) > 0 {
+			return false
+		}
+		result = true
+	case "OR":
+		if len(matched) > 0 {
+			return true
+		}
+		result = false
+	case "NOT":
+		result = len(unmatched) > 0
+	}
+	if len(unknown) > 0 {
+		s.skip(text, fmt.Sprintf(T("无法判断子规则 %s"), strings.Join(unknown, "; ")))
+		return false
+	}
+	return result
+}
+
+// 记录被跳过的规则
+func (s *ruleSimulator) skip(text, reason string) {
+	s.skipped = append(s.skipped, fmt.Sprintf("%s (%s)", text, reason))
+}
+
+// 返回用于 IP 规则的地址，域名在第一次需要时按配置的 DNS 解析
+// 规则带有 no-resolve 时不解析域名
+func (s *ruleSimulator) targetIP(noResolve bool) (netip.Addr, bool) {
+	if s.host == "" {
+		return s.ip, true
+	}
+	if noResolve {
+		return netip.Addr{}, false
+	}
+
+	if !s.resolved {
+		s.resolved = true
+		addrs, source, err := s.resolver.resolve(s.host)
+		if err != nil {
+			s.resolveErr = err
+		} else {
+			s.ip = addrs[0]
+			s.resolveInfo = source
+		}
+	}
+	return s.ip, s.resolveErr == nil && s.ip.IsValid()
+}
+
+// 端口是否在 80 或 8000-9000 形式的范围内
+func portInRange(port int, payload string) bool {
+	low, high, isRange := strings.Cut(payload, "-")
+	first, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return false
+	}
+	last := first
+	if isRange {
+		if last, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+			return false
+		}
+	}
+	return port >= first && port <= last
+}
+
+// 匹配 RULE-SET 规则，读取 rule-provider 的本地缓存文件
+func (s *ruleSimulator) matchRuleSet(rule Rule, text string, noResolve bool) bool {
+	payload, err := s.loadRuleSet(rule.Payload)
+	if err != nil {
+		s.skip(text, err.Error())
+		return false
+	}
+
+	switch payload.behavior {
+	case "domain":
+		if s.host == "" {
+			return false
+		}
+		for _, entry := range payload.entries {
+			if matchDomainPattern(entry, s.host) {
+				return true
+			}
+		}
+	case "ipcidr":
+		ip, ok := s.targetIP(noResolve)
+		if !ok {
+			return false
+		}
+		for _, entry := range payload.entries {
+			if prefix, err := netip.ParsePrefix(entry); err == nil && prefix.Contains(ip) {
+				return true
+			}
+		}
+	default:
+		// classical: 每一项都是不带策略的规则
+		for _, entry := range payload.entries {
+			parts := strings.Split(entry, ",")
+			item := Rule{Type: strings.ToUpper(strings.TrimSpace(parts[0]))}
+			if len(parts) > 1 {
+				item.Payload = strings.TrimSpace(parts[1])
+			}
+			for _, option := range parts[min(len(parts), 2):] {
+				item.Options = append(item.Options, strings.TrimSpace(option))
+			}
+			if !isMatchType(item.Type) && s.matches(item, text+" -> "+entry) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 读取 rule-provider 的内容，支持 YAML payload 和每行一项的纯文本格式
+func (s *ruleSimulator) loadRuleSet(name string) (*ruleSetPayload, error) {
+	if payload, ok := s.providers[name]; ok {
+		return payload, nil
+	}
+
+	provider, ok := s.config.RuleProviders[name]
+	if !ok {
+		return nil, errorOf(ErrRuleProviderNotFound, T("rule-provider %s 不存在"), name)
+	}
+	path := provider.Path
+	if path == "" {
+		return nil, fmt.Errorf(T("rule-provider %s 没有本地文件"), name)
+	}
+	// 相对路径以 Clash 工作目录为基准
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(clashWorkDir(), path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(T("读取 rule-provider %s 失败: %w"), name, err)
+	}
+
+	payload := &ruleSetPayload{behavior: strings.ToLower(provider.Behavior)}
+
+	var doc struct {
+		Payload []string `yaml:"payload"`
+	}
+	if err := yaml.Unmarshal(content, &doc); err == nil && len(doc.Payload) > 0 {
+		payload.entries = doc.Payload
+	} else {
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				payload.entries = append(payload.entries, line)
+			}
+		}
+	}
+
+	s.providers[name] = payload
+	return payload, nil
+}
+
+// 匹配 domain 类型 rule-set 中的条目
+// +.example.com 匹配自身及所有子域名，.example.com 只匹配子域名，*.example.com 只匹配一级子域名
+func matchDomainPattern(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	switch {
+	case strings.HasPrefix(pattern, "+."):
+		base := pattern[2:]
+		return host == base || strings.HasSuffix(host, "."+base)
+	case strings.HasPrefix(pattern, "."):
+		return strings.HasSuffix(host, pattern)
+	case strings.HasPrefix(pattern, "*."):
+		base := pattern[1:]
+		return strings.HasSuffix(host, base) && !strings.Contains(strings.TrimSuffix(host, base), ".")
+	default:
+		return host == pattern
+	}
+}
+
+// 策略最终使用的节点，依次经过的代理组和节点
+type policyResolution struct {
+	Chain []string
+	// 结果来源：Clash API 或配置文件，没有经过代理组时为空
+	Source string
+	// 无法确定具体节点时的说明
+	Note string
+}
+
+// 确定策略当前使用的节点，优先查询 Clash API，不可用时根据配置推断
+func resolvePolicy(config *ClashConfig, policy string) policyResolution {
+	result := policyResolution{Chain: []string{policy}}
+
+	current := policy
+	for depth := 0; depth < 10; depth++ {
+		group := config.FindGroup(current)
+		if group == nil {
+			return result
+		}
+
+		// 有任意一步根据配置推断时，结果来源为配置文件
+		next, err := fetchGroupNow(current)
+		if err == nil && next != "" && result.Source == "" {
+			result.Source = "Clash API"
+		}
+		if err != nil || next == "" {
+			result.Source = T("配置文件")
+			next = ""
+			switch {
+			case group.Type == "select" && group.Selected != "":
+				next = group.Selected
+			case group.Type == "select" && len(group.Proxies) > 0:
+				next = group.Proxies[0]
+			default:
+				result.Note = fmt.Sprintf(T("代理组 %s (%s) 由 Clash 根据测速或负载均衡选择节点，Clash 未运行时无法确定"), group.Name, group.Type)
+				return result
+			}
+		}
+
+		result.Chain = append(result.Chain, next)
+		current = next
+	}
+	return result
+}
+
+// 通过 Clash API 查询代理组当前选中的节点
+func fetchGroupNow(name string) (string, error) {
+	group, err := defaultClashAPIClient().withTimeout(2 * time.Second).proxy(name)
+	if err != nil {
+		return "", err
+	}
+	return group.Now, nil
+}
+
+// 处理 rules test 命令
+func testRules(args []string) error {
+	var target string
+	port := 0
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--port", "-p":
+			if i+1 >= len(args) {
+				return errors.New(T("--port 需要指定端口"))
+			}
+			i++
+			p, err := strconv.Atoi(args[i])
+			if err != nil || p < 1 || p > 65535 {
+				return fmt.Errorf(T("无效的端口: %s"), args[i])
+			}
+			port = p
+		default:
+			if target != "" {
+				return errors.New(T("只能指定一个域名或 IP"))
+			}
+			target = args[i]
+		}
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		return fmt.Errorf(T("读取配置文件失败: %w"), err)
+	}
+
+	sim, err := newRuleSimulator(config, target, port)
+	if err != nil {
+		return err
+	}
+	match := sim.run()
+
+	if sim.host != "" {
+		fmt.Printf(T("目标: %s"), sim.host)
+	} else {
+		fmt.Printf(T("目标: %s"), sim.ip)
+	}
+	if sim.port != 0 {
+		fmt.Printf(T(" (端口 %d)"), sim.port)
+	}
+	fmt.Println()
+
+	if sim.resolved {
+		if sim.resolveErr != nil {
+			fmt.Printf(T("DNS 解析失败: %v\n"), sim.resolveErr)
+		} else {
+			fmt.Printf(T("DNS 解析: %s (来自 %s)\n"), sim.ip, sim.resolveInfo)
+		}
+	}
+	if sim.ip.IsValid() && sim.mmdb != nil {
+		if country, err := sim.mmdb.country(sim.ip); err == nil && country != "" {
+			fmt.Printf(T("IP 归属: %s\n"), country)
+		}
+	}
+
+	if len(sim.skipped) > 0 {
+		fmt.Println(T("\n以下规则无法在本地判断，已跳过:"))
+		for _, skipped := range sim.skipped {
+			fmt.Printf("  - %s\n", skipped)
+		}
+	}
+
+	if match == nil {
+		fmt.Println(T("\n没有命中任何规则，Clash 将直接连接 (DIRECT)"))
+		return nil
+	}
+
+	fmt.Printf(T("\n命中规则: 第 %d 条 %s\n"), match.Index, match.Text)
+	fmt.Printf(T("策略: %s\n"), match.Rule.Target)
+
+	if builtinPolicies[match.Rule.Target] {
+		return nil
+	}
+	resolution := resolvePolicy(config, match.Rule.Target)
+	if len(resolution.Chain) > 1 {
+		fmt.Printf(T("当前节点: %s (来自%s)\n"), strings.Join(resolution.Chain, " -> "), resolution.Source)
+	}
+	if resolution.Note != "" {
+		fmt.Println(resolution.Note)
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRuleTestTarget(t *testing.T) {
+	tests := []struct {
+		target   string
+		wantHost string
+		wantPort int
+		wantErr  bool
+	}{
+		{target: "example.com", wantHost: "example.com"},
+		{target: "example.com:8443", wantHost: "example.com", wantPort: 8443},
+		{target: "https://example.com/path", wantHost: "example.com", wantPort: 443},
+		{target: "http://example.com:8080", wantHost: "example.com", wantPort: 8080},
+		{target: "[2001:db8::1]:53", wantHost: "2001:db8::1", wantPort: 53},
+		{target: "2001:db8::1", wantHost: "2001:db8::1"},
+		{target: "example.com:http", wantErr: true},
+		{target: " ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			host, port, err := parseRuleTestTarget(tt.target)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望出错，得到 %s:%d", host, port)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRuleTestTarget: %v", err)
+			}
+			if host != tt.wantHost || port != tt.wantPort {
+				t.Errorf("得到 %s:%d，期望 %s:%d", host, port, tt.wantHost, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestRuleSimulatorRun(t *testing.T) {
+	dir := useTempWorkDir(t)
+	files := map[string]string{
+		"ads.yaml":     "payload:\n  - '+.ads.com'\n  - '.tracker.net'\n  - '*.cdn.io'\n",
+		"lan.txt":      "# 局域网\n192.168.0.0/16\n",
+		"classic.yaml": "payload:\n  - DOMAIN-SUFFIX,example.org\n  - DST-PORT,22\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := parseClashConfig([]byte(`
+rule-providers:
+  ads: {type: file, behavior: domain, path: ./ads.yaml}
+  lan: {type: file, behavior: ipcidr, path: ./lan.txt}
+  classic: {type: file, behavior: classical, path: ./classic.yaml}
+rules:
+  - DOMAIN,exact.com,A
+  - DOMAIN-SUFFIX,google.com,B
+  - DOMAIN-KEYWORD,facebook,C
+  - PROCESS-NAME,curl,D
+  - RULE-SET,ads,REJECT
+  - RULE-SET,lan,DIRECT,no-resolve
+  - RULE-SET,classic,E
+  - IP-CIDR,10.0.0.0/8,F,no-resolve
+  - GEOIP,LAN,DIRECT,no-resolve
+  - DST-PORT,8000-9000,G
+  - MATCH,H
+`))
+	if err != nil {
+		t.Fatalf("parseClashConfig: %v", err)
+	}
+
+	tests := []struct {
+		target string
+		port   int
+		// 命中的规则序号，从 1 开始
+		wantIndex int
+		// 无法在本地判断而跳过的规则数
+		wantSkipped int
+	}{
+		{target: "exact.com", wantIndex: 1},
+		{target: "Mail.Google.com.", wantIndex: 2},
+		{target: "https://www.facebook.com/x", wantIndex: 3},
+		{target: "x.ads.com", wantIndex: 5, wantSkipped: 1},
+		{target: "a.cdn.io", wantIndex: 5, wantSkipped: 1},
+		{target: "192.168.1.1", wantIndex: 6, wantSkipped: 1},
+		{target: "www.example.org", wantIndex: 7, wantSkipped: 1},
+		{target: "1.2.3.4:22", wantIndex: 7, wantSkipped: 1},
+		{target: "10.1.2.3", wantIndex: 8, wantSkipped: 2},
+		{target: "127.0.0.1", wantIndex: 9, wantSkipped: 2},
+		{target: "notgoogle.com", port: 8080, wantIndex: 10, wantSkipped: 1},
+		// 没有端口时 DST-PORT 规则被跳过，最后由 MATCH 兜底
+		{target: "a.b.cdn.io", wantIndex: 11, wantSkipped: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			sim, err := newRuleSimulator(config, tt.target, tt.port)
+			if err != nil {
+				t.Fatalf("newRuleSimulator: %v", err)
+			}
+			match := sim.run()
+			if match == nil {
+				t.Fatalf("没有命中任何规则，期望第 %d 条", tt.wantIndex)
+			}
+			if match.Index != tt.wantIndex {
+				t.Errorf("命中第 %d 条 %s，期望第 %d 条", match.Index, match.Text, tt.wantIndex)
+			}
+			if len(sim.skipped) != tt.wantSkipped {
+				t.Errorf("跳过 %d 条规则，期望 %d 条: %s", len(sim.skipped), tt.wantSkipped, strings.Join(sim.skipped, "; "))
+			}
+		})
+	}
+}
+
+func TestMatchDomainPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"+.example.com", "example.com", true},
+		{"+.example.com", "a.b.example.com", true},
+		{"+.example.com", "badexample.com", false},
+		{".example.com", "example.com", false},
+		{".example.com", "a.example.com", true},
+		{"*.example.com", "a.example.com", true},
+		{"*.example.com", "a.b.example.com", false},
+		{"Example.com", "example.com", true},
+		{"example.com", "a.example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchDomainPattern(tt.pattern, tt.host); got != tt.want {
+			t.Errorf("matchDomainPattern(%q, %q) = %v，期望 %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestPortInRange(t *testing.T) {
+	tests := []struct {
+		port    int
+		payload string
+		want    bool
+	}{
+		{80, "80", true},
+		{81, "80", false},
+		{8000, "8000-9000", true},
+		{9000, "8000-9000", true},
+		{9001, "8000-9000", false},
+		{80, "abc", false},
+	}
+
+	for _, tt := range tests {
+		if got := portInRange(tt.port, tt.payload); got != tt.want {
+			t.Errorf("portInRange(%d, %q) = %v，期望 %v", tt.port, tt.payload, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePolicyFromConfig(t *testing.T) {
+	dir := useTempWorkDir(t)
+	// 控制接口指向没有监听的端口，只能根据配置推断
+	content := `
+external-controller: 127.0.0.1:1
+proxies:
+  - {name: a, type: http, server: 1.1.1.1, port: 80}
+  - {name: b, type: http, server: 1.1.1.2, port: 80}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [Auto, a], selected: Sub}
+  - {name: Sub, type: select, proxies: [a, b]}
+  - {name: Auto, type: url-test, proxies: [a, b], url: 'http://example.com', interval: 300}
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := parseClashConfig([]byte(content))
+	if err != nil {
+		t.Fatalf("parseClashConfig: %v", err)
+	}
+
+	tests := []struct {
+		policy     string
+		wantChain  []string
+		wantSource string
+		wantNote   bool
+	}{
+		{policy: "DIRECT", wantChain: []string{"DIRECT"}},
+		{policy: "a", wantChain: []string{"a"}},
+		{policy: "Proxy", wantChain: []string{"Proxy", "Sub", "a"}, wantSource: T("配置文件")},
+		{policy: "Auto", wantChain: []string{"Auto"}, wantSource: T("配置文件"), wantNote: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			got := resolvePolicy(config, tt.policy)
+			if !reflect.DeepEqual(got.Chain, tt.wantChain) {
+				t.Errorf("Chain = %v，期望 %v", got.Chain, tt.wantChain)
+			}
+			if got.Source != tt.wantSource {
+				t.Errorf("Source = %q，期望 %q", got.Source, tt.wantSource)
+			}
+			if (got.Note != "") != tt.wantNote {
+				t.Errorf("Note = %q", got.Note)
+			}
+		})
+	}
+}
+
+func TestResolvePolicyFromClashAPI(t *testing.T) {
+	dir := useTempWorkDir(t)
+	// Clash 只报告 Proxy 选中的节点，Sub 需要根据配置推断
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/proxies/Proxy":
+			w.Write([]byte(`{"name": "Proxy", "now": "Sub"}`))
+		case "/proxies/Auto":
+			w.Write([]byte(`{"name": "Auto", "now": "b"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	content := `
+external-controller: ` + strings.TrimPrefix(server.URL, "http://") + `
+proxies:
+  - {name: a, type: http, server: 1.1.1.1, port: 80}
+  - {name: b, type: http, server: 1.1.1.2, port: 80}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [Sub, Auto]}
+  - {name: Sub, type: select, proxies: [a, b]}
+  - {name: Auto, type: url-test, proxies: [a, b], url: 'http://example.com', interval: 300}
+`
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := mustParseConfig(t, content)
+
+	tests := []struct {
+		policy     string
+		wantChain  []string
+		wantSource string
+	}{
+		{policy: "Auto", wantChain: []string{"Auto", "b"}, wantSource: "Clash API"},
+		{policy: "Proxy", wantChain: []string{"Proxy", "Sub", "a"}, wantSource: T("配置文件")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			got := resolvePolicy(config, tt.policy)
+			if !reflect.DeepEqual(got.Chain, tt.wantChain) {
+				t.Errorf("Chain = %v，期望 %v", got.Chain, tt.wantChain)
+			}
+			if got.Source != tt.wantSource {
+				t.Errorf("Source = %q，期望 %q", got.Source, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestRuleSimulatorLogicalRules(t *testing.T) {
+	tests := []struct {
+		rule    string
+		target  string
+		port    int
+		want    bool
+		skipped bool
+	}{
+		{rule: "DOMAIN-REGEX,^ads[0-9]*\\.example\\.com$", target: "ads12.example.com", want: true},
+		{rule: "DOMAIN-REGEX,^ads[0-9]*\\.example\\.com$", target: "www.example.com"},
+		{rule: "GEOSITE,google", target: "www.google.com", skipped: true},
+		{rule: "NETWORK,UDP", target: "example.com", skipped: true},
+		{rule: "PROCESS-NAME,curl", target: "example.com", skipped: true},
+		{rule: "AND,((DOMAIN-SUFFIX,example.com),(DST-PORT,443))", target: "a.example.com", port: 443, want: true},
+		{rule: "AND,((DOMAIN-SUFFIX,example.com),(DST-PORT,443))", target: "a.example.com", port: 80},
+		// 已有子规则不命中时，无法判断的子规则不影响 AND 的结果
+		{rule: "AND,((DOMAIN,other.com),(NETWORK,UDP))", target: "a.example.com"},
+		{rule: "AND,((DOMAIN,a.example.com),(NETWORK,UDP))", target: "a.example.com", skipped: true},
+		{rule: "OR,((NETWORK,UDP),(DOMAIN-KEYWORD,example))", target: "a.example.com", want: true},
+		{rule: "OR,((NETWORK,UDP),(DOMAIN,other.com))", target: "a.example.com", skipped: true},
+		{rule: "OR,((DOMAIN,other.com),(IP-CIDR,10.0.0.0/8,no-resolve))", target: "10.1.2.3", want: true},
+		{rule: "NOT,((DOMAIN-SUFFIX,example.com))", target: "example.org", want: true},
+		{rule: "NOT,((DOMAIN-SUFFIX,example.com))", target: "example.com"},
+		{rule: "NOT,((GEOSITE,cn))", target: "example.com", skipped: true},
+		{rule: "AND,((NOT,((DOMAIN,b.example.com))),(OR,((DST-PORT,22),(DST-PORT,443))))", target: "a.example.com", port: 443, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.target, func(t *testing.T) {
+			config := &ClashConfig{Rules: []string{tt.rule + ",X"}}
+			sim, err := newRuleSimulator(config, tt.target, tt.port)
+			if err != nil {
+				t.Fatalf("newRuleSimulator: %v", err)
+			}
+
+			match := sim.run()
+			if got := match != nil; got != tt.want {
+				t.Errorf("命中 = %v，期望 %v (跳过: %v)", got, tt.want, sim.skipped)
+			}
+			if got := len(sim.skipped) == 1; got != tt.skipped {
+				t.Errorf("跳过的规则 = %v，期望跳过 = %v", sim.skipped, tt.skipped)
+			}
+		})
+	}
+}
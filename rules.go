@@ -0,0 +1,688 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 一条分流规则，例如 DOMAIN-SUFFIX,google.com,Proxy
+type Rule struct {
+	Type    string
+	Payload string
+	Target  string
+	// no-resolve 等附加参数
+	Options []string
+}
+
+// 规则的文本形式
+func (r Rule) String() string {
+	parts := []string{r.Type}
+	if !isMatchType(r.Type) {
+		parts = append(parts, r.Payload)
+	}
+	parts = append(parts, r.Target)
+	return strings.Join(append(parts, r.Options...), ",")
+}
+
+// 支持的规则类型，按菜单中显示的顺序排列
+var supportedRuleTypes = []string{
+	"DOMAIN",
+	"DOMAIN-SUFFIX",
+	"DOMAIN-KEYWORD",
+	"IP-CIDR",
+	"IP-CIDR6",
+	"GEOIP",
+	"PROCESS-NAME",
+	"DST-PORT",
+	"RULE-SET",
+	"MATCH",
+}
+
+// 各规则类型内容的校验
+// 新增规则只接受表中和逻辑规则的类型；已有配置中的其他类型不检查内容，交给 Clash 处理，避免配置无法保存
+var rulePayloadCheckers = map[string]func(payload string, config *ClashConfig) error{
+	"DOMAIN":         checkDomainPayload,
+	"DOMAIN-SUFFIX":  checkDomainPayload,
+	"DOMAIN-KEYWORD": checkDomainPayload,
+	"DOMAIN-REGEX":   checkRegexPayload,
+	"GEOSITE":        checkDomainPayload,
+	"IP-CIDR":        checkCIDRPayload(false),
+	"IP-CIDR6":       checkCIDRPayload(true),
+	"SRC-IP-CIDR":    checkCIDRPayload(false),
+	"IP-ASN":         checkNonEmptyPayload,
+	"GEOIP":          checkGeoIPPayload,
+	"SRC-GEOIP":      checkGeoIPPayload,
+	"PROCESS-NAME":   checkNonEmptyPayload,
+	"PROCESS-PATH":   checkNonEmptyPayload,
+	"DST-PORT":       checkPortPayload,
+	"SRC-PORT":       checkPortPayload,
+	"IN-PORT":        checkPortPayload,
+	"NETWORK":        checkNetworkPayload,
+	"RULE-SET":       checkRuleSetPayload,
+	"MATCH":          nil,
+	"FINAL":          nil,
+}
+
+// 逻辑规则，内容为括号包裹的子规则，例如 AND,((DOMAIN,a.com),(NETWORK,UDP)),Proxy
+var logicalRuleTypes = map[string]bool{
+	"AND": true,
+	"OR":  true,
+	"NOT": true,
+}
+
+// 规则中不属于目标策略的附加参数
+var ruleOptions = map[string]bool{
+	"no-resolve": true,
+	"src":        true,
+}
+
+// 是否为兜底规则类型
+func isMatchType(ruleType string) bool {
+	return ruleType == "MATCH" || ruleType == "FINAL"
+}
+
+// 解析规则文本，只检查格式，不检查类型和内容
+func parseRule(text string) (Rule, error) {
+	parts := strings.Split(text, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	rule := Rule{Type: strings.ToUpper(parts[0])}
+	if isMatchType(rule.Type) {
+		if len(parts) < 2 || parts[1] == "" {
+			return rule, errors.New(T("缺少目标策略"))
+		}
+		rule.Target = parts[1]
+		return rule, nil
+	}
+
+	if len(parts) < 3 {
+		return rule, errors.New(T("格式应为 类型,内容,策略"))
+	}
+
+	// 去掉末尾的 no-resolve 等参数
+	last := len(parts) - 1
+	for last > 2 && ruleOptions[strings.ToLower(parts[last])] {
+		last--
+	}
+	if parts[last] == "" {
+		return rule, errors.New(T("缺少目标策略"))
+	}
+
+	rule.Payload = strings.Join(parts[1:last], ",")
+	rule.Target = parts[last]
+	rule.Options = parts[last+1:]
+	return rule, nil
+}
+
+// 规则类型是否已知，未知的类型只能原样保存
+func isKnownRuleType(ruleType string) bool {
+	_, known := rulePayloadCheckers[ruleType]
+	return known || logicalRuleTypes[ruleType]
+}
+
+// 检查规则及其子规则的类型是否已知，新增规则时拒绝拼写错误的类型
+func checkKnownRuleType(ruleType, payload string) error {
+	if !isKnownRuleType(ruleType) {
+		return fmt.Errorf(T("不支持的规则类型 %s"), ruleType)
+	}
+	if !logicalRuleTypes[ruleType] {
+		return nil
+	}
+
+	subRules, err := splitLogicalPayload(payload)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subRules {
+		subRule := parseSubRule(sub)
+		if err := checkKnownRuleType(subRule.Type, subRule.Payload); err != nil {
+			return fmt.Errorf(T("子规则 (%s): %w"), sub, err)
+		}
+	}
+	return nil
+}
+
+// 检查规则内容，config 用于检查 RULE-SET 引用的 rule-provider
+func checkRuleSyntax(rule Rule, config *ClashConfig) error {
+	if err := checkRulePayload(rule.Type, rule.Payload, config); err != nil {
+		return fmt.Errorf(T("%s 规则的内容无效: %w"), rule.Type, err)
+	}
+	return nil
+}
+
+// 按规则类型检查内容，逻辑规则递归检查子规则，未知类型不检查
+func checkRulePayload(ruleType, payload string, config *ClashConfig) error {
+	if logicalRuleTypes[ruleType] {
+		return checkLogicalPayload(ruleType, payload, config)
+	}
+	checker := rulePayloadCheckers[ruleType]
+	if checker == nil {
+		return nil
+	}
+	return checker(payload, config)
+}
+
+// 检查逻辑规则的子规则，子规则的格式为 (类型,内容)
+func checkLogicalPayload(ruleType, payload string, config *ClashConfig) error {
+	subRules, err := splitLogicalPayload(payload)
+	if err != nil {
+		return err
+	}
+	if len(subRules) == 0 {
+		return errors.New(T("逻辑规则至少需要一条子规则"))
+	}
+	if ruleType == "NOT" && len(subRules) != 1 {
+		return errors.New(T("NOT 规则只能包含一条子规则"))
+	}
+
+	for _, sub := range subRules {
+		subRule := parseSubRule(sub)
+		if err := checkRulePayload(subRule.Type, subRule.Payload, config); err != nil {
+			return fmt.Errorf(T("子规则 (%s): %w"), sub, err)
+		}
+	}
+	return nil
+}
+
+// 解析逻辑规则中去掉括号的子规则，子规则没有目标策略
+func parseSubRule(sub string) Rule {
+	parts := strings.Split(sub, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	// 末尾的 no-resolve 等参数
+	last := len(parts)
+	for last > 2 && ruleOptions[strings.ToLower(parts[last-1])] {
+		last--
+	}
+	return Rule{
+		Type:    strings.ToUpper(parts[0]),
+		Payload: strings.Join(parts[1:last], ","),
+		Options: parts[last:],
+	}
+}
+
+// 拆分 ((类型,内容),(类型,内容)) 形式的内容，返回去掉括号的子规则
+func splitLogicalPayload(payload string) ([]string, error) {
+	invalid := fmt.Errorf(T("逻辑规则的内容应为 ((类型,内容),...): %s"), payload)
+	if !strings.HasPrefix(payload, "(") || !strings.HasSuffix(payload, ")") {
+		return nil, invalid
+	}
+
+	inner := payload[1 : len(payload)-1]
+	var subRules []string
+	depth, start := 0, 0
+	for i, r := range inner {
+		switch {
+		case r == '(':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return nil, invalid
+			}
+			if depth == 0 {
+				subRules = append(subRules, strings.TrimSpace(inner[start:i]))
+			}
+		case depth == 0 && r != ',' && r != ' ':
+			// 子规则之间只能有逗号
+			return nil, invalid
+		}
+	}
+	if depth != 0 {
+		return nil, invalid
+	}
+	return subRules, nil
+}
+
+func checkNonEmptyPayload(payload string, _ *ClashConfig) error {
+	if payload == "" {
+		return errors.New(T("内容不能为空"))
+	}
+	return nil
+}
+
+func checkDomainPayload(payload string, _ *ClashConfig) error {
+	if payload == "" {
+		return errors.New(T("域名不能为空"))
+	}
+	if strings.ContainsAny(payload, " \t/") {
+		return fmt.Errorf(T("域名中不能包含空格或 /: %s"), payload)
+	}
+	return nil
+}
+
+func checkRegexPayload(payload string, _ *ClashConfig) error {
+	if payload == "" {
+		return errors.New(T("正则表达式不能为空"))
+	}
+	if _, err := regexp.Compile(payload); err != nil {
+		return fmt.Errorf(T("无效的正则表达式: %v"), err)
+	}
+	return nil
+}
+
+func checkCIDRPayload(ipv6 bool) func(string, *ClashConfig) error {
+	return func(payload string, _ *ClashConfig) error {
+		prefix, err := netip.ParsePrefix(payload)
+		if err != nil {
+			return fmt.Errorf(T("无效的 CIDR: %s"), payload)
+		}
+		if ipv6 && !prefix.Addr().Is6() {
+			return fmt.Errorf(T("IP-CIDR6 需要 IPv6 地址段: %s"), payload)
+		}
+		return nil
+	}
+}
+
+func checkGeoIPPayload(payload string, _ *ClashConfig) error {
+	if payload == "" {
+		return errors.New(T("国家代码不能为空"))
+	}
+	for _, r := range payload {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return fmt.Errorf(T("无效的国家代码: %s"), payload)
+		}
+	}
+	return nil
+}
+
+func checkPortPayload(payload string, _ *ClashConfig) error {
+	bounds := strings.SplitN(payload, "-", 2)
+	for _, bound := range bounds {
+		port, err := strconv.Atoi(strings.TrimSpace(bound))
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf(T("无效的端口或端口范围: %s"), payload)
+		}
+	}
+	return nil
+}
+
+func checkNetworkPayload(payload string, _ *ClashConfig) error {
+	switch strings.ToLower(payload) {
+	case "tcp", "udp":
+		return nil
+	}
+	return fmt.Errorf(T("网络类型只能是 TCP 或 UDP: %s"), payload)
+}
+
+func checkRuleSetPayload(payload string, config *ClashConfig) error {
+	if payload == "" {
+		return errors.New(T("rule-provider 名称不能为空"))
+	}
+	if config != nil && !ruleProviderNames(config)[payload] {
+		return errorOf(ErrRuleProviderNotFound, T("rule-provider %s 不存在"), payload)
+	}
+	return nil
+}
+
+// 配置中定义的 rule-provider 名称
+func ruleProviderNames(config *ClashConfig) map[string]bool {
+	names := make(map[string]bool)
+	for name := range config.RuleProviders {
+		names[name] = true
+	}
+	return names
+}
+
+// 规则可以使用的目标策略：内置策略、代理组和节点
+func ruleTargetNames(config *ClashConfig) map[string]bool {
+	targets := make(map[string]bool)
+	for name := range builtinPolicies {
+		targets[name] = true
+	}
+	for _, group := range config.ProxyGroups {
+		targets[group.Name] = true
+	}
+	for _, proxy := range config.Proxies {
+		targets[proxy.Name] = true
+	}
+	return targets
+}
+
+// MATCH 规则的位置，没有时返回 -1
+func matchRuleIndex(rules []string) int {
+	for i, text := range rules {
+		if rule, err := parseRule(text); err == nil && isMatchType(rule.Type) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 校验新规则并插入到指定位置，position 从 1 开始，0 表示放在 MATCH 之前
+func addRule(config *ClashConfig, text string, position int) (Rule, error) {
+	rule, err := parseRule(text)
+	if err != nil {
+		return rule, err
+	}
+	if err := checkKnownRuleType(rule.Type, rule.Payload); err != nil {
+		return rule, err
+	}
+	if err := checkRuleSyntax(rule, config); err != nil {
+		return rule, err
+	}
+	if !ruleTargetNames(config)[rule.Target] {
+		return rule, fmt.Errorf(T("目标 %s 不是已有的代理组、节点或 DIRECT/REJECT"), rule.Target)
+	}
+
+	match := matchRuleIndex(config.Rules)
+	if isMatchType(rule.Type) {
+		if match >= 0 {
+			return rule, fmt.Errorf(T("已存在 MATCH 规则 (第 %d 条)，请先删除"), match+1)
+		}
+		config.Rules = append(config.Rules, rule.String())
+		return rule, nil
+	}
+
+	// 新规则只能放在 MATCH 之前，MATCH 之后的规则不会生效
+	limit := len(config.Rules)
+	if match >= 0 {
+		limit = match
+	}
+	index := limit
+	if position > 0 {
+		if position > limit+1 {
+			return rule, fmt.Errorf(T("位置 %d 超出范围，规则必须位于 MATCH 之前 (1-%d)"), position, limit+1)
+		}
+		index = position - 1
+	}
+
+	config.Rules = append(config.Rules[:index], append([]string{rule.String()}, config.Rules[index:]...)...)
+	return rule, nil
+}
+
+// 按序号或规则文本查找规则，返回下标
+func findRule(rules []string, target string) (int, error) {
+	if n, err := strconv.Atoi(target); err == nil {
+		if n < 1 || n > len(rules) {
+			return -1, fmt.Errorf(T("规则序号超出范围: %d (共 %d 条)"), n, len(rules))
+		}
+		return n - 1, nil
+	}
+
+	wanted, err := parseRule(target)
+	if err != nil {
+		return -1, fmt.Errorf(T("无效的规则: %w"), err)
+	}
+	for i, text := range rules {
+		if rule, err := parseRule(text); err == nil && strings.EqualFold(rule.String(), wanted.String()) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf(T("未找到规则: %s"), target)
+}
+
+// 删除规则，返回被删除的规则
+func removeRule(config *ClashConfig, target string) (string, error) {
+	index, err := findRule(config.Rules, target)
+	if err != nil {
+		return "", err
+	}
+	removed := config.Rules[index]
+	config.Rules = append(config.Rules[:index], config.Rules[index+1:]...)
+	return removed, nil
+}
+
+// 将第 from 条规则移动到第 to 条的位置，MATCH 规则始终保持在最后
+func moveRule(config *ClashConfig, from, to int) error {
+	count := len(config.Rules)
+	if from < 1 || from > count || to < 1 || to > count {
+		return fmt.Errorf(T("规则序号超出范围 (共 %d 条)"), count)
+	}
+
+	match := matchRuleIndex(config.Rules)
+	if match >= 0 {
+		if from-1 == match {
+			return errors.New(T("MATCH 规则必须位于最后，不能移动"))
+		}
+		if to-1 >= match {
+			return fmt.Errorf(T("规则只能移动到 MATCH 之前 (1-%d)"), match)
+		}
+	}
+
+	rule := config.Rules[from-1]
+	rules := append(config.Rules[:from-1:from-1], config.Rules[from:]...)
+	config.Rules = append(rules[:to-1], append([]string{rule}, rules[to-1:]...)...)
+	return nil
+}
+
+// 处理 rules 子命令
+func manageRules(args []string) {
+	if len(args) == 0 {
+		printRulesUsage()
+		os.Exit(1)
+	}
+
+	// test 只读取配置，不做修改
+	if args[0] == "test" {
+		if err := testRules(args[1:]); err != nil {
+			fmt.Printf(T("rules test 失败: %v\n"), err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	config, err := readClashConfig()
+	if err != nil {
+		fmt.Printf(T("读取配置文件失败: %v\n"), err)
+		os.Exit(1)
+	}
+	// 修改前的配置，用于显示差异
+	current, _ := readClashConfig()
+
+	switch args[0] {
+	case "list":
+		printRules(config)
+		return
+	case "add":
+		if len(args) < 2 {
+			printRulesUsage()
+			os.Exit(1)
+		}
+		position := 0
+		if len(args) >= 4 && (args[2] == "--position" || args[2] == "-p") {
+			position, err = strconv.Atoi(args[3])
+			if err != nil || position < 1 {
+				fmt.Printf(T("无效的位置: %s\n"), args[3])
+				os.Exit(1)
+			}
+		}
+		_, err = addRule(config, args[1], position)
+	case "remove":
+		if len(args) != 2 {
+			printRulesUsage()
+			os.Exit(1)
+		}
+		_, err = removeRule(config, args[1])
+	case "move":
+		if len(args) != 3 {
+			printRulesUsage()
+			os.Exit(1)
+		}
+		from, fromErr := strconv.Atoi(args[1])
+		to, toErr := strconv.Atoi(args[2])
+		if fromErr != nil || toErr != nil {
+			fmt.Println(T("rules move 需要两个规则序号"))
+			os.Exit(1)
+		}
+		err = moveRule(config, from, to)
+	default:
+		fmt.Printf(T("未知的 rules 子命令: %s\n"), args[0])
+		printRulesUsage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf(T("rules %s 失败: %v\n"), args[0], err)
+		os.Exit(1)
+	}
+
+	// 命令行模式通常用于脚本，只打印修改内容，不要求确认
+	printConfigChanges(diffClashConfigs(current, config))
+	if err := saveClashConfig(config); err != nil {
+		fmt.Printf(T("保存配置失败: %v\n"), err)
+		os.Exit(1)
+	}
+	fmt.Println(T("\n配置已更新，您需要重启 Clash 服务以应用更改"))
+}
+
+// 显示 rules 子命令的用法
+func printRulesUsage() {
+	fmt.Printf(T("用法: %s rules <子命令> [参数]\n\n"), os.Args[0])
+	fmt.Println(T("可用子命令:"))
+	fmt.Println(T("  list                          列出所有规则"))
+	fmt.Println(T("  add <规则> [--position N]     添加规则，例如 DOMAIN-SUFFIX,example.com,DIRECT"))
+	fmt.Println(T("                                默认添加在 MATCH 之前，--position 指定插入位置"))
+	fmt.Println(T("  remove <序号|规则>            删除规则"))
+	fmt.Println(T("  move <序号> <新位置>          调整规则顺序，MATCH 始终保持在最后"))
+	fmt.Println(T("  test <域名|IP|URL> [--port N] 在本地模拟规则匹配，显示命中的规则、策略和当前节点"))
+	fmt.Printf(T("\n支持的规则类型: %s\n"), strings.Join(supportedRuleTypes, ", "))
+}
+
+// 规则列表的文字
+func formatRules(config *ClashConfig) string {
+	var b strings.Builder
+	if len(config.Rules) == 0 {
+		fmt.Fprintln(&b, T("配置文件中没有规则"))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, T("共有 %d 条规则:\n\n"), len(config.Rules))
+	for i, text := range config.Rules {
+		rule, err := parseRule(text)
+		if err != nil {
+			fmt.Fprintf(&b, T("%3d. %s (无效: %v)\n"), i+1, text, err)
+			continue
+		}
+		payload := rule.Payload
+		if len(rule.Options) > 0 {
+			payload += " (" + strings.Join(rule.Options, ",") + ")"
+		}
+		fmt.Fprintf(&b, "%3d. %-15s %-36s -> %s\n", i+1, rule.Type, payload, rule.Target)
+	}
+	return b.String()
+}
+
+// 打印规则列表
+func printRules(config *ClashConfig) {
+	fmt.Print(formatRules(config))
+}
+
+// 交互式管理规则
+func interactiveManageRules() {
+	reader := stdinReader
+	for {
+		clearScreen()
+		fmt.Println(T("===== 规则管理 ====="))
+
+		config, err := readClashConfig()
+		if err != nil {
+			fmt.Printf(T("读取配置文件失败: %v\n"), err)
+			waitForKeyPress()
+			return
+		}
+		items := []string{T("添加规则"), T("删除规则"), T("调整规则顺序")}
+		choice := selectMenu(T("规则管理"), textLines(formatRules(config)), items, T("返回")) + 1
+
+		switch choice {
+		case 1:
+			err = interactiveAddRule(config)
+		case 2:
+			printRules(config)
+			_, err = removeRule(config, promptLine(reader, T("\n请输入要删除的规则序号: ")))
+		case 3:
+			printRules(config)
+			from, errFrom := strconv.Atoi(promptLine(reader, T("\n请输入要移动的规则序号: ")))
+			to, errTo := strconv.Atoi(promptLine(reader, T("请输入新的位置: ")))
+			if errFrom != nil || errTo != nil {
+				err = errors.New(T("规则序号必须是数字"))
+			} else {
+				err = moveRule(config, from, to)
+			}
+		case 0:
+			return
+		}
+
+		if err != nil {
+			fmt.Printf(T("操作失败: %v\n"), err)
+			waitForKeyPress()
+			continue
+		}
+
+		saved, err := saveClashConfigWithConfirm(config)
+		if err != nil {
+			fmt.Printf(T("保存配置失败: %v\n"), err)
+		} else if saved {
+			promptRestartClash()
+		}
+		waitForKeyPress()
+	}
+}
+
+// 交互式输入一条新规则
+func interactiveAddRule(config *ClashConfig) error {
+	reader := stdinReader
+
+	typeIndex := selectMenu(T("选择规则类型"), nil, supportedRuleTypes, T("取消"))
+	if typeIndex < 0 {
+		// 配置未修改，保存时会提示没有变化
+		return nil
+	}
+	rule := Rule{Type: supportedRuleTypes[typeIndex]}
+	fmt.Printf(T("规则类型: %s\n"), rule.Type)
+
+	if !isMatchType(rule.Type) {
+		fmt.Print(T("请输入匹配内容 (域名、CIDR、国家代码、进程名、端口或 rule-provider 名称): "))
+		payload, _ := reader.ReadString('\n')
+		rule.Payload = strings.TrimSpace(payload)
+	}
+
+	// 可选的目标：内置策略和代理组
+	targets := []string{"DIRECT", "REJECT"}
+	for _, group := range config.ProxyGroups {
+		targets = append(targets, group.Name)
+	}
+	fmt.Println(T("\n目标策略:"))
+	for i, target := range targets {
+		fmt.Printf("%2d. %s\n", i+1, target)
+	}
+	fmt.Print(T("请选择目标策略序号，或直接输入节点名称: "))
+	targetInput, _ := reader.ReadString('\n')
+	targetInput = strings.TrimSpace(targetInput)
+	if n, err := strconv.Atoi(targetInput); err == nil && n >= 1 && n <= len(targets) {
+		rule.Target = targets[n-1]
+	} else {
+		rule.Target = targetInput
+	}
+
+	switch rule.Type {
+	case "IP-CIDR", "IP-CIDR6", "GEOIP", "RULE-SET":
+		noResolve := promptLine(reader, T("是否添加 no-resolve (不为域名解析 IP)? [y/n]: "))
+		if strings.ToLower(noResolve) == "y" {
+			rule.Options = []string{"no-resolve"}
+		}
+	}
+
+	position := 0
+	if !isMatchType(rule.Type) {
+		fmt.Print(T("请输入插入位置 (直接回车则放在 MATCH 之前): "))
+		positionInput, _ := reader.ReadString('\n')
+		if p, err := strconv.Atoi(strings.TrimSpace(positionInput)); err == nil {
+			position = p
+		}
+	}
+
+	_, err := addRule(config, rule.String(), position)
+	return err
+}
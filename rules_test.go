@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Rule
+		wantErr bool
+	}{
+		{text: "DOMAIN-SUFFIX,google.com,Proxy", want: Rule{Type: "DOMAIN-SUFFIX", Payload: "google.com", Target: "Proxy"}},
+		{text: "ip-cidr, 10.0.0.0/8 ,DIRECT,no-resolve", want: Rule{Type: "IP-CIDR", Payload: "10.0.0.0/8", Target: "DIRECT", Options: []string{"no-resolve"}}},
+		{text: "MATCH,Proxy", want: Rule{Type: "MATCH", Target: "Proxy"}},
+		{text: "MATCH", wantErr: true},
+		{text: "DOMAIN,a.com", wantErr: true},
+		{text: "DOMAIN,a.com,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := parseRule(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望解析失败，得到 %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRule: %v", err)
+			}
+			if got.String() != tt.want.String() || got.Type != tt.want.Type || got.Payload != tt.want.Payload {
+				t.Errorf("得到 %#v，期望 %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRuleSyntax(t *testing.T) {
+	config := mustParseConfig(t, `
+rule-providers:
+  ads: {type: http, behavior: domain, url: "http://example.com/ads.yaml", path: ./ads.yaml}
+`)
+
+	tests := []struct {
+		text    string
+		wantErr error
+	}{
+		{text: "DOMAIN,example.com,DIRECT"},
+		{text: "DOMAIN,exa mple.com,DIRECT", wantErr: errAny},
+		{text: "IP-CIDR,10.0.0.0/8,DIRECT,no-resolve"},
+		{text: "IP-CIDR,not-a-cidr,DIRECT", wantErr: errAny},
+		{text: "IP-CIDR6,2001:db8::/32,DIRECT"},
+		{text: "IP-CIDR6,10.0.0.0/8,DIRECT", wantErr: errAny},
+		{text: "GEOIP,CN,DIRECT"},
+		{text: "GEOIP,C1,DIRECT", wantErr: errAny},
+		{text: "DST-PORT,8000-9000,DIRECT"},
+		{text: "DST-PORT,0,DIRECT", wantErr: errAny},
+		{text: "RULE-SET,ads,REJECT"},
+		{text: "RULE-SET,missing,REJECT", wantErr: ErrRuleProviderNotFound},
+		{text: "MATCH,DIRECT"},
+		{text: "DOMAIN-REGEX,^ads\\.,REJECT"},
+		{text: "DOMAIN-REGEX,(ads,REJECT", wantErr: errAny},
+		{text: "IN-PORT,7890,DIRECT"},
+		{text: "NETWORK,udp,REJECT"},
+		{text: "NETWORK,icmp,REJECT", wantErr: errAny},
+		// 已有配置中的未知类型不检查内容，交给 Clash 处理
+		{text: "IN-TYPE,SOCKS5,DIRECT"},
+		// 逻辑规则递归检查子规则
+		{text: "AND,((DOMAIN,a.com),(NETWORK,UDP)),REJECT"},
+		{text: "OR,((IP-CIDR,1.0.0.0/8,no-resolve),(NOT,((DST-PORT,443)))),DIRECT"},
+		{text: "AND,((IP-CIDR,bad),(NETWORK,UDP)),REJECT", wantErr: errAny},
+		{text: "OR,((DOMAIN,a.com),(NOT,((RULE-SET,missing)))),DIRECT", wantErr: ErrRuleProviderNotFound},
+		{text: "NOT,((DOMAIN,a.com),(DOMAIN,b.com)),REJECT", wantErr: errAny},
+		{text: "AND,(),REJECT", wantErr: errAny},
+		{text: "AND,((DOMAIN,a.com),REJECT", wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			rule, err := parseRule(tt.text)
+			if err != nil {
+				t.Fatalf("parseRule: %v", err)
+			}
+			err = checkRuleSyntax(rule, config)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("期望规则有效，得到: %v", err)
+			case tt.wantErr != nil && err == nil:
+				t.Errorf("期望规则无效")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Errorf("错误 %q 不属于 %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddRule(t *testing.T) {
+	const rulesTestConfig = `
+proxies:
+  - {name: a, type: http, server: 1.1.1.1, port: 80}
+proxy-groups:
+  - {name: Proxy, type: select, proxies: [a]}
+rules:
+  - DOMAIN,one.com,DIRECT
+  - DOMAIN,two.com,DIRECT
+  - MATCH,Proxy
+`
+
+	tests := []struct {
+		name     string
+		text     string
+		position int
+		wantErr  error
+		want     []string
+	}{
+		{
+			name: "默认放在 MATCH 之前",
+			text: "DOMAIN-SUFFIX,new.com,Proxy",
+			want: []string{"DOMAIN,one.com,DIRECT", "DOMAIN,two.com,DIRECT", "DOMAIN-SUFFIX,new.com,Proxy", "MATCH,Proxy"},
+		},
+		{
+			name:     "插入到指定位置",
+			text:     "ip-cidr,10.0.0.0/8,a,no-resolve",
+			position: 1,
+			want:     []string{"IP-CIDR,10.0.0.0/8,a,no-resolve", "DOMAIN,one.com,DIRECT", "DOMAIN,two.com,DIRECT", "MATCH,Proxy"},
+		},
+		{name: "位置不能在 MATCH 之后", text: "DOMAIN,new.com,DIRECT", position: 4, wantErr: errAny},
+		{name: "已存在 MATCH", text: "MATCH,DIRECT", wantErr: errAny},
+		{name: "目标不存在", text: "DOMAIN,new.com,Missing", wantErr: errAny},
+		{name: "逻辑规则", text: "AND,((DOMAIN,a.com),(NETWORK,UDP)),REJECT", position: 1,
+			want: []string{"AND,((DOMAIN,a.com),(NETWORK,UDP)),REJECT", "DOMAIN,one.com,DIRECT", "DOMAIN,two.com,DIRECT", "MATCH,Proxy"}},
+		{name: "逻辑规则的子规则无效", text: "NOT,((GEOIP,C1)),REJECT", wantErr: errAny},
+		{name: "拼错的规则类型", text: "DOMIAN-SUFFIX,new.com,DIRECT", wantErr: errAny},
+		{name: "拼错的子规则类型", text: "AND,((DOMAIN,a.com),(NETWROK,UDP)),REJECT", wantErr: errAny},
+		{name: "内容无效", text: "IP-CIDR,10.0.0.0,DIRECT", wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := mustParseConfig(t, rulesTestConfig)
+			ok := expectConfigOp(t, config, tt.wantErr, func() error {
+				_, err := addRule(config, tt.text, tt.position)
+				return err
+			})
+			if ok && !reflect.DeepEqual(config.Rules, tt.want) {
+				t.Errorf("rules = %q\n期望 %q", config.Rules, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 管理工具自身的设置文件，保存在工作目录中
+func managerSettingsPath() string {
+	return clashWorkPath("clash-setup.yaml")
+}
+
+// 管理工具的持久化设置
+type ManagerSettings struct {
+	// 下载订阅时使用的 HTTP 选项
+	SubscriptionFetch SubscriptionFetchOptions `yaml:"subscription-fetch"`
+	// 已导入的订阅，刷新时用于替换对应的节点
+	Subscriptions []SubscriptionRecord `yaml:"subscriptions,omitempty"`
+	// 通过 ruleset add 导入的规则集，刷新时重新下载并转换
+	RuleSets []RuleSetRecord `yaml:"rule-sets,omitempty"`
+	// 按地区自动生成代理组
+	RegionGroups RegionGroupSettings `yaml:"region-groups,omitempty"`
+	// 节点的标签和最近一次测速结果，按节点名称索引
+	ProxyMeta map[string]*ProxyMeta `yaml:"proxy-meta,omitempty"`
+	// 修改配置前是否显示差异并要求确认，未设置时默认开启
+	ConfirmChanges *bool `yaml:"confirm-changes,omitempty"`
+	// 最近一次通过 profile switch 切换到的 profile
+	ActiveProfile string `yaml:"active-profile,omitempty"`
+}
+
+// 修改配置前是否需要确认
+func (s *ManagerSettings) confirmChanges() bool {
+	return s.ConfirmChanges == nil || *s.ConfirmChanges
+}
+
+// 读取管理工具设置，文件不存在时返回默认设置
+func loadManagerSettings() (*ManagerSettings, error) {
+	settings := &ManagerSettings{
+		SubscriptionFetch: defaultSubscriptionFetchOptions(),
+	}
+
+	content, err := os.ReadFile(managerSettingsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(content, settings); err != nil {
+		return nil, fmt.Errorf(T("解析设置文件 %s 失败: %w"), managerSettingsPath(), err)
+	}
+
+	return settings, nil
+}
+
+// 保存管理工具设置
+func saveManagerSettings(settings *ManagerSettings) error {
+	if err := os.MkdirAll(filepath.Dir(managerSettingsPath()), 0755); err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	// 设置中可能包含认证请求头，仅允许 root 读取
+	return os.WriteFile(managerSettingsPath(), content, 0600)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 终端按键
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyEnter
+	keyBackspace
+	keyTab
+	keyBackTab
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEscape
+	keyCtrlC
+	keyCtrlR
+	keyCtrlT
+	keyCtrlU
+	keyUnknown
+)
+
+// 一次按键，keyRune 时 Rune 为输入的字符
+type keyEvent struct {
+	Kind keyKind
+	Rune rune
+}
+
+// 执行 stty，终端设置作用于标准输入
+func runStty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// 原始模式下一次读取最多等待的时间，单位为 0.1 秒；超时后界面可以刷新状态栏和测速结果
+const rawReadTimeout = "2"
+
+// 切换到原始模式，按键不需要回车即可读取，也不会回显；返回恢复终端设置的函数
+// 标准输入不是终端时返回错误，调用方应退回到普通的逐行输入
+func enableRawMode() (func(), error) {
+	saved, err := runStty("-g")
+	if err != nil {
+		return nil, fmt.Errorf(T("标准输入不是终端: %w"), err)
+	}
+	if _, err := runStty("raw", "-echo", "min", "0", "time", rawReadTimeout); err != nil {
+		return nil, err
+	}
+	// 隐藏光标，退出时恢复
+	fmt.Print("\033[?25l")
+	return func() {
+		fmt.Print("\033[?25h")
+		runStty(saved)
+	}, nil
+}
+
+// 终端的行数和列数，无法获取时返回 24x80
+func terminalSize() (int, int) {
+	output, err := runStty("size")
+	if err == nil {
+		fields := strings.Fields(output)
+		if len(fields) == 2 {
+			rows, errRows := strconv.Atoi(fields[0])
+			cols, errCols := strconv.Atoi(fields[1])
+			if errRows == nil && errCols == nil && rows > 0 && cols > 0 {
+				return rows, cols
+			}
+		}
+	}
+	return 24, 80
+}
+
+// 终端的转义序列，不含开头的 ESC
+var keySequences = map[string]keyKind{
+	"[A":  keyUp,
+	"OA":  keyUp,
+	"[B":  keyDown,
+	"OB":  keyDown,
+	"[C":  keyRight,
+	"OC":  keyRight,
+	"[D":  keyLeft,
+	"OD":  keyLeft,
+	"[H":  keyHome,
+	"OH":  keyHome,
+	"[1~": keyHome,
+	"[F":  keyEnd,
+	"OF":  keyEnd,
+	"[4~": keyEnd,
+	"[5~": keyPageUp,
+	"[6~": keyPageDown,
+	"[Z":  keyBackTab,
+}
+
+// 读取按键，最多等待 rawReadTimeout，没有按键时返回空列表
+// 粘贴的文字或快速连续的按键可能在一次读取中到达
+func pollKeys() ([]keyEvent, error) {
+	buf := make([]byte, 256)
+	n, err := os.Stdin.Read(buf)
+	if n > 0 {
+		return parseKeys(buf[:n]), nil
+	}
+	// 原始模式下读取超时返回 0 字节，Go 将其视为 EOF
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// 读取按键，一直等到有按键为止
+func readKeys() ([]keyEvent, error) {
+	for {
+		keys, err := pollKeys()
+		if err != nil || len(keys) > 0 {
+			return keys, err
+		}
+	}
+}
+
+// 解析按键的字节序列
+func parseKeys(b []byte) []keyEvent {
+	var keys []keyEvent
+	for len(b) > 0 {
+		var key keyEvent
+		size := 1
+		switch b[0] {
+		case 3:
+			key.Kind = keyCtrlC
+		case 18:
+			key.Kind = keyCtrlR
+		case 20:
+			key.Kind = keyCtrlT
+		case 21:
+			key.Kind = keyCtrlU
+		case '\t':
+			key.Kind = keyTab
+		case '\r', '\n':
+			key.Kind = keyEnter
+		case 8, 127:
+			key.Kind = keyBackspace
+		case 27:
+			key.Kind = keyEscape
+			for sequence, kind := range keySequences {
+				if strings.HasPrefix(string(b[1:]), sequence) {
+					key.Kind = kind
+					size = 1 + len(sequence)
+					break
+				}
+			}
+		default:
+			r, n := utf8.DecodeRune(b)
+			size = n
+			key.Kind = keyUnknown
+			if r != utf8.RuneError && unicode.IsPrint(r) {
+				key = keyEvent{Kind: keyRune, Rune: r}
+			}
+		}
+		keys = append(keys, key)
+		b = b[size:]
+	}
+	return keys
+}
+
+// 字符在终端中占用的列数，中日韩文字和 emoji 占两列
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r):
+		return 0
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1FAFF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		// 两个地区指示符组成一面国旗，共占两列
+		return 1
+	}
+	return 1
+}
+
+// 字符串在终端中占用的列数
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// 将字符串截断或用空格补齐到指定列数，截断时以 … 结尾
+func fitWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if current := displayWidth(s); current <= width {
+		return s + strings.Repeat(" ", width-current)
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	b.WriteString("…")
+	used++
+	return b.String() + strings.Repeat(" ", width-used)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 让测试使用临时的工作目录和配置文件，结束后恢复原路径
+func useTempWorkDir(t *testing.T) string {
+	t.Helper()
+	workDir, configPath := activeWorkDir, activeConfigPath
+	t.Cleanup(func() {
+		activeWorkDir, activeConfigPath = workDir, configPath
+	})
+
+	dir := t.TempDir()
+	initClashPaths(dir, "")
+	return dir
+}
+
+// 解析测试用的配置内容
+func mustParseConfig(t *testing.T, content string) *ClashConfig {
+	t.Helper()
+	config, err := parseClashConfig([]byte(content))
+	if err != nil {
+		t.Fatalf("parseClashConfig: %v", err)
+	}
+	return config
+}
+
+// 期望操作失败，但不关心错误类型
+var errAny = errors.New("任意错误")
+
+// 执行修改配置的操作并检查结果
+// wantErr 为 nil 时期望成功；否则期望失败，错误属于 wantErr，且配置保持不变
+// 返回操作是否成功，成功时由调用方继续检查修改后的配置
+func expectConfigOp(t *testing.T, config *ClashConfig, wantErr error, op func() error) bool {
+	t.Helper()
+	before, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatalf("yaml.Marshal: %v", err)
+	}
+
+	err = op()
+	if wantErr == nil {
+		if err != nil {
+			t.Fatalf("期望成功，得到: %v", err)
+		}
+		return true
+	}
+
+	if err == nil {
+		t.Fatalf("期望失败，但操作成功")
+	}
+	if wantErr != errAny && !errors.Is(err, wantErr) {
+		t.Errorf("错误 %q 不属于 %v", err, wantErr)
+	}
+	if after, _ := yaml.Marshal(config); !bytes.Equal(before, after) {
+		t.Errorf("失败后配置被修改\n之前:\n%s\n之后:\n%s", before, after)
+	}
+	return false
+}
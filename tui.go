@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 状态栏显示的 Clash 服务和 API 状态
+type clashStatus struct {
+	Running bool
+	API     bool
+	Version string
+}
+
+// 后台检查状态的间隔，systemctl 和 API 请求都比较慢，不在界面刷新时同步检查
+const clashStatusInterval = 3 * time.Second
+
+var (
+	clashStatusOnce    sync.Once
+	clashStatusMu      sync.Mutex
+	clashStatusLatest  clashStatus
+	clashStatusChecked bool
+)
+
+// 检查 Clash 服务是否运行，以及控制器 API 能否访问
+func checkClashStatus() clashStatus {
+	status := clashStatus{Running: isClashRunning()}
+
+	version, err := defaultClashAPIClient().withTimeout(time.Second).version()
+	if err == nil {
+		status.API = true
+		status.Version = version
+	}
+	return status
+}
+
+// 最近一次检查的状态，第一次调用时启动后台检查；尚未检查完成时 checked 为 false
+func currentClashStatus() (clashStatus, bool) {
+	clashStatusOnce.Do(func() {
+		go func() {
+			for {
+				status := checkClashStatus()
+				clashStatusMu.Lock()
+				clashStatusLatest = status
+				clashStatusChecked = true
+				clashStatusMu.Unlock()
+				time.Sleep(clashStatusInterval)
+			}
+		}()
+	})
+
+	clashStatusMu.Lock()
+	defer clashStatusMu.Unlock()
+	return clashStatusLatest, clashStatusChecked
+}
+
+// 屏幕最底部的状态栏
+func statusBarLine(cols int) string {
+	text := T(" 正在检查 Clash 状态...")
+	if status, checked := currentClashStatus(); checked {
+		text = fmt.Sprintf(" Clash: %s | API: %s", statusString(status.Running), apiStatusString(status.API))
+		if status.Version != "" {
+			text += " (" + status.Version + ")"
+		}
+	}
+	text += T(" | 配置: ") + clashConfigPath()
+	return "\033[7m" + fitWidth(text, cols) + "\033[0m"
+}
+
+// 全屏界面，render 返回状态栏以上的内容，handleKey 返回 false 时退出
+type tuiView interface {
+	render(rows, cols int) []string
+	handleKey(key keyEvent, rows int) bool
+}
+
+// 需要在没有按键时更新内容的界面，例如后台测速
+type tuiUpdater interface {
+	update()
+}
+
+// 运行全屏界面，标准输入不是终端时返回错误
+// 每次读取按键最多等待 rawReadTimeout，画面只在内容变化时重绘，避免慢速 SSH 连接下卡顿
+func runTUI(view tuiView) error {
+	restore, err := enableRawMode()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	rows, cols := terminalSize()
+	last := ""
+	for tick := 0; ; tick++ {
+		// 终端大小大约每秒检查一次
+		if tick%5 == 0 {
+			rows, cols = terminalSize()
+		}
+		if updater, ok := view.(tuiUpdater); ok {
+			updater.update()
+		}
+
+		frame := renderFrame(view.render(rows-1, cols), rows-1, cols)
+		if frame != last {
+			fmt.Print(frame)
+			last = frame
+		}
+
+		keys, err := pollKeys()
+		if err != nil {
+			break
+		}
+		for _, key := range keys {
+			if !view.handleKey(key, rows-1) {
+				fmt.Print("\033[H\033[2J")
+				return nil
+			}
+		}
+	}
+	fmt.Print("\033[H\033[2J")
+	return nil
+}
+
+// 拼出整屏内容：从左上角开始逐行覆盖并清除行尾，最后一行是状态栏
+// 不先清屏，重绘时不会闪烁
+func renderFrame(lines []string, height, cols int) string {
+	var b strings.Builder
+	b.WriteString("\033[H")
+	for i := 0; i < height; i++ {
+		if i < len(lines) {
+			b.WriteString(lines[i])
+		}
+		b.WriteString("\033[K\r\n")
+	}
+	// 状态栏后不换行，否则屏幕会向上滚动一行
+	b.WriteString(statusBarLine(cols) + "\033[K")
+	return b.String()
+}
+
+// 标题行
+func tuiTitle(title string) string {
+	return "\033[1m===== " + title + " =====\033[0m"
+}
+
+// 每次只从标准输入读取一个字节，包装成 bufio.Reader 后读到换行即停止，
+// 不会预读后续的输入，之后的菜单仍能收到用户的按键
+type stdinByteReader struct{}
+
+func (stdinByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return os.Stdin.Read(p[:1])
+}
+
+// 所有交互式输入共用的读取器
+var stdinReader = bufio.NewReader(stdinByteReader{})
+
+// 读取一行，读取失败且没有内容时返回错误
+// fmt.Scanln 遇到非数字的输入会把剩余内容留给下一次读取，这里总是读完整行
+func readLine() (string, error) {
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// 将多行文字拆成菜单上方显示的行
+func textLines(text string) []string {
+	return strings.Split(strings.TrimRight(text, "\n"), "\n")
+}
+
+// 菜单，0 号为返回
+type tuiMenu struct {
+	title  string
+	header []string
+	items  []string
+	back   string
+
+	// 光标位置，等于 len(items) 时指向返回
+	cursor int
+	offset int
+	// 上方内容过长时的滚动位置
+	headerOffset int
+	digits       string
+	choice       int
+}
+
+// 显示菜单，返回选中项的序号（从 0 开始），选择返回时为 -1
+// header 显示在选项上方，过长时可以用 PgUp/PgDn 滚动；标准输入不是终端时逐行输入序号
+func selectMenu(title string, header []string, items []string, back string) int {
+	menu := &tuiMenu{title: title, header: header, items: items, back: back, choice: -1}
+	if err := runTUI(menu); err != nil {
+		return menu.runLineMode()
+	}
+	return menu.choice
+}
+
+// 菜单各部分的高度
+func (m *tuiMenu) layout(rows int) (headerHeight, itemHeight int) {
+	// 标题、空行、选项后的空行和帮助各占一行，上方内容后还有一个空行
+	available := rows - 4
+	if len(m.header) > 0 {
+		available--
+	}
+	itemCount := len(m.items) + 1
+	if len(m.header)+itemCount <= available {
+		return len(m.header), itemCount
+	}
+
+	// 放不下时选项至少占一半
+	itemHeight = available - len(m.header)
+	if half := available / 2; itemHeight < half {
+		itemHeight = half
+	}
+	if itemHeight > itemCount {
+		itemHeight = itemCount
+	}
+	if itemHeight < 1 {
+		itemHeight = 1
+	}
+	headerHeight = available - itemHeight
+	if headerHeight < 0 {
+		headerHeight = 0
+	}
+	return headerHeight, itemHeight
+}
+
+func (m *tuiMenu) label(index int) string {
+	width := len(strconv.Itoa(len(m.items)))
+	if index == len(m.items) {
+		return fmt.Sprintf("%*d. %s", width, 0, m.back)
+	}
+	return fmt.Sprintf("%*d. %s", width, index+1, m.items[index])
+}
+
+func (m *tuiMenu) render(rows, cols int) []string {
+	headerHeight, itemHeight := m.layout(rows)
+	lines := []string{tuiTitle(m.title), ""}
+
+	if len(m.header) > 0 {
+		if maxOffset := len(m.header) - headerHeight; m.headerOffset > maxOffset {
+			m.headerOffset = maxOffset
+		}
+		if m.headerOffset < 0 {
+			m.headerOffset = 0
+		}
+		for i := m.headerOffset; i < m.headerOffset+headerHeight; i++ {
+			lines = append(lines, fitWidth(m.header[i], cols-1))
+		}
+		// 下方还有内容时最后一行改为提示，提示本身占用了一行
+		if below := len(m.header) - m.headerOffset - headerHeight; below > 0 && headerHeight > 0 {
+			lines[len(lines)-1] = fmt.Sprintf(T("\033[2m… 还有 %d 行，PgUp/PgDn 滚动\033[0m"), below+1)
+		}
+		lines = append(lines, "")
+	}
+
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+itemHeight {
+		m.offset = m.cursor - itemHeight + 1
+	}
+	for i := m.offset; i < m.offset+itemHeight && i <= len(m.items); i++ {
+		line := fitWidth(m.label(i), cols-3)
+		if i == m.cursor {
+			line = "\033[7m> " + line + "\033[0m"
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "")
+	lines = append(lines, fitWidth(T("↑↓ 移动  回车确认  数字键选择  Esc/q 返回"), cols-1))
+	return lines
+}
+
+func (m *tuiMenu) handleKey(key keyEvent, rows int) bool {
+	count := len(m.items) + 1
+	headerHeight, _ := m.layout(rows)
+	if key.Kind != keyRune || key.Rune < '0' || key.Rune > '9' {
+		m.digits = ""
+	}
+
+	switch key.Kind {
+	case keyEscape, keyCtrlC, keyLeft:
+		m.choice = -1
+		return false
+	case keyUp:
+		m.cursor = (m.cursor + count - 1) % count
+	case keyDown, keyTab:
+		m.cursor = (m.cursor + 1) % count
+	case keyHome:
+		m.cursor = 0
+	case keyEnd:
+		m.cursor = count - 1
+	case keyPageUp:
+		m.headerOffset -= headerHeight - 1
+	case keyPageDown:
+		m.headerOffset += headerHeight - 1
+	case keyEnter, keyRight:
+		m.choice = m.cursor
+		if m.cursor == len(m.items) {
+			m.choice = -1
+		}
+		return false
+	case keyRune:
+		if key.Rune == 'q' {
+			m.choice = -1
+			return false
+		}
+		if key.Rune >= '0' && key.Rune <= '9' {
+			return m.handleDigit(key.Rune)
+		}
+	}
+	return true
+}
+
+// 数字键：序号唯一确定时直接选中，否则移动光标等待下一位或回车
+func (m *tuiMenu) handleDigit(r rune) bool {
+	m.digits += string(r)
+	n, _ := strconv.Atoi(m.digits)
+	if n > len(m.items) {
+		m.digits = string(r)
+		n = int(r - '0')
+	}
+	if m.digits == "0" {
+		m.choice = -1
+		return false
+	}
+	if n < 1 || n > len(m.items) {
+		m.digits = ""
+		return true
+	}
+
+	m.cursor = n - 1
+	if n*10 > len(m.items) {
+		m.choice = n - 1
+		return false
+	}
+	return true
+}
+
+// 标准输入不是终端时逐行输入序号，输入结束时视为返回
+func (m *tuiMenu) runLineMode() int {
+	fmt.Printf("===== %s =====\n", m.title)
+	for _, line := range m.header {
+		fmt.Println(line)
+	}
+	if len(m.header) > 0 {
+		fmt.Println()
+	}
+	for i := 0; i <= len(m.items); i++ {
+		fmt.Println(m.label(i))
+	}
+
+	for {
+		fmt.Printf(T("请选择 [0-%d]: "), len(m.items))
+		line, err := readLine()
+		if err != nil {
+			fmt.Println()
+			return -1
+		}
+		n, err := strconv.Atoi(line)
+		if err == nil && n == 0 {
+			return -1
+		}
+		if err == nil && n >= 1 && n <= len(m.items) {
+			return n - 1
+		}
+		fmt.Println(T("无效的选择，请重试"))
+	}
+}
+
+// 确认对话框
+type tuiConfirm struct {
+	title    string
+	question string
+	body     []string
+	yes      bool
+	offset   int
+}
+
+// 显示确认对话框，body 为需要确认的内容，过长时可以滚动
+// 标准输入不是终端时逐行输入 y/n
+func confirmDialog(title, question string, body []string, defaultYes bool) bool {
+	dialog := &tuiConfirm{title: title, question: question, body: body, yes: defaultYes}
+	if err := runTUI(dialog); err != nil {
+		return dialog.runLineMode()
+	}
+	return dialog.yes
+}
+
+// 内容区域的高度：标题、空行、内容后的空行、问题和帮助各占一行
+func confirmBodyHeight(rows int) int {
+	if height := rows - 5; height > 1 {
+		return height
+	}
+	return 1
+}
+
+func (d *tuiConfirm) render(rows, cols int) []string {
+	height := confirmBodyHeight(rows)
+	if maxOffset := len(d.body) - height; d.offset > maxOffset {
+		d.offset = maxOffset
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+
+	lines := []string{tuiTitle(d.title), ""}
+	for i := d.offset; i < d.offset+height && i < len(d.body); i++ {
+		lines = append(lines, fitWidth(d.body[i], cols-1))
+	}
+	if below := len(d.body) - d.offset - height; below > 0 {
+		lines[len(lines)-1] = fmt.Sprintf(T("\033[2m… 还有 %d 行，↑↓ 滚动\033[0m"), below+1)
+	}
+	for len(lines) < height+2 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, "")
+
+	yes, no := T("  是  "), T("  否  ")
+	if d.yes {
+		yes = "\033[7m" + yes + "\033[0m"
+	} else {
+		no = "\033[7m" + no + "\033[0m"
+	}
+	lines = append(lines, d.question+"  ["+yes+"] ["+no+"]")
+	lines = append(lines, fitWidth(T("←→ 切换  y/n 直接选择  回车确认  Esc 取消"), cols-1))
+	return lines
+}
+
+func (d *tuiConfirm) handleKey(key keyEvent, rows int) bool {
+	height := confirmBodyHeight(rows)
+	switch key.Kind {
+	case keyEscape, keyCtrlC:
+		d.yes = false
+		return false
+	case keyEnter:
+		return false
+	case keyLeft, keyRight, keyTab, keyBackTab:
+		d.yes = !d.yes
+	case keyUp:
+		d.offset--
+	case keyDown:
+		d.offset++
+	case keyPageUp:
+		d.offset -= height - 1
+	case keyPageDown:
+		d.offset += height - 1
+	case keyRune:
+		switch key.Rune {
+		case 'y', 'Y':
+			d.yes = true
+			return false
+		case 'n', 'N', 'q':
+			d.yes = false
+			return false
+		}
+	}
+	return true
+}
+
+func (d *tuiConfirm) runLineMode() bool {
+	for _, line := range d.body {
+		fmt.Println(line)
+	}
+	fmt.Printf("%s [y/n]: ", d.question)
+	answer, err := readLine()
+	if err == io.EOF {
+		fmt.Println()
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes"
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,20 +12,20 @@ func VerifyInstallation() error {
 	// 检查 clash 二进制文件
 	_, err := exec.LookPath("clash")
 	if err != nil {
-		return fmt.Errorf("Clash 二进制文件未找到: %v", err)
+		return fmt.Errorf(T("Clash 二进制文件未找到: %w"), err)
 	}
-	
+
 	// 检查配置文件
-	if _, err := os.Stat("/srv/clash/config.yaml"); os.IsNotExist(err) {
-		return fmt.Errorf("Clash 配置文件未找到: %v", err)
+	if _, err := os.Stat(clashConfigPath()); os.IsNotExist(err) {
+		return fmt.Errorf(T("Clash 配置文件未找到: %w"), err)
 	}
-	
+
 	// 检查 Country.mmdb 文件
 	mmdbPaths := []string{
 		"/root/.config/clash/Country.mmdb",
 		os.ExpandEnv("$HOME/.config/clash/Country.mmdb"),
 	}
-	
+
 	mmdbFound := false
 	for _, path := range mmdbPaths {
 		if _, err := os.Stat(path); err == nil {
@@ -32,10 +33,10 @@ func VerifyInstallation() error {
 			break
 		}
 	}
-	
+
 	if !mmdbFound {
-		return fmt.Errorf("Country.mmdb 文件未找到")
+		return errors.New(T("Country.mmdb 文件未找到"))
 	}
-	
+
 	return nil
-} 
\ No newline at end of file
+}